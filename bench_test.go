@@ -0,0 +1,87 @@
+package cords
+
+import (
+	"math/rand"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/npillmayer/cords/chunk"
+)
+
+// Chunk layout benchmarks. Compare layouts by running them with different
+// build tags, e.g.
+//
+//	go test -run - -bench Layout . > 64.txt
+//	go test -run - -bench Layout -tags chunk256 . > 256.txt
+//	benchstat 64.txt 256.txt
+
+// benchText is about 1 MiB of text, mostly ASCII with some multi-byte runes.
+var benchText = strings.Repeat(benchLine, 1<<20/len(benchLine))
+
+const benchLine = "Größe 0123456789 the quick brown fox\n"
+
+func BenchmarkLayoutLoad(b *testing.B) {
+	b.ReportMetric(float64(chunk.MaxBase), "bytes/chunk")
+	b.SetBytes(int64(len(benchText)))
+	for b.Loop() {
+		_ = FromString(benchText)
+	}
+}
+
+func BenchmarkLayoutSeek(b *testing.B) {
+	cord := FromString(benchText)
+	b.ReportMetric(float64(cord.height()), "height")
+	rnd := rand.New(rand.NewSource(1))
+	for b.Loop() {
+		if _, _, err := cord.Index(uint64(rnd.Int63n(int64(cord.Len())))); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkLayoutIterate(b *testing.B) {
+	cord := FromString(benchText)
+	b.SetBytes(int64(cord.Len()))
+	for b.Loop() {
+		var lines uint64
+		for c := range cord.RangeChunk() {
+			lines += c.Summary().Lines
+		}
+		if lines != cord.LineCount() {
+			b.Fatalf("expected %d lines, counted %d", cord.LineCount(), lines)
+		}
+	}
+}
+
+func BenchmarkLayoutSplit(b *testing.B) {
+	cord := FromString(benchText)
+	rnd := rand.New(rand.NewSource(1))
+	for b.Loop() {
+		if _, _, err := Split(cord, uint64(len(benchLine)*rnd.Intn(int(cord.LineCount()))+10)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkFromMapped measures the cost of opening a cord over text which is
+// not copied: a parallel pass over the text, which is linear in its size. The
+// index% metric is the size of the resident index relative to the text.
+func BenchmarkFromMapped(b *testing.B) {
+	for _, mb := range []int{1, 16, 64} {
+		text := []byte(strings.Repeat(benchText, mb))
+		b.Run(strconv.Itoa(mb)+"MiB", func(b *testing.B) {
+			b.SetBytes(int64(len(text)))
+			b.ReportAllocs()
+			var cord Cord
+			for b.Loop() {
+				var err error
+				if cord, err = FromMapped(text, 64); err != nil {
+					b.Fatal(err)
+				}
+			}
+			u := MemoryUsage(cord)
+			b.ReportMetric(100*float64(u.Bytes)/float64(len(text)), "index%")
+		})
+	}
+}
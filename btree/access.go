@@ -1,7 +1,8 @@
 package btree
 
 // At returns the leaf item at item index.
-func (t *Tree[I, S, E]) At(index int64) (I, error) {
+func (t *Tree[I, S, E]) At(index int64) (item I, err error) {
+	defer catchLoadFailure(&err)
 	var zero I
 	if t == nil || t.root == nil {
 		return zero, ErrIndexOutOfBounds
@@ -18,20 +19,17 @@ func (t *Tree[I, S, E]) atNode(n treeNode[I, S, E], height int, index int64) (I,
 	assert(n != nil, "atNode called with nil node")
 	assert(height > 0, "atNode called with non-positive height")
 	if height == 1 {
-		leaf := n.(*leafNode[I, S, E])
+		leaf := mustLeaf[I, S, E](n)
 		//if index < 0 || index >= len(leaf.items) {
 		if index < 0 || index >= leaf.Weight() {
 			return zero, ErrIndexOutOfBounds
 		}
 		return leaf.items[index], nil
 	}
-	inner := n.(*innerNode[I, S, E])
+	inner := mustInner[I, S, E](n)
 	remaining := index
 	for _, child := range inner.children {
 		childItems := child.Weight()
-		// todo remove
-		//childItems := t.countItems(child)
-		assert(childItems == t.countItems(child), "child weight mismatch")
 		if remaining < childItems {
 			return t.atNode(child, height-1, remaining)
 		}
@@ -45,7 +43,8 @@ func (t *Tree[I, S, E]) atNode(n treeNode[I, S, E], height int, index int64) (I,
 //
 // itemIndex may be equal to Len(), in which case the full tree summary is
 // returned. itemIndex must not be negative.
-func (t *Tree[I, S, E]) PrefixSummary(itemIndex int64) (S, error) {
+func (t *Tree[I, S, E]) PrefixSummary(itemIndex int64) (sum S, err error) {
+	defer catchLoadFailure(&err)
 	zero := t.cfg.Monoid.Zero()
 	if t == nil || t.root == nil {
 		if itemIndex == 0 {
@@ -66,7 +65,8 @@ func (t *Tree[I, S, E]) PrefixSummary(itemIndex int64) (S, error) {
 //
 // itemIndex may be equal to Len(), in which case the full extension value is
 // returned. itemIndex must not be negative.
-func (t *Tree[I, S, E]) PrefixExt(itemIndex int64) (E, error) {
+func (t *Tree[I, S, E]) PrefixExt(itemIndex int64) (ext E, err error) {
+	defer catchLoadFailure(&err)
 	var zero E
 	if t == nil {
 		return zero, ErrInvalidConfig
@@ -98,7 +98,7 @@ func (t *Tree[I, S, E]) prefixSummaryNode(n treeNode[I, S, E], height int, remai
 		return acc, nil
 	}
 	if height == 1 {
-		leaf := n.(*leafNode[I, S, E])
+		leaf := mustLeaf[I, S, E](n)
 		//if remaining > len(leaf.items) {
 		if remaining > leaf.Weight() {
 			var zero S
@@ -111,14 +111,11 @@ func (t *Tree[I, S, E]) prefixSummaryNode(n treeNode[I, S, E], height int, remai
 		return sum, nil
 	}
 
-	inner := n.(*innerNode[I, S, E])
+	inner := mustInner[I, S, E](n)
 	sum := acc
 	rem := remaining
 	for _, child := range inner.children {
-		// todo remove
-		//childItems := t.countItems(child)
 		childItems := child.Weight()
-		assert(childItems == t.countItems(child), "child weight mismatch")
 		if rem >= childItems {
 			sum = t.cfg.Monoid.Add(sum, child.Summary())
 			rem -= childItems
@@ -143,7 +140,7 @@ func (t *Tree[I, S, E]) prefixExtNode(n treeNode[I, S, E], height int, remaining
 		return acc, nil
 	}
 	if height == 1 {
-		leaf := n.(*leafNode[I, S, E])
+		leaf := mustLeaf[I, S, E](n)
 		if remaining > leaf.Weight() {
 			var zero E
 			return zero, ErrIndexOutOfBounds
@@ -157,14 +154,11 @@ func (t *Tree[I, S, E]) prefixExtNode(n treeNode[I, S, E], height int, remaining
 		return sum, nil
 	}
 
-	inner := n.(*innerNode[I, S, E])
+	inner := mustInner[I, S, E](n)
 	sum := acc
 	rem := remaining
 	for _, child := range inner.children {
-		// todo remove
-		//childItems := t.countItems(child)
 		childItems := child.Weight()
-		assert(childItems == t.countItems(child), "child weight mismatch")
 		if rem >= childItems {
 			sum = t.cfg.Extension.Add(sum, child.Ext())
 			rem -= childItems
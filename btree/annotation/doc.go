@@ -0,0 +1,47 @@
+/*
+Package annotation provides Index, a persistent index of annotations anchored
+to text positions.
+
+Annotations like bookmarks, diagnostics or breakpoints cover a range [From,To)
+of a text and carry a value. When the text is edited, the index is told about
+the edit and moves the annotations along with the text:
+
+	idx, bm, _ := annotation.Index[string]{}.Add(10, 10, "bookmark", annotation.Options{})
+	idx, _ = idx.InsertText(0, 5)       // bm now is at position 15
+	for _, a := range idx.AnnotationsIn(0, 20) {
+		fmt.Println(a.From, a.To, a.Value)
+	}
+
+Annotations are stored in a btree.Tree, ordered by start position. Each entry
+records its start relative to the start of the previous entry, so an edit
+shifts all annotations behind it by touching a single entry. Summaries track
+how far the annotations of a subtree reach, which lets overlap queries skip
+subtrees ending before the queried range.
+
+Adding and removing annotations is O(log n). Inserting or deleting text is
+O(log n) plus O(log n) for every annotation which starts at the edit position,
+contains it, or starts within a deleted range. Overlap queries are O(log n)
+per annotation found.
+
+# Gravity and Deletion
+
+An anchor at exactly the position where text is inserted either stays in
+front of the new text (StickLeft, the default) or moves behind it
+(StickRight). Gravity is set separately for the start and the end of an
+annotation. With the default options, text inserted at the start of an
+annotation becomes part of it, while text inserted at its end does not.
+
+Anchors inside a deleted range move to the start of the deletion. An annotation
+which has been covered completely by a deletion is kept as an empty annotation,
+unless it has option Evaporate set, in which case it is removed.
+
+Like all btree-based collections, Index values are immutable. Every edit
+returns a new index and leaves the original unchanged.
+*/
+package annotation
+
+func assert(condition bool, msg string) {
+	if !condition {
+		panic(msg)
+	}
+}
@@ -0,0 +1,7 @@
+package annotation
+
+import "errors"
+
+// ErrInvalidRange signals a negative position or length, or a range with
+// from > to.
+var ErrInvalidRange = errors.New("annotation: invalid range")
@@ -0,0 +1,388 @@
+package annotation
+
+import (
+	"cmp"
+	"fmt"
+	"iter"
+	"slices"
+
+	"github.com/npillmayer/cords/btree"
+)
+
+// Gravity decides where an anchor goes if text is inserted exactly at its
+// position.
+type Gravity uint8
+
+const (
+	StickLeft  Gravity = iota // anchor stays in front of inserted text
+	StickRight                // anchor moves behind inserted text
+)
+
+func (g Gravity) String() string {
+	if g == StickRight {
+		return "right"
+	}
+	return "left"
+}
+
+// Options control how an annotation follows edits of the text.
+type Options struct {
+	StartGravity Gravity // gravity of the annotation's start
+	EndGravity   Gravity // gravity of the annotation's end
+	Evaporate    bool    // remove the annotation if a deletion covers it
+}
+
+// ID identifies an annotation within the edit history of an index.
+type ID uint64
+
+// Annotation is a value attached to the range [From,To) of a text.
+type Annotation[T any] struct {
+	ID       ID
+	From, To int64
+	Value    T
+	Options  Options
+}
+
+// Index is a persistent collection of annotations, ordered by start position.
+//
+// The zero value is an empty index, ready to use. Index values may be copied
+// and shared between goroutines freely.
+type Index[T any] struct {
+	tree *btree.Tree[entry[T], summary, btree.NO_EXT]
+	next ID // last ID handed out
+}
+
+// entry is an annotation as stored in the tree. Its start is relative to the
+// start of the previous entry.
+type entry[T any] struct {
+	gap    int64 // distance from the previous entry's start
+	length int64
+	id     ID
+	value  T
+	opts   Options
+}
+
+// summary describes a run of entries, relative to the start of the entry
+// preceding the run.
+type summary struct {
+	span  int64 // start of the last entry
+	count int64 // number of entries
+	reach int64 // maximum end of an entry, with empty entries counting as 1 wide
+}
+
+func (e entry[T]) Summary() summary {
+	return summary{span: e.gap, count: 1, reach: e.gap + max(e.length, 1)}
+}
+
+func (e entry[T]) annotation(start int64) Annotation[T] {
+	return Annotation[T]{
+		ID:      e.id,
+		From:    start,
+		To:      start + e.length,
+		Value:   e.value,
+		Options: e.opts,
+	}
+}
+
+type summaryMonoid struct{}
+
+func (summaryMonoid) Zero() summary { return summary{} }
+
+func (summaryMonoid) Add(left, right summary) summary {
+	sum := summary{span: left.span + right.span, count: left.count + right.count}
+	switch {
+	case right.count == 0:
+		sum.reach = left.reach
+	case left.count == 0:
+		sum.reach = left.span + right.reach
+	default:
+		sum.reach = max(left.reach, left.span+right.reach)
+	}
+	return sum
+}
+
+// startDimension seeks entries by start position.
+type startDimension struct{}
+
+func (startDimension) Zero() int64                    { return 0 }
+func (startDimension) Add(acc int64, s summary) int64 { return acc + s.span }
+func (startDimension) Compare(acc, target int64) int  { return cmp.Compare(acc, target) }
+
+// Len returns the number of annotations in x.
+func (x Index[T]) Len() int {
+	return int(x.tree.Len())
+}
+
+// Add returns an index with an annotation for range [from,to) added. The new
+// annotation is placed behind all annotations starting at the same position.
+func (x Index[T]) Add(from, to int64, value T, opts Options) (Index[T], Annotation[T], error) {
+	if from < 0 || from > to {
+		return x, Annotation[T]{}, fmt.Errorf("%w: [%d,%d)", ErrInvalidRange, from, to)
+	}
+	i := x.seek(from + 1)
+	x.next++
+	e := entry[T]{length: to - from, id: x.next, value: value, opts: opts}
+	edited := []edit[T]{{e, from}}
+	end := i
+	if i < x.tree.Len() {
+		edited = append(edited, x.edits(i, i+1)...)
+		end++
+	}
+	x = x.splice(i, end, edited)
+	return x, e.annotation(from), nil
+}
+
+// Remove returns an index without annotation a. It reports false if a is not
+// contained in x.
+func (x Index[T]) Remove(a Annotation[T]) (Index[T], bool) {
+	first, last := x.seek(a.From), x.seek(a.From+1)
+	for i, e := range x.tree.ItemRange(first, last) {
+		if e.id != a.ID {
+			continue
+		}
+		end := min(i+2, x.tree.Len())
+		edited := x.edits(i+1, end)
+		return x.splice(i, end, edited), true
+	}
+	return x, false
+}
+
+// AnnotationsIn returns all annotations intersecting [from,to), ordered by
+// start position.
+//
+// An empty annotation at position p intersects [from,to) if from <= p < to.
+// An empty range [p,p) finds the annotations containing p and the empty
+// annotations at p.
+func (x Index[T]) AnnotationsIn(from, to int64) []Annotation[T] {
+	var found []Annotation[T]
+	end := max(to, from+1)
+	for prefix, e := range x.reaching(from + 1) {
+		start := prefix.span + e.gap
+		if start >= end {
+			break
+		}
+		found = append(found, e.annotation(start))
+	}
+	return found
+}
+
+// All returns an iterator over all annotations of x, ordered by start
+// position.
+func (x Index[T]) All() iter.Seq[Annotation[T]] {
+	return func(yield func(Annotation[T]) bool) {
+		if x.Len() == 0 {
+			return
+		}
+		var start int64
+		for _, e := range x.tree.ItemRange(0, x.tree.Len()) {
+			start += e.gap
+			if !yield(e.annotation(start)) {
+				return
+			}
+		}
+	}
+}
+
+// InsertText returns an index with all annotations moved to follow an
+// insertion of length bytes of text at pos.
+func (x Index[T]) InsertText(pos, length int64) (Index[T], error) {
+	if pos < 0 || length < 0 {
+		return x, fmt.Errorf("%w: insert %d at %d", ErrInvalidRange, length, pos)
+	}
+	if length == 0 || x.Len() == 0 {
+		return x, nil
+	}
+	shift := func(p int64, g Gravity) int64 {
+		if p > pos || p == pos && g == StickRight {
+			return p + length
+		}
+		return p
+	}
+	// Annotations starting in front of pos keep their start. If they reach pos,
+	// their end may move.
+	x = x.updateReaching(pos, pos, func(start int64, e entry[T]) int64 {
+		return shift(start+e.length, e.opts.EndGravity) - start
+	})
+	// Annotations starting at pos move according to their start's gravity,
+	// which may reorder them. The first annotation behind pos carries the
+	// shift for all later ones.
+	first, last := x.seek(pos), x.seek(pos+1)
+	end := min(last+1, x.tree.Len())
+	edited := x.edits(first, end)
+	for i := range edited {
+		ed := &edited[i]
+		from := shift(ed.start, ed.opts.StartGravity)
+		to := max(shift(ed.start+ed.length, ed.opts.EndGravity), from)
+		ed.start, ed.length = from, to-from
+	}
+	slices.SortStableFunc(edited, func(a, b edit[T]) int {
+		return cmp.Compare(a.start, b.start)
+	})
+	return x.splice(first, end, edited), nil
+}
+
+// DeleteText returns an index with all annotations moved to follow a deletion
+// of length bytes of text at pos.
+//
+// Anchors within the deleted range move to pos. Annotations covered by the
+// deletion are removed if they have option Evaporate set. Empty annotations at
+// pos or at pos+length are not covered.
+func (x Index[T]) DeleteText(pos, length int64) (Index[T], error) {
+	if pos < 0 || length < 0 {
+		return x, fmt.Errorf("%w: delete %d at %d", ErrInvalidRange, length, pos)
+	}
+	if length == 0 || x.Len() == 0 {
+		return x, nil
+	}
+	limit := pos + length
+	shift := func(p int64) int64 {
+		switch {
+		case p <= pos:
+			return p
+		case p <= limit:
+			return pos
+		}
+		return p - length
+	}
+	x = x.updateReaching(pos, pos+1, func(start int64, e entry[T]) int64 {
+		return shift(start+e.length) - start
+	})
+	// Annotations starting within [pos,limit] collapse onto pos, keeping their
+	// order. The first annotation behind limit carries the shift for all later
+	// ones.
+	first, last := x.seek(pos), x.seek(limit+1)
+	end := min(last+1, x.tree.Len())
+	edited := x.edits(first, end)
+	kept := edited[:0]
+	for _, ed := range edited {
+		from, to := ed.start, ed.start+ed.length
+		covered := from >= pos && to <= limit && !(from == to && (from == pos || from == limit))
+		if covered && ed.opts.Evaporate {
+			continue
+		}
+		ed.start = shift(from)
+		ed.length = shift(to) - ed.start
+		kept = append(kept, ed)
+	}
+	return x.splice(first, end, kept), nil
+}
+
+// --- Helpers ---------------------------------------------------------------
+
+// edit is an entry with its absolute start position, used while rewriting a
+// range of entries.
+type edit[T any] struct {
+	entry[T]
+	start int64
+}
+
+// seek returns the index of the first entry starting at or behind pos.
+func (x Index[T]) seek(pos int64) int64 {
+	if x.Len() == 0 {
+		return 0
+	}
+	cursor, err := btree.NewCursor(x.tree, startDimension{})
+	assert(err == nil, "annotation: cannot create cursor")
+	i, _, err := cursor.Seek(pos)
+	assert(err == nil, "annotation: seek failed")
+	return i
+}
+
+// edits returns the entries in [from,to) with their absolute start positions.
+func (x Index[T]) edits(from, to int64) []edit[T] {
+	if from >= to {
+		return nil
+	}
+	edited := make([]edit[T], 0, to-from)
+	start := x.startBefore(from)
+	for _, e := range x.tree.ItemRange(from, to) {
+		start += e.gap
+		edited = append(edited, edit[T]{e, start})
+	}
+	return edited
+}
+
+// startBefore returns the start of the entry in front of entry i, or 0 for
+// the first entry.
+func (x Index[T]) startBefore(i int64) int64 {
+	if i == 0 {
+		return 0
+	}
+	prefix, err := x.tree.PrefixSummary(i)
+	assert(err == nil, "annotation: cannot compute prefix")
+	return prefix.span
+}
+
+// splice replaces the entries in [from,to) by edited, which have to be ordered
+// by start position. Entries behind to keep their absolute positions, so the
+// entry at to has to be part of the replaced range if the last start changes.
+func (x Index[T]) splice(from, to int64, edited []edit[T]) Index[T] {
+	entries := make([]entry[T], len(edited))
+	prev := x.startBefore(from)
+	for i, ed := range edited {
+		assert(ed.start >= prev, "annotation: entries out of order")
+		entries[i] = ed.entry
+		entries[i].gap = ed.start - prev
+		prev = ed.start
+	}
+	tree := x.ensureTree()
+	var err error
+	if to > from {
+		tree, err = tree.DeleteRange(from, to-from)
+		assert(err == nil, "annotation: cannot delete entries")
+	}
+	if len(entries) > 0 {
+		tree, err = tree.InsertAt(from, entries...)
+		assert(err == nil, "annotation: cannot insert entries")
+	}
+	return Index[T]{tree: tree, next: x.next}
+}
+
+// reaching returns an iterator over all entries with an end at or behind pos,
+// treating empty entries as 1 wide, together with their prefix summaries.
+func (x Index[T]) reaching(pos int64) iter.Seq2[summary, entry[T]] {
+	return func(yield func(summary, entry[T]) bool) {
+		if x.Len() == 0 {
+			return
+		}
+		keep := func(prefix, s summary) bool {
+			return s.count > 0 && prefix.span+s.reach >= pos
+		}
+		for prefix, e := range x.tree.Filter(keep) {
+			if !yield(prefix, e) {
+				return
+			}
+		}
+	}
+}
+
+// updateReaching sets the length of all entries which start in front of
+// before and reach pos to the value returned by length.
+func (x Index[T]) updateReaching(before, pos int64, length func(start int64, e entry[T]) int64) Index[T] {
+	var updates []edit[T]
+	var indexes []int64
+	for prefix, e := range x.reaching(pos) {
+		start := prefix.span + e.gap
+		if start >= before {
+			break
+		}
+		if l := length(start, e); l != e.length {
+			e.length = l
+			updates = append(updates, edit[T]{e, start})
+			indexes = append(indexes, prefix.count)
+		}
+	}
+	for i, ed := range updates {
+		x = x.splice(indexes[i], indexes[i]+1, []edit[T]{ed})
+	}
+	return x
+}
+
+func (x Index[T]) ensureTree() *btree.Tree[entry[T], summary, btree.NO_EXT] {
+	if x.tree != nil {
+		return x.tree
+	}
+	tree, err := btree.New(btree.Config[entry[T], summary, btree.NO_EXT]{Monoid: summaryMonoid{}})
+	assert(err == nil, "annotation: cannot create tree")
+	return tree
+}
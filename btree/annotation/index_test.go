@@ -0,0 +1,222 @@
+package annotation
+
+import (
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"slices"
+	"testing"
+)
+
+func spans[T any](as []Annotation[T]) string {
+	s := ""
+	for _, a := range as {
+		s += fmt.Sprintf("%v[%d,%d)", a.Value, a.From, a.To)
+	}
+	return s
+}
+
+func mustAdd[T any](t *testing.T, x Index[T], from, to int64, v T, opts Options) Index[T] {
+	t.Helper()
+	x, _, err := x.Add(from, to, v, opts)
+	if err != nil {
+		t.Fatalf("add [%d,%d) failed: %v", from, to, err)
+	}
+	return x
+}
+
+func TestAddAndQuery(t *testing.T) {
+	var x Index[string]
+	x = mustAdd(t, x, 10, 20, "a", Options{})
+	x = mustAdd(t, x, 0, 5, "b", Options{})
+	x = mustAdd(t, x, 15, 15, "c", Options{})
+	x = mustAdd(t, x, 10, 12, "d", Options{})
+	if x.Len() != 4 {
+		t.Fatalf("expected 4 annotations, have %d", x.Len())
+	}
+	if got := spans(slices.Collect(x.All())); got != "b[0,5)a[10,20)d[10,12)c[15,15)" {
+		t.Errorf("unexpected order: %s", got)
+	}
+	for _, q := range []struct {
+		from, to int64
+		want     string
+	}{
+		{0, 100, "b[0,5)a[10,20)d[10,12)c[15,15)"},
+		{5, 10, ""},
+		{4, 11, "b[0,5)a[10,20)d[10,12)"},
+		{12, 15, "a[10,20)"},
+		{15, 16, "a[10,20)c[15,15)"},
+		{15, 15, "a[10,20)c[15,15)"},
+		{20, 30, ""},
+	} {
+		if got := spans(x.AnnotationsIn(q.from, q.to)); got != q.want {
+			t.Errorf("AnnotationsIn(%d,%d) = %s, want %s", q.from, q.to, got, q.want)
+		}
+	}
+	if _, _, err := x.Add(5, 4, "x", Options{}); !errors.Is(err, ErrInvalidRange) {
+		t.Errorf("expected ErrInvalidRange, got %v", err)
+	}
+}
+
+func TestIndexIsPersistent(t *testing.T) {
+	x1 := mustAdd(t, Index[int]{}, 3, 6, 1, Options{})
+	x2, err := x1.InsertText(0, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	x3, a, _ := x2.Add(0, 1, 2, Options{})
+	if got := spans(slices.Collect(x1.All())); got != "1[3,6)" {
+		t.Errorf("original index changed: %s", got)
+	}
+	if got := spans(slices.Collect(x3.All())); got != "2[0,1)1[13,16)" {
+		t.Errorf("unexpected edited index: %s", got)
+	}
+	x4, ok := x3.Remove(a)
+	if !ok || spans(slices.Collect(x4.All())) != "1[13,16)" {
+		t.Errorf("remove failed: ok=%v, %s", ok, spans(slices.Collect(x4.All())))
+	}
+	if _, ok := x4.Remove(a); ok {
+		t.Errorf("expected second remove to fail")
+	}
+}
+
+func TestInsertTextGravity(t *testing.T) {
+	var x Index[string]
+	x = mustAdd(t, x, 10, 20, "default", Options{})
+	x = mustAdd(t, x, 10, 20, "expand", Options{StartGravity: StickLeft, EndGravity: StickRight})
+	x = mustAdd(t, x, 10, 20, "shrink", Options{StartGravity: StickRight, EndGravity: StickLeft})
+	x = mustAdd(t, x, 10, 10, "left", Options{})
+	x = mustAdd(t, x, 10, 10, "right", Options{StartGravity: StickRight, EndGravity: StickRight})
+	x = mustAdd(t, x, 30, 31, "later", Options{})
+	x, _ = x.InsertText(10, 3)
+	want := "default[10,23)expand[10,23)left[10,10)shrink[13,23)right[13,13)later[33,34)"
+	if got := spans(slices.Collect(x.All())); got != want {
+		t.Errorf("insert at start:\n got %s\nwant %s", got, want)
+	}
+	x, _ = x.InsertText(23, 2)
+	want = "default[10,23)expand[10,25)left[10,10)shrink[13,23)right[13,13)later[35,36)"
+	if got := spans(slices.Collect(x.All())); got != want {
+		t.Errorf("insert at end:\n got %s\nwant %s", got, want)
+	}
+}
+
+func TestDeleteText(t *testing.T) {
+	var x Index[string]
+	x = mustAdd(t, x, 0, 10, "span", Options{})
+	x = mustAdd(t, x, 4, 6, "inner", Options{})
+	x = mustAdd(t, x, 4, 6, "gone", Options{Evaporate: true})
+	x = mustAdd(t, x, 4, 4, "at-pos", Options{Evaporate: true})
+	x = mustAdd(t, x, 5, 5, "inside", Options{})
+	x = mustAdd(t, x, 5, 12, "tail", Options{})
+	x = mustAdd(t, x, 20, 22, "later", Options{})
+	x, _ = x.DeleteText(4, 3)
+	want := "span[0,7)inner[4,4)at-pos[4,4)inside[4,4)tail[4,9)later[17,19)"
+	if got := spans(slices.Collect(x.All())); got != want {
+		t.Errorf("delete:\n got %s\nwant %s", got, want)
+	}
+}
+
+// model is a naive reference implementation of an index.
+type model []Annotation[int]
+
+func (m model) insertText(pos, n int64) model {
+	shift := func(p int64, g Gravity) int64 {
+		if p > pos || p == pos && g == StickRight {
+			return p + n
+		}
+		return p
+	}
+	var r model
+	for _, a := range m {
+		from := shift(a.From, a.Options.StartGravity)
+		a.To = max(shift(a.To, a.Options.EndGravity), from)
+		a.From = from
+		r = append(r, a)
+	}
+	slices.SortStableFunc(r, func(a, b Annotation[int]) int { return int(a.From - b.From) })
+	return r
+}
+
+func (m model) deleteText(pos, n int64) model {
+	limit := pos + n
+	shift := func(p int64) int64 {
+		switch {
+		case p <= pos:
+			return p
+		case p <= limit:
+			return pos
+		}
+		return p - n
+	}
+	var r model
+	for _, a := range m {
+		covered := a.From >= pos && a.To <= limit && !(a.From == a.To && (a.From == pos || a.From == limit))
+		if covered && a.Options.Evaporate {
+			continue
+		}
+		a.From, a.To = shift(a.From), shift(a.To)
+		r = append(r, a)
+	}
+	return r
+}
+
+func (m model) in(from, to int64) model {
+	var r model
+	for _, a := range m {
+		if a.From < max(to, from+1) && max(a.To, a.From+1) > from {
+			r = append(r, a)
+		}
+	}
+	return r
+}
+
+func TestIndexAgainstModel(t *testing.T) {
+	rnd := rand.New(rand.NewPCG(7, 34))
+	var x Index[int]
+	var m model
+	for step := range 3000 {
+		pos := rnd.Int64N(200)
+		n := rnd.Int64N(20)
+		switch op := rnd.IntN(10); {
+		case op < 4:
+			opts := Options{
+				StartGravity: Gravity(rnd.IntN(2)),
+				EndGravity:   Gravity(rnd.IntN(2)),
+				Evaporate:    rnd.IntN(2) == 0,
+			}
+			var a Annotation[int]
+			x, a, _ = x.Add(pos, pos+n, step, opts)
+			i := len(m)
+			for i > 0 && m[i-1].From > pos {
+				i--
+			}
+			m = slices.Insert(m, i, a)
+		case op < 6:
+			x, _ = x.InsertText(pos, n)
+			m = m.insertText(pos, n)
+		case op < 8:
+			x, _ = x.DeleteText(pos, n)
+			m = m.deleteText(pos, n)
+		default:
+			if len(m) > 0 {
+				i := rnd.IntN(len(m))
+				var ok bool
+				if x, ok = x.Remove(m[i]); !ok {
+					t.Fatalf("step %d: remove of %v failed", step, m[i])
+				}
+				m = slices.Delete(m, i, i+1)
+			}
+		}
+		if got, want := spans(slices.Collect(x.All())), spans(m); got != want {
+			t.Fatalf("step %d: index and model differ:\n got %s\nwant %s", step, got, want)
+		}
+		from := rnd.Int64N(220)
+		to := from + rnd.Int64N(30)
+		if got, want := spans(x.AnnotationsIn(from, to)), spans(m.in(from, to)); got != want {
+			t.Fatalf("step %d: AnnotationsIn(%d,%d) differs:\n got %s\nwant %s", step, from, to, got, want)
+		}
+	}
+	if err := x.tree.Check(); err != nil {
+		t.Fatalf("tree invariants violated: %v", err)
+	}
+}
@@ -1,6 +1,13 @@
 package btree
 
-import "testing"
+import (
+	"math/rand"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/npillmayer/cords/chunk"
+)
 
 func BenchmarkInsertAtStub(b *testing.B) {
 	tree, err := New[textChunk, textSummary](Config[textChunk, textSummary, NO_EXT]{
@@ -12,3 +19,230 @@ func BenchmarkInsertAtStub(b *testing.B) {
 	_ = tree
 	b.Skip("benchmark scaffold: InsertAt is not implemented yet")
 }
+
+// benchBases lists the branching factors compared by fan-out benchmarks.
+var benchBases = []int{4, Base, 8, 16, 32}
+
+func buildChunkTree(b *testing.B, base int, n int) *Tree[chunk.Chunk, chunk.Summary, NO_EXT] {
+	b.Helper()
+	tree, err := New[chunk.Chunk, chunk.Summary](Config[chunk.Chunk, chunk.Summary, NO_EXT]{
+		Monoid: chunk.Monoid{},
+		Base:   base,
+	})
+	if err != nil {
+		b.Fatalf("setup failed: %v", err)
+	}
+	c, err := chunk.New(strings.Repeat("0123456789abcde\n", chunk.MaxBase/16))
+	if err != nil {
+		b.Fatalf("setup failed: %v", err)
+	}
+	for range n {
+		if tree, err = tree.InsertAt(tree.Len(), c); err != nil {
+			b.Fatalf("setup failed: %v", err)
+		}
+	}
+	return tree
+}
+
+func BenchmarkFanOutInsertAt(b *testing.B) {
+	for _, base := range benchBases {
+		b.Run("base="+strconv.Itoa(base), func(b *testing.B) {
+			tree := buildChunkTree(b, base, 2000)
+			c, _ := chunk.New("x")
+			rnd := rand.New(rand.NewSource(1))
+			for b.Loop() {
+				if _, err := tree.InsertAt(rnd.Int63n(tree.Len()), c); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkFanOutSeekByte(b *testing.B) {
+	for _, base := range benchBases {
+		b.Run("base="+strconv.Itoa(base), func(b *testing.B) {
+			tree := buildChunkTree(b, base, 2000)
+			cursor, err := NewCursor(tree, chunk.ByteDimension{})
+			if err != nil {
+				b.Fatal(err)
+			}
+			total := tree.Summary().Bytes
+			rnd := rand.New(rand.NewSource(1))
+			for b.Loop() {
+				if _, _, err := cursor.Seek(uint64(rnd.Int63n(int64(total))) + 1); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkFanOutIterate(b *testing.B) {
+	for _, base := range benchBases {
+		b.Run("base="+strconv.Itoa(base), func(b *testing.B) {
+			tree := buildChunkTree(b, base, 2000)
+			for b.Loop() {
+				var n int
+				tree.ForEachItem(func(c chunk.Chunk) bool {
+					n += c.Len()
+					return true
+				})
+			}
+		})
+	}
+}
+
+func benchChunks(b *testing.B, n int) []chunk.Chunk {
+	b.Helper()
+	c, err := chunk.New(strings.Repeat("0123456789abcde\n", chunk.MaxBase/16))
+	if err != nil {
+		b.Fatalf("setup failed: %v", err)
+	}
+	items := make([]chunk.Chunk, n)
+	for i := range items {
+		items[i] = c
+	}
+	return items
+}
+
+func BenchmarkFromItems(b *testing.B) {
+	cfg := Config[chunk.Chunk, chunk.Summary, NO_EXT]{Monoid: chunk.Monoid{}}
+	items := benchChunks(b, 1<<18)
+	for _, workers := range []int{1, 0} {
+		b.Run("workers="+strconv.Itoa(workers), func(b *testing.B) {
+			for b.Loop() {
+				if _, err := FromItemsParallel(cfg, items, workers); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkParallelReduce(b *testing.B) {
+	cfg := Config[chunk.Chunk, chunk.Summary, NO_EXT]{Monoid: chunk.Monoid{}}
+	tree, err := FromItems(cfg, benchChunks(b, 1<<18))
+	if err != nil {
+		b.Fatal(err)
+	}
+	countWords := func(c chunk.Chunk) int { return len(strings.Fields(c.String())) }
+	add := func(x, y int) int { return x + y }
+	for _, workers := range []int{1, 0} {
+		b.Run("workers="+strconv.Itoa(workers), func(b *testing.B) {
+			for b.Loop() {
+				ParallelReduce(tree, countWords, add, workers)
+			}
+		})
+	}
+}
+
+func BenchmarkDeleteRange(b *testing.B) {
+	cfg := Config[chunk.Chunk, chunk.Summary, NO_EXT]{Monoid: chunk.Monoid{}}
+	tree, err := FromItems(cfg, benchChunks(b, 1<<16))
+	if err != nil {
+		b.Fatal(err)
+	}
+	for _, count := range []int64{16, 4096} {
+		b.Run("count="+strconv.FormatInt(count, 10), func(b *testing.B) {
+			for b.Loop() {
+				if _, err := tree.DeleteRange(1000, count); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkInsertAtMany(b *testing.B) {
+	cfg := Config[chunk.Chunk, chunk.Summary, NO_EXT]{Monoid: chunk.Monoid{}}
+	tree, err := FromItems(cfg, benchChunks(b, 1<<16))
+	if err != nil {
+		b.Fatal(err)
+	}
+	for _, count := range []int{16, 4096} {
+		items := benchChunks(b, count)
+		b.Run("count="+strconv.Itoa(count), func(b *testing.B) {
+			for b.Loop() {
+				if _, err := tree.InsertAt(1000, items...); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkTyping inserts 1000 single chunks at a moving position, once
+// through the persistent API and once through a finger.
+func BenchmarkTyping(b *testing.B) {
+	cfg := Config[chunk.Chunk, chunk.Summary, NO_EXT]{Monoid: chunk.Monoid{}}
+	tree, err := FromItems(cfg, benchChunks(b, 1<<16))
+	if err != nil {
+		b.Fatal(err)
+	}
+	c := benchChunks(b, 1)[0]
+	b.Run("persistent", func(b *testing.B) {
+		for b.Loop() {
+			t := tree
+			for i := range int64(1000) {
+				if t, err = t.InsertAt(5000+i, c); err != nil {
+					b.Fatal(err)
+				}
+			}
+		}
+	})
+	b.Run("finger", func(b *testing.B) {
+		for b.Loop() {
+			f, _ := NewFinger(tree)
+			for i := range int64(1000) {
+				if err := f.InsertAt(5000+i, c); err != nil {
+					b.Fatal(err)
+				}
+			}
+			f.Freeze()
+		}
+	})
+}
+
+// BenchmarkNodeStorage measures edits on a tree of the default base. Path
+// copying allocates a new node for each level an edit touches, so these are
+// dominated by node allocation.
+func BenchmarkNodeStorage(b *testing.B) {
+	tree, err := New[chunk.Chunk, chunk.Summary](Config[chunk.Chunk, chunk.Summary, NO_EXT]{
+		Monoid: chunk.Monoid{},
+	})
+	if err != nil {
+		b.Fatalf("setup failed: %v", err)
+	}
+	c, err := chunk.New(strings.Repeat("0123456789abcde\n", 4))
+	if err != nil {
+		b.Fatalf("setup failed: %v", err)
+	}
+	for range 5000 {
+		if tree, err = tree.InsertAt(tree.Len(), c); err != nil {
+			b.Fatalf("setup failed: %v", err)
+		}
+	}
+	b.Run("InsertAt", func(b *testing.B) {
+		rnd := rand.New(rand.NewSource(1))
+		b.ReportAllocs()
+		for b.Loop() {
+			if _, err := tree.InsertAt(rnd.Int63n(tree.Len()), c); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("SplitConcat", func(b *testing.B) {
+		rnd := rand.New(rand.NewSource(1))
+		b.ReportAllocs()
+		for b.Loop() {
+			left, right, err := tree.SplitAt(rnd.Int63n(tree.Len()))
+			if err != nil {
+				b.Fatal(err)
+			}
+			if _, err := left.Concat(right); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
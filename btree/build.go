@@ -0,0 +1,85 @@
+package btree
+
+// FromItems creates a tree holding items in order.
+//
+// The tree is bulk-loaded bottom-up in O(n): leaves and internal nodes are
+// packed evenly with between Base and 2*Base entries each. This is faster than
+// repeated insertion and yields a compact tree of minimal height.
+func FromItems[I SummarizedItem[S], S, E any](cfg Config[I, S, E], items []I) (*Tree[I, S, E], error) {
+	return FromItemsParallel(cfg, items, 1)
+}
+
+// FromItemsParallel works like FromItems, but builds the nodes of each tree
+// level concurrently with up to workers goroutines. If workers is less than 1,
+// GOMAXPROCS goroutines are used.
+//
+// The resulting tree is identical to the one built by FromItems. Item and
+// summary methods are called concurrently and must be safe for concurrent use.
+func FromItemsParallel[I SummarizedItem[S], S, E any](cfg Config[I, S, E], items []I, workers int) (*Tree[I, S, E], error) {
+	tree, err := New(cfg)
+	if err != nil {
+		return nil, err
+	}
+	tree.root, tree.height = tree.buildNodes(items, workers)
+	return tree, nil
+}
+
+// buildNodes bulk-loads a subtree from items and returns its root and height.
+//
+// Every level is packed with packSizes, so all non-root nodes satisfy the
+// occupancy bounds of the tree. Nodes of a level are independent of each
+// other and are built by up to workers goroutines.
+func (t *Tree[I, S, E]) buildNodes(items []I, workers int) (treeNode[I, S, E], int) {
+	if len(items) == 0 {
+		return nil, 0
+	}
+	sizes, offsets := t.packSizes(len(items))
+	level := make([]treeNode[I, S, E], len(sizes))
+	parallelFor(len(sizes), workers, func(i int) {
+		level[i] = t.makeLeaf(items[offsets[i] : offsets[i]+sizes[i]])
+	})
+	return t.buildLevels(level, 1, workers)
+}
+
+// buildLevels stacks internal levels on top of a level of nodes of the given
+// height until a single root remains, and returns root and tree height.
+func (t *Tree[I, S, E]) buildLevels(level []treeNode[I, S, E], height int, workers int) (treeNode[I, S, E], int) {
+	assert(len(level) > 0, "buildLevels called with empty level")
+	for len(level) > 1 {
+		sizes, offsets := t.packSizes(len(level))
+		parents := make([]treeNode[I, S, E], len(sizes))
+		parallelFor(len(sizes), workers, func(i int) {
+			parents[i] = t.makeInternal(level[offsets[i] : offsets[i]+sizes[i]]...)
+		})
+		level = parents
+		height++
+	}
+	return level[0], height
+}
+
+// packSizes partitions n entries of one tree level into groups, each of which
+// becomes a node of the next level. It returns group sizes and start offsets.
+//
+// If n fits into a single node, a single group is returned (it will become the
+// root and is exempt from minimum occupancy). Otherwise n is spread evenly over
+// g = ⌈n/2Base⌉ groups; as n > 2Base·(g-1), every group holds at least Base
+// entries.
+func (t *Tree[I, S, E]) packSizes(n int) (sizes []int, offsets []int) {
+	assert(n > 0, "packSizes called for empty level")
+	maxItems := t.maxItems()
+	groups := (n + maxItems - 1) / maxItems
+	sizes = make([]int, groups)
+	offsets = make([]int, groups)
+	q, r := n/groups, n%groups
+	offset := 0
+	for i := range groups {
+		sizes[i] = q
+		if i < r {
+			sizes[i]++
+		}
+		offsets[i] = offset
+		offset += sizes[i]
+	}
+	assert(groups == 1 || q >= t.base(), "packSizes violates occupancy bounds")
+	return sizes, offsets
+}
@@ -0,0 +1,72 @@
+package btree
+
+import (
+	"strconv"
+	"testing"
+)
+
+func textItems(n int) []textChunk {
+	items := make([]textChunk, n)
+	for i := range n {
+		items[i] = fromString(strconv.Itoa(i))
+	}
+	return items
+}
+
+func TestFromItemsBuildsValidTrees(t *testing.T) {
+	for _, base := range []int{MinBase, Base, 16} {
+		cfg := Config[textChunk, textSummary, NO_EXT]{Monoid: textMonoid{}, Base: base}
+		for _, n := range []int{0, 1, base, 2 * base, 2*base + 1, 4*base*base + 3, 1000} {
+			items := textItems(n)
+			tree, err := FromItems(cfg, items)
+			if err != nil {
+				t.Fatalf("base %d, n=%d: FromItems failed: %v", base, n, err)
+			}
+			if err := tree.Check(); err != nil {
+				t.Fatalf("base %d, n=%d: invariants failed: %v", base, n, err)
+			}
+			if tree.Len() != int64(n) {
+				t.Fatalf("base %d, n=%d: unexpected length %d", base, n, tree.Len())
+			}
+			got := collectTextItems(tree)
+			for i := range items {
+				if got[i] != string(items[i]) {
+					t.Fatalf("base %d, n=%d: item mismatch at %d", base, n, i)
+				}
+			}
+		}
+	}
+}
+
+func TestFromItemsParallelMatchesSequential(t *testing.T) {
+	cfg := Config[textChunk, textSummary, NO_EXT]{Monoid: textMonoid{}}
+	items := textItems(5000)
+	seq, err := FromItems(cfg, items)
+	if err != nil {
+		t.Fatalf("FromItems failed: %v", err)
+	}
+	for _, workers := range []int{0, 2, 7} {
+		par, err := FromItemsParallel(cfg, items, workers)
+		if err != nil {
+			t.Fatalf("FromItemsParallel(%d) failed: %v", workers, err)
+		}
+		if err := par.Check(); err != nil {
+			t.Fatalf("workers=%d: invariants failed: %v", workers, err)
+		}
+		if par.Height() != seq.Height() || par.Summary() != seq.Summary() {
+			t.Fatalf("workers=%d: tree shape differs from sequential build", workers)
+		}
+		got, want := collectTextItems(par), collectTextItems(seq)
+		for i := range want {
+			if got[i] != want[i] {
+				t.Fatalf("workers=%d: item mismatch at %d", workers, i)
+			}
+		}
+	}
+}
+
+func TestFromItemsRejectsInvalidConfig(t *testing.T) {
+	if _, err := FromItems(Config[textChunk, textSummary, NO_EXT]{}, textItems(3)); err == nil {
+		t.Fatalf("expected error for missing monoid")
+	}
+}
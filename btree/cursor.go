@@ -67,6 +67,7 @@ func NewExtCursor[I SummarizedItem[S], S, E any, K any](tree *Tree[I, S, E], dim
 // target is beyond the total accumulated dimension, Seek returns (Len(), total,
 // nil).
 func (c *Cursor[I, S, E, K]) Seek(target K) (itemIndex int64, acc K, err error) {
+	defer catchLoadFailure(&err)
 	if c == nil || c.tree == nil || c.dim == nil {
 		var zero K
 		return 0, zero, fmt.Errorf("%w: cursor not initialized", ErrInvalidDimension)
@@ -89,6 +90,7 @@ func (c *Cursor[I, S, E, K]) Seek(target K) (itemIndex int64, acc K, err error)
 // Returns found=false when target is at/before Zero(), when the tree is empty,
 // or when target is beyond the total accumulated dimension.
 func (c *Cursor[I, S, E, K]) SeekItem(target K) (itemIndex int64, item I, acc K, found bool, err error) {
+	defer catchLoadFailure(&err)
 	if c == nil || c.tree == nil || c.dim == nil {
 		var zeroI I
 		var zeroK K
@@ -113,6 +115,7 @@ func (c *Cursor[I, S, E, K]) SeekItem(target K) (itemIndex int64, item I, acc K,
 // target is beyond the total accumulated extension dimension, Seek returns
 // (Len(), total, nil).
 func (c *ExtCursor[I, S, E, K]) Seek(target K) (itemIndex int64, acc K, err error) {
+	defer catchLoadFailure(&err)
 	if c == nil || c.tree == nil || c.dim == nil {
 		var zero K
 		return 0, zero, fmt.Errorf("%w: cursor not initialized", ErrInvalidDimension)
@@ -140,6 +143,7 @@ func (c *ExtCursor[I, S, E, K]) Seek(target K) (itemIndex int64, acc K, err erro
 // Returns found=false when target is at/before Zero(), when the tree is empty,
 // or when target is beyond the total accumulated extension dimension.
 func (c *ExtCursor[I, S, E, K]) SeekItem(target K) (itemIndex int64, item I, acc K, found bool, err error) {
+	defer catchLoadFailure(&err)
 	if c == nil || c.tree == nil || c.dim == nil {
 		var zeroI I
 		var zeroK K
@@ -216,7 +220,7 @@ func seekNodeWithOps[I SummarizedItem[S], S, E any, K any](tree *Tree[I, S, E],
 	//
 	assert(n != nil, "seekNodeWithOps called with nil node")
 	if n.isLeaf() {
-		leaf := n.(*leafNode[I, S, E])
+		leaf := mustLeaf[I, S, E](n)
 		cur := acc
 		for i, item := range leaf.items {
 			next := ops.addItem(cur, item)
@@ -227,7 +231,7 @@ func seekNodeWithOps[I SummarizedItem[S], S, E any, K any](tree *Tree[I, S, E],
 		}
 		return startIndex + int64(len(leaf.items)), cur, false, nil
 	}
-	inner := n.(*innerNode[I, S, E])
+	inner := mustInner[I, S, E](n)
 	curIdx := startIndex
 	curAcc := acc
 	for _, child := range inner.children {
@@ -255,7 +259,7 @@ func seekNodeItemWithOps[I SummarizedItem[S], S, E any, K any](
 	assert(n != nil, "seekNodeItemWithOps called with nil node")
 	var zeroI I
 	if n.isLeaf() {
-		leaf := n.(*leafNode[I, S, E])
+		leaf := mustLeaf[I, S, E](n)
 		cur := acc
 		for i, entry := range leaf.items {
 			next := ops.addItem(cur, entry)
@@ -268,7 +272,7 @@ func seekNodeItemWithOps[I SummarizedItem[S], S, E any, K any](
 		//return startIndex + len(leaf.items), zeroI, cur, false, nil
 		return startIndex + leaf.Weight(), zeroI, cur, false, nil
 	}
-	inner := n.(*innerNode[I, S, E])
+	inner := mustInner[I, S, E](n)
 	curIdx := startIndex
 	curAcc := acc
 	for _, child := range inner.children {
@@ -0,0 +1,156 @@
+package btree
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// NodeDump describes a node of a tree for debugging output, as produced by
+// Dump and DumpVersions.
+//
+// Summaries and extension values are formatted with fmt.Sprint. Items are
+// listed only if a label function is given. Leaves living in a node store which
+// are not resident are not loaded; they are flagged as Lazy and list no items.
+type NodeDump struct {
+	ID       int         `json:"id"`               // unique per node within a dump
+	Height   int         `json:"height"`           // 1 for leaves
+	Weight   int64       `json:"weight"`           // number of items below the node
+	Summary  string      `json:"summary"`          // formatted summary
+	Ext      string      `json:"ext,omitempty"`    // formatted extension value, if configured
+	Lazy     bool        `json:"lazy,omitempty"`   // leaf items live in a node store
+	Shared   bool        `json:"shared,omitempty"` // node has been dumped before, children omitted
+	Items    []string    `json:"items,omitempty"`
+	Children []*NodeDump `json:"children,omitempty"`
+}
+
+// Dump returns a description of the shape of tree. label, if not nil, is used
+// to describe leaf items.
+func Dump[I SummarizedItem[S], S, E any](tree *Tree[I, S, E], label func(I) string) *NodeDump {
+	return DumpVersions(label, tree)[0]
+}
+
+// DumpVersions describes several trees, usually versions of one tree, with
+// common node IDs. A node which is part of more than one tree is described in
+// full on its first occurrence only; later occurrences are flagged as Shared.
+// Empty trees are described as nil.
+func DumpVersions[I SummarizedItem[S], S, E any](label func(I) string, trees ...*Tree[I, S, E]) []*NodeDump {
+	d := dumper[I, S, E]{label: label, ids: make(map[treeNode[I, S, E]]int)}
+	dumps := make([]*NodeDump, len(trees))
+	for i, tree := range trees {
+		if tree.IsEmpty() {
+			continue
+		}
+		d.ext = tree.cfg.Extension != nil
+		dumps[i] = d.node(tree.root, tree.height)
+	}
+	return dumps
+}
+
+// WriteJSON writes the description of tree produced by Dump as indented JSON.
+func WriteJSON[I SummarizedItem[S], S, E any](w io.Writer, tree *Tree[I, S, E], label func(I) string) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(Dump(tree, label))
+}
+
+type dumper[I SummarizedItem[S], S, E any] struct {
+	label func(I) string
+	ext   bool
+	ids   map[treeNode[I, S, E]]int
+}
+
+func (d *dumper[I, S, E]) node(n treeNode[I, S, E], height int) *NodeDump {
+	assert(n != nil, "dump called with nil node")
+	dump := &NodeDump{
+		Height:  height,
+		Weight:  n.Weight(),
+		Summary: fmt.Sprint(n.Summary()),
+	}
+	if d.ext {
+		dump.Ext = fmt.Sprint(n.Ext())
+	}
+	if id, ok := d.ids[n]; ok {
+		dump.ID, dump.Shared = id, true
+		return dump
+	}
+	dump.ID = len(d.ids)
+	d.ids[n] = dump.ID
+	switch node := n.(type) {
+	case *lazyNode[I, S, E]:
+		dump.Lazy = true
+	case *leafNode[I, S, E]:
+		if d.label != nil {
+			dump.Items = make([]string, len(node.items))
+			for i, item := range node.items {
+				dump.Items[i] = d.label(item)
+			}
+		}
+	case *innerNode[I, S, E]:
+		dump.Children = make([]*NodeDump, len(node.children))
+		for i, child := range node.children {
+			dump.Children[i] = d.node(child, height-1)
+		}
+	}
+	return dump
+}
+
+// WriteDot writes the shape of tree in Graphviz DOT format. label, if not nil,
+// is used to describe leaf items.
+//
+//	btree.WriteDot(f, tree, nil) // then: dot -Tsvg -o tree.svg f
+func WriteDot[I SummarizedItem[S], S, E any](w io.Writer, tree *Tree[I, S, E], label func(I) string) error {
+	return WriteDotVersions(w, label, tree)
+}
+
+// WriteDotVersions writes several trees, usually versions of one tree, into a
+// single Graphviz DOT graph. Nodes shared between the trees are drawn once, with
+// edges from every parent, which makes structural sharing visible.
+func WriteDotVersions[I SummarizedItem[S], S, E any](w io.Writer, label func(I) string, trees ...*Tree[I, S, E]) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintln(bw, "digraph btree {")
+	fmt.Fprintln(bw, "  node [shape=record, fontname=\"monospace\", fontsize=10];")
+	for v, dump := range DumpVersions(label, trees...) {
+		fmt.Fprintf(bw, "  v%d [shape=plaintext, label=\"version %d\"];\n", v, v)
+		if dump != nil {
+			fmt.Fprintf(bw, "  v%d -> n%d;\n", v, dump.ID)
+			writeDotNode(bw, dump)
+		}
+	}
+	fmt.Fprintln(bw, "}")
+	return bw.Flush()
+}
+
+func writeDotNode(w io.Writer, dump *NodeDump) {
+	if dump.Shared {
+		return
+	}
+	fields := []string{fmt.Sprintf("#%d w=%d", dump.ID, dump.Weight), dump.Summary}
+	if dump.Ext != "" {
+		fields = append(fields, dump.Ext)
+	}
+	if dump.Lazy {
+		fields = append(fields, "(not resident)")
+	}
+	fields = append(fields, dump.Items...)
+	for i, f := range fields {
+		fields[i] = dotEscaper.Replace(f)
+	}
+	style := ""
+	if dump.Height == 1 {
+		style = ", style=filled, fillcolor=\"#eeeeee\""
+	}
+	fmt.Fprintf(w, "  n%d [label=\"{%s}\"%s];\n", dump.ID, strings.Join(fields, "|"), style)
+	for _, child := range dump.Children {
+		fmt.Fprintf(w, "  n%d -> n%d;\n", dump.ID, child.ID)
+		writeDotNode(w, child)
+	}
+}
+
+// dotEscaper escapes characters with a special meaning in DOT record labels.
+var dotEscaper = strings.NewReplacer(
+	`\`, `\\`, `"`, `\"`, `{`, `\{`, `}`, `\}`, `|`, `\|`, `<`, `\<`, `>`, `\>`,
+	"\n", `\n`, "\r", `\r`, "\t", `\t`,
+)
@@ -0,0 +1,109 @@
+package btree
+
+import (
+	"bytes"
+	"encoding/json"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestDumpVersionsMarksSharedNodes(t *testing.T) {
+	v1 := buildTextTree(t, 200)
+	v2, err := v1.InsertAt(0, fromString("x"))
+	if err != nil {
+		t.Fatalf("insert failed: %v", err)
+	}
+	dumps := DumpVersions(textChunk.String, v1, v2)
+	var count func(*NodeDump) (nodes, shared int)
+	count = func(d *NodeDump) (nodes, shared int) {
+		if d.Shared {
+			return 0, 1
+		}
+		nodes = 1
+		for _, c := range d.Children {
+			n, s := count(c)
+			nodes, shared = nodes+n, shared+s
+		}
+		return
+	}
+	n1, s1 := count(dumps[0])
+	n2, s2 := count(dumps[1])
+	if s1 != 0 || n1 == 0 {
+		t.Fatalf("first version should be dumped in full, got %d nodes, %d shared", n1, s1)
+	}
+	if s2 == 0 || n2 != v2.Height() {
+		t.Fatalf("second version should only add its copied path: %d new nodes, %d shared", n2, s2)
+	}
+	if dumps[1].Weight != 201 || dumps[1].Children[0].Shared {
+		t.Fatalf("unexpected root dump %+v", dumps[1])
+	}
+}
+
+func TestWriteJSONRoundtrip(t *testing.T) {
+	tree := buildTextTree(t, 50)
+	var buf bytes.Buffer
+	if err := WriteJSON(&buf, tree, textChunk.String); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+	var dump NodeDump
+	if err := json.Unmarshal(buf.Bytes(), &dump); err != nil {
+		t.Fatalf("cannot decode dump: %v", err)
+	}
+	var items []string
+	var collect func(*NodeDump)
+	collect = func(d *NodeDump) {
+		items = append(items, d.Items...)
+		for _, c := range d.Children {
+			collect(c)
+		}
+	}
+	collect(&dump)
+	if dump.Weight != 50 || len(items) != 50 || items[49] != "49" {
+		t.Fatalf("unexpected dump: weight=%d, %d items", dump.Weight, len(items))
+	}
+}
+
+func TestWriteDotVersions(t *testing.T) {
+	v1 := buildTextTree(t, 100)
+	v2, err := v1.DeleteAt(99)
+	if err != nil {
+		t.Fatalf("delete failed: %v", err)
+	}
+	empty, _ := New(Config[textChunk, textSummary, NO_EXT]{Monoid: textMonoid{}})
+	var buf bytes.Buffer
+	if err := WriteDotVersions(&buf, func(c textChunk) string { return "<" + c.String() + ">" }, v1, v2, empty); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+	out := buf.String()
+	if !strings.HasPrefix(out, "digraph btree {") || !strings.HasSuffix(out, "}\n") {
+		t.Fatalf("not a DOT graph:\n%s", out)
+	}
+	if !strings.Contains(out, `\<42\>`) {
+		t.Errorf("expected escaped item labels in output")
+	}
+	defs := regexp.MustCompile(`(?m)^  (n\d+) \[`).FindAllStringSubmatch(out, -1)
+	seen := make(map[string]bool)
+	for _, d := range defs {
+		if seen[d[1]] {
+			t.Fatalf("node %s defined twice", d[1])
+		}
+		seen[d[1]] = true
+	}
+	incoming := make(map[string]int)
+	for _, e := range regexp.MustCompile(`-> (n\d+);`).FindAllStringSubmatch(out, -1) {
+		incoming[e[1]]++
+	}
+	shared := 0
+	for _, c := range incoming {
+		if c > 1 {
+			shared++
+		}
+	}
+	if shared == 0 {
+		t.Errorf("expected nodes shared between versions to have several parents")
+	}
+	if !strings.Contains(out, `v2 [shape=plaintext, label="version 2"];`) {
+		t.Errorf("expected a label for the empty version")
+	}
+}
@@ -14,7 +14,8 @@ Current status:
   - optional extension summaries (`E`) via `SumExtension[I,S,E]`,
   - extension-aware node aggregation and recomputation,
   - distinct `leafNode` and `innerNode` representations,
-  - fixed-array node storage with dynamic views (`items`/`children`) over inline buffers,
+  - fixed-capacity node storage with dynamic views (`items`/`children`) over backing buffers,
+  - per-tree branching factor (`Config.Base`),
   - tree API surface and summary-guided (`Cursor`) / extension-guided (`ExtCursor`) seek,
   - in-order iteration (`ForEachItem`) and ranged iteration (`ItemRange`),
   - prefix aggregation for summaries (`PrefixSummary`) and extensions (`PrefixExt`),
@@ -22,7 +23,13 @@ Current status:
   - path-copy split with subtree sharing,
   - structural, height-aware concat/join with path-copy updates,
   - public editing operations: `InsertAt`, `DeleteAt`, `DeleteRange`, `SplitAt`, `Concat`,
+  - bottom-up bulk loading (`FromItems`, `FromItemsParallel`),
+  - transient batch editing at a moving position (`Finger`, `Freeze`),
+  - parallel map-reduce over disjoint subtrees (`ParallelReduce`),
+  - lazily loaded leaves from a `NodeStore` (`FromStore`), with checksummed
+    file persistence (`Save`, `Open`),
   - extension compatibility checks for cross-tree concat (`MagicID`),
+  - structured invariant reports (`Validate`) and model-based fuzz targets,
   - ongoing hardening and cleanup while preparing backend integration.
 
 Extension model:
@@ -18,4 +18,7 @@ var (
 	// ErrExtensionUnavailable signals that an extension-specific API was used
 	// without an extension configured for the tree.
 	ErrExtensionUnavailable = errors.New("btree: extension unavailable")
+	// ErrNodeStore signals that the node store of a lazily loaded tree failed to
+	// provide a leaf.
+	ErrNodeStore = errors.New("btree: node store failure")
 )
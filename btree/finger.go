@@ -0,0 +1,195 @@
+package btree
+
+import "fmt"
+
+// Finger is a transient editing position within a tree, for sequences of
+// local edits like typing in an editor.
+//
+// A finger holds a private working copy of the path from the root to the leaf
+// it currently points into. Nodes on that path are copied once when the
+// finger moves there and are updated in place afterwards, and aggregates of
+// internal nodes are refreshed only when the finger moves away or is frozen.
+// Edits within one leaf therefore avoid the per-edit path copy of the
+// persistent API. Edits which split or merge leaves fall back to persistent
+// path-copy operations.
+//
+// The tree a finger has been created from is never modified; Freeze returns
+// the edited state as a persistent tree. A Finger is not safe for concurrent
+// use.
+type Finger[I SummarizedItem[S], S, E any] struct {
+	tree  *Tree[I, S, E]             // private working tree
+	owned map[treeNode[I, S, E]]bool // nodes created by this finger, mutable in place
+	path  []fingerStep[I, S, E]      // owned internal nodes from root to leaf
+	leaf  *leafNode[I, S, E]         // owned focus leaf, or nil
+	start int64                      // item index of the first item in leaf
+	size  int64                      // item count, exact even if aggregates are stale
+	dirty bool                       // aggregates along path are stale
+}
+
+// fingerStep is an internal node on the finger's path together with the slot
+// of the child the path continues with.
+type fingerStep[I SummarizedItem[S], S, E any] struct {
+	node *innerNode[I, S, E]
+	slot int
+}
+
+// NewFinger creates a finger for batch edits on tree.
+func NewFinger[I SummarizedItem[S], S, E any](tree *Tree[I, S, E]) (*Finger[I, S, E], error) {
+	if tree == nil {
+		return nil, fmt.Errorf("%w: nil tree", ErrInvalidConfig)
+	}
+	return &Finger[I, S, E]{
+		tree:  tree.Clone(),
+		owned: make(map[treeNode[I, S, E]]bool),
+		size:  tree.Len(),
+	}, nil
+}
+
+// Len returns the number of items in the edited tree.
+func (f *Finger[I, S, E]) Len() int64 {
+	return f.size
+}
+
+// InsertAt inserts items at an item index of the edited tree.
+func (f *Finger[I, S, E]) InsertAt(index int64, items ...I) (err error) {
+	defer catchLoadFailure(&err)
+	if index < 0 || index > f.size {
+		return ErrIndexOutOfBounds
+	}
+	for i, item := range items {
+		if err = f.insertOne(index+int64(i), item); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DeleteAt removes the item at index from the edited tree.
+func (f *Finger[I, S, E]) DeleteAt(index int64) (err error) {
+	defer catchLoadFailure(&err)
+	if index < 0 || index >= f.size {
+		return ErrIndexOutOfBounds
+	}
+	f.focus(index, false)
+	leaf := f.leaf
+	if len(leaf.items) > f.tree.base() || (len(f.path) == 0 && len(leaf.items) > 1) {
+		local := int(index - f.start)
+		n := len(leaf.items)
+		copy(leaf.itemStore[local:n-1], leaf.itemStore[local+1:n])
+		var zero I
+		leaf.itemStore[n-1] = zero
+		leaf.n = uint8(n - 1)
+		leaf.items = leaf.itemStore[:n-1]
+		f.tree.recomputeLeafSummary(leaf)
+		f.dirty = true
+		f.size--
+		return nil
+	}
+	f.release()
+	needsRebalance, err := f.tree.deleteOneAt(index)
+	if err != nil {
+		return err
+	}
+	if needsRebalance {
+		return fmt.Errorf("%w: delete rebalance could not be resolved", ErrUnimplemented)
+	}
+	f.size--
+	return nil
+}
+
+// Freeze returns the edited state as a persistent tree.
+//
+// The finger stays usable. Its nodes become part of the returned tree and are
+// not modified by subsequent edits, which start copying afresh.
+func (f *Finger[I, S, E]) Freeze() *Tree[I, S, E] {
+	f.release()
+	return f.tree.Clone()
+}
+
+func (f *Finger[I, S, E]) insertOne(index int64, item I) error {
+	f.focus(index, true)
+	leaf := f.leaf
+	if leaf != nil && len(leaf.items) < f.tree.maxItems() {
+		local := int(index - f.start)
+		n := len(leaf.items)
+		copy(leaf.itemStore[local+1:n+1], leaf.itemStore[local:n])
+		leaf.itemStore[local] = item
+		leaf.n = uint8(n + 1)
+		leaf.items = leaf.itemStore[:n+1]
+		f.tree.recomputeLeafSummary(leaf)
+		f.dirty = true
+		f.size++
+		return nil
+	}
+	f.release()
+	if err := f.tree.insertOneAt(index, item); err != nil {
+		return err
+	}
+	f.size++
+	return nil
+}
+
+// focus moves the finger to the leaf holding index. For insertions, an index
+// at the end of the current leaf does not move the finger.
+func (f *Finger[I, S, E]) focus(index int64, insert bool) {
+	if f.leaf != nil {
+		end := f.start + int64(len(f.leaf.items))
+		if index >= f.start && (index < end || insert && index == end) {
+			return
+		}
+	}
+	f.unfocus()
+	t := f.tree
+	if t.root == nil {
+		return
+	}
+	t.root = f.own(t.root)
+	n, start := t.root, int64(0)
+	for height := t.height; height > 1; height-- {
+		inner := mustInner[I, S, E](n)
+		var slot int
+		var local int64
+		var err error
+		if insert {
+			slot, local, err = t.locateChildForInsert(inner, index-start)
+		} else {
+			slot, local, err = t.locateChildForDelete(inner, index-start)
+		}
+		assert(err == nil, "finger index out of bounds")
+		start = index - local
+		inner.children[slot] = f.own(inner.children[slot])
+		f.path = append(f.path, fingerStep[I, S, E]{node: inner, slot: slot})
+		n = inner.children[slot]
+	}
+	f.leaf, f.start = n.(*leafNode[I, S, E]), start
+}
+
+// unfocus refreshes aggregates along the path and releases the focus leaf.
+func (f *Finger[I, S, E]) unfocus() {
+	if f.dirty {
+		for i := len(f.path) - 1; i >= 0; i-- {
+			f.tree.recomputeInnerSummary(f.path[i].node)
+		}
+		f.dirty = false
+	}
+	f.path = f.path[:0]
+	f.leaf = nil
+}
+
+// release unfocuses the finger and gives up ownership of all nodes. It is
+// called before nodes are handed out or replaced by path-copying operations.
+func (f *Finger[I, S, E]) release() {
+	f.unfocus()
+	clear(f.owned)
+}
+
+// own returns a node which may be modified in place in lieu of n, copying n if
+// it has not been created by this finger.
+func (f *Finger[I, S, E]) own(n treeNode[I, S, E]) treeNode[I, S, E] {
+	if f.owned[n] {
+		return n
+	}
+	cloned := f.tree.cloneNode(n)
+	f.owned[cloned] = true
+	return cloned
+}
@@ -0,0 +1,104 @@
+package btree
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestFingerTypingMatchesPersistentEdits(t *testing.T) {
+	for _, base := range []int{MinBase, Base} {
+		cfg := Config[textChunk, textSummary, uint64]{Monoid: textMonoid{}, Extension: countingExt{id: "bytes"}, Base: base}
+		orig, err := FromItems(cfg, textItems(100))
+		if err != nil {
+			t.Fatalf("bulk load failed: %v", err)
+		}
+		want := collectTextItems(orig)
+		finger, err := NewFinger(orig)
+		if err != nil {
+			t.Fatalf("NewFinger failed: %v", err)
+		}
+		// type a word at two positions, with a typo corrected by backspace
+		pos := int64(40)
+		for i, c := range "helo\blo" {
+			if c == '\b' {
+				pos--
+				if err := finger.DeleteAt(pos); err != nil {
+					t.Fatalf("delete failed: %v", err)
+				}
+				want = append(want[:pos], want[pos+1:]...)
+				continue
+			}
+			if err := finger.InsertAt(pos, fromString(string(c))); err != nil {
+				t.Fatalf("insert %d failed: %v", i, err)
+			}
+			want = append(want[:pos], append([]string{string(c)}, want[pos:]...)...)
+			pos++
+		}
+		for i := range 50 {
+			at := int64(90 + i)
+			if err := finger.InsertAt(at, fromString("t"+strconv.Itoa(i))); err != nil {
+				t.Fatalf("insert failed: %v", err)
+			}
+			want = append(want[:at], append([]string{"t" + strconv.Itoa(i)}, want[at:]...)...)
+		}
+		for range 30 {
+			if err := finger.DeleteAt(10); err != nil {
+				t.Fatalf("delete failed: %v", err)
+			}
+			want = append(want[:10], want[11:]...)
+		}
+		if finger.Len() != int64(len(want)) {
+			t.Fatalf("finger length %d, want %d", finger.Len(), len(want))
+		}
+		tree := finger.Freeze()
+		if err := tree.Check(); err != nil {
+			t.Fatalf("frozen tree invalid: %v", err)
+		}
+		if got := collectTextItems(tree); strings.Join(got, ",") != strings.Join(want, ",") {
+			t.Fatalf("frozen tree differs from model:\n%v\n%v", got, want)
+		}
+		assertByteExtensionAccounting(t, tree)
+		if got := collectTextItems(orig); len(got) != 100 || got[40] != "40" {
+			t.Fatalf("original tree changed by finger edits")
+		}
+		// edits after Freeze must not leak into the frozen tree
+		if err := finger.InsertAt(0, fromString("after")); err != nil {
+			t.Fatalf("insert after freeze failed: %v", err)
+		}
+		if err := finger.DeleteAt(finger.Len() - 1); err != nil {
+			t.Fatalf("delete after freeze failed: %v", err)
+		}
+		if got := collectTextItems(tree); strings.Join(got, ",") != strings.Join(want, ",") {
+			t.Fatalf("frozen tree changed by later finger edits")
+		}
+		if err := finger.Freeze().Check(); err != nil {
+			t.Fatalf("second frozen tree invalid: %v", err)
+		}
+	}
+}
+
+func TestFingerOnEmptyTree(t *testing.T) {
+	finger, err := NewFinger(makeTextTree(t))
+	if err != nil {
+		t.Fatalf("NewFinger failed: %v", err)
+	}
+	if err := finger.DeleteAt(0); !errors.Is(err, ErrIndexOutOfBounds) {
+		t.Fatalf("expected ErrIndexOutOfBounds, got %v", err)
+	}
+	if err := finger.InsertAt(0, chunks("a", "b", "c")...); err != nil {
+		t.Fatalf("insert failed: %v", err)
+	}
+	for range 3 {
+		if err := finger.DeleteAt(0); err != nil {
+			t.Fatalf("delete failed: %v", err)
+		}
+	}
+	if tree := finger.Freeze(); !tree.IsEmpty() || tree.Check() != nil {
+		t.Fatalf("expected empty valid tree")
+	}
+	if _, err := NewFinger[textChunk, textSummary, NO_EXT](nil); err == nil {
+		t.Fatalf("expected error for nil tree")
+	}
+}
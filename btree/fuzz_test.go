@@ -0,0 +1,167 @@
+package btree
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/npillmayer/cords/chunk"
+)
+
+// treeVersion is a tree together with the items it is expected to hold.
+type treeVersion[I SummarizedItem[S], S any] struct {
+	tree  *Tree[I, S, NO_EXT]
+	model []I
+}
+
+// runModelOps interprets ops as a sequence of InsertAt, DeleteRange, SplitAt
+// and Concat operations, three bytes per operation. Every operation is applied
+// to the latest tree version and to a slice model. After every step the new
+// version has to pass Validate and match its model, and the version operated
+// on has to remain unchanged. Finally, all versions are checked once more.
+func runModelOps[I SummarizedItem[S], S any](t *testing.T, cfg Config[I, S, NO_EXT], ops []byte,
+	mkItem func(byte) I, equal func(I, I) bool) {
+	//
+	const maxItems = 1000
+	tree, err := New(cfg)
+	if err != nil {
+		t.Fatalf("new tree failed: %v", err)
+	}
+	versions := []treeVersion[I, S]{{tree: tree}}
+	for step := 0; step+2 < len(ops); step += 3 {
+		cur := versions[len(versions)-1]
+		n := int64(len(cur.model))
+		a, b := int64(ops[step+1]), int64(ops[step+2])
+		var next treeVersion[I, S]
+		var desc string
+		switch ops[step] % 4 {
+		case 0:
+			pos, count := a%(n+1), b%8+1
+			items := make([]I, count)
+			for i := range items {
+				items[i] = mkItem(byte(b) + byte(i))
+			}
+			desc = fmt.Sprintf("InsertAt(%d, %d items)", pos, count)
+			next.tree, err = cur.tree.InsertAt(pos, items...)
+			next.model = concatModel(cur.model[:pos], items, cur.model[pos:])
+		case 1:
+			if n == 0 {
+				continue
+			}
+			pos := a % n
+			count := b%(n-pos) + 1
+			desc = fmt.Sprintf("DeleteRange(%d, %d)", pos, count)
+			next.tree, err = cur.tree.DeleteRange(pos, count)
+			next.model = concatModel(cur.model[:pos], cur.model[pos+count:])
+		case 2:
+			// split and swap halves, which exercises SplitAt and Concat on
+			// trees of unrelated heights
+			pos := a % (n + 1)
+			desc = fmt.Sprintf("SplitAt(%d)", pos)
+			var left, right *Tree[I, S, NO_EXT]
+			if left, right, err = cur.tree.SplitAt(pos); err == nil {
+				checkVersion(t, desc+" left", treeVersion[I, S]{left, cur.model[:pos]}, equal)
+				checkVersion(t, desc+" right", treeVersion[I, S]{right, cur.model[pos:]}, equal)
+				next.tree, err = right.Concat(left)
+			}
+			next.model = concatModel(cur.model[pos:], cur.model[:pos])
+		case 3:
+			if 2*n > maxItems {
+				continue
+			}
+			// concat a tree with itself or with a bulk-loaded tree
+			desc = "Concat(self)"
+			other := cur
+			if b%2 == 1 {
+				other.model = make([]I, a%64)
+				for i := range other.model {
+					other.model[i] = mkItem(byte(i))
+				}
+				other.tree, err = FromItems(cfg, other.model)
+				if err != nil {
+					t.Fatalf("FromItems failed: %v", err)
+				}
+				desc = fmt.Sprintf("Concat(%d items)", len(other.model))
+			}
+			next.tree, err = cur.tree.Concat(other.tree)
+			next.model = concatModel(cur.model, other.model)
+		}
+		if err != nil {
+			t.Fatalf("step %d: %s failed: %v", step/3, desc, err)
+		}
+		checkVersion(t, fmt.Sprintf("step %d: %s", step/3, desc), next, equal)
+		checkVersion(t, fmt.Sprintf("step %d: %s: input", step/3, desc), cur, equal)
+		if len(next.model) <= maxItems {
+			versions = append(versions, next)
+		}
+	}
+	for i, v := range versions {
+		checkVersion(t, fmt.Sprintf("version %d", i), v, equal)
+	}
+}
+
+func concatModel[I any](parts ...[]I) []I {
+	var model []I
+	for _, p := range parts {
+		model = append(model, p...)
+	}
+	return model
+}
+
+// checkVersion validates v.tree and compares it against v.model, including the
+// total summary.
+func checkVersion[I SummarizedItem[S], S any](t *testing.T, desc string, v treeVersion[I, S], equal func(I, I) bool) {
+	t.Helper()
+	report, err := v.tree.Validate()
+	if err != nil {
+		t.Fatalf("%s: validate failed: %v", desc, err)
+	}
+	if !report.OK() {
+		t.Fatalf("%s: %s", desc, report)
+	}
+	if v.tree.Len() != int64(len(v.model)) {
+		t.Fatalf("%s: tree has %d items, model %d", desc, v.tree.Len(), len(v.model))
+	}
+	i := 0
+	v.tree.ForEachItem(func(item I) bool {
+		if !equal(item, v.model[i]) {
+			t.Fatalf("%s: item %d differs from model", desc, i)
+		}
+		i++
+		return true
+	})
+	monoid := v.tree.Config().Monoid
+	sum := monoid.Zero()
+	for _, item := range v.model {
+		sum = monoid.Add(sum, item.Summary())
+	}
+	if fmt.Sprint(sum) != fmt.Sprint(v.tree.Summary()) {
+		t.Fatalf("%s: summary %v differs from model %v", desc, v.tree.Summary(), sum)
+	}
+}
+
+// chunkConfig configures trees of text chunks, as used by the cords backend.
+func chunkConfig(base int) Config[chunk.Chunk, chunk.Summary, NO_EXT] {
+	return Config[chunk.Chunk, chunk.Summary, NO_EXT]{Monoid: chunk.Monoid{}, Base: base}
+}
+
+func mkChunk(b byte) chunk.Chunk {
+	text := []byte("abcdefgh\näöü")
+	c, err := chunk.NewBytes(text[:int(b)%len("abcdefgh\n")+1])
+	if err != nil {
+		panic(err)
+	}
+	return c
+}
+
+func FuzzChunkTreeOps(f *testing.F) {
+	f.Add(uint8(0), []byte{0, 0, 7, 0, 1, 7, 3, 0, 0, 2, 5, 0, 1, 2, 3})
+	f.Add(uint8(4), []byte{3, 60, 1, 3, 0, 0, 2, 30, 0, 1, 10, 40, 2, 99, 0})
+	f.Add(uint8(1), []byte{0, 0, 255, 3, 0, 0, 3, 0, 0, 3, 0, 0, 2, 17, 0, 1, 5, 3, 2, 200, 0})
+	f.Fuzz(func(t *testing.T, base uint8, ops []byte) {
+		if len(ops) > 3*64 {
+			ops = ops[:3*64]
+		}
+		cfg := chunkConfig(MinBase + int(base)%7)
+		runModelOps(t, cfg, ops, mkChunk, func(a, b chunk.Chunk) bool { return a == b })
+	})
+}
@@ -1,150 +1,274 @@
 package btree
 
-import "fmt"
+import (
+	"fmt"
+	"strings"
+)
 
-// Check validates structural tree invariants.
+// Violation describes a single broken tree invariant.
+type Violation struct {
+	// Path lists child indices from the root down to the offending node; it is
+	// empty for the root node and for tree-level violations.
+	Path []int
+	// Level is the level of the offending node within the tree, with leaves at
+	// level 1 and the root at the tree's height.
+	Level int
+	// Invariant names the broken invariant, e.g. "summary mismatch".
+	Invariant string
+	// Expected and Actual describe the required and the observed state.
+	Expected, Actual string
+}
+
+func (v Violation) String() string {
+	return fmt.Sprintf("%s at node %v (level %d): expected %s, have %s",
+		v.Invariant, v.Path, v.Level, v.Expected, v.Actual)
+}
+
+// CheckReport collects all invariant violations found by Validate.
+type CheckReport struct {
+	Violations []Violation
+	Nodes      int // number of nodes visited
+}
+
+// OK is true if no violations were found.
+func (r *CheckReport) OK() bool {
+	return r == nil || len(r.Violations) == 0
+}
+
+// Err returns nil for a report without violations. Otherwise it returns an
+// error wrapping ErrInvalidConfig, describing the first violation.
+func (r *CheckReport) Err() error {
+	if r.OK() {
+		return nil
+	}
+	if len(r.Violations) == 1 {
+		return fmt.Errorf("%w: %s", ErrInvalidConfig, r.Violations[0])
+	}
+	return fmt.Errorf("%w: %s (and %d more violations)", ErrInvalidConfig,
+		r.Violations[0], len(r.Violations)-1)
+}
+
+func (r *CheckReport) String() string {
+	if r.OK() {
+		return "tree ok"
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d violations in %d nodes:", len(r.Violations), r.Nodes)
+	for _, v := range r.Violations {
+		b.WriteString("\n  ")
+		b.WriteString(v.String())
+	}
+	return b.String()
+}
+
+func (r *CheckReport) add(path []int, level int, invariant string, expected, actual any) {
+	r.Violations = append(r.Violations, Violation{
+		Path:      append([]int(nil), path...),
+		Level:     level,
+		Invariant: invariant,
+		Expected:  fmt.Sprint(expected),
+		Actual:    fmt.Sprint(actual),
+	})
+}
+
+// Check validates structural tree invariants and reports the first violation
+// as an error. Use Validate for a complete report.
 //
 // This checker is intentionally strict and should be used in tests while the
 // implementation is evolving.
 func (t *Tree[I, S, E]) Check() error {
+	report, err := t.Validate()
+	if err != nil {
+		return err
+	}
+	return report.Err()
+}
+
+// Validate checks all structural tree invariants and reports every violation
+// found: node occupancy, uniform height, item counts, fixed-storage backing of
+// nodes, and consistency of cached summaries, extension values and weights
+// with their recomputation from items.
+//
+// Summaries and extension values are compared by their default formatting
+// (%v), so S and E need not be comparable. The error result is only set if
+// leaves of a lazily loaded tree cannot be loaded.
+func (t *Tree[I, S, E]) Validate() (report *CheckReport, err error) {
+	defer catchLoadFailure(&err)
+	report = &CheckReport{}
 	if t == nil {
-		return fmt.Errorf("%w: nil tree", ErrInvalidConfig)
+		report.add(nil, 0, "nil tree", "tree", "nil")
+		return report, nil
 	}
 	if t.root == nil {
 		if t.height != 0 {
-			return fmt.Errorf("%w: empty tree must have height=0", ErrInvalidConfig)
+			report.add(nil, t.height, "empty tree height", 0, t.height)
 		}
-		return nil
+		return report, nil
 	}
 	if t.height <= 0 {
-		return fmt.Errorf("%w: non-empty tree must have height > 0", ErrInvalidConfig)
-	}
-	w, height, err := t.checkNode(t.root, true)
-	if err != nil {
-		return err
+		report.add(nil, t.height, "non-empty tree height", "height > 0", t.height)
 	}
+	c := checker[I, S, E]{tree: t, report: report}
+	items, height := c.checkNode(t.root, t.height, true)
 	if height != t.height {
-		return fmt.Errorf("%w: height mismatch (%d != %d)", ErrInvalidConfig, height, t.height)
+		report.add(nil, height, "height mismatch", t.height, height)
 	}
-	if t.root.Weight() <= 0 {
-		return fmt.Errorf("%w: weight in top node=%d)", ErrInvalidConfig, t.root.Weight())
+	if w := t.root.Weight(); w != int64(items) {
+		report.add(nil, height, "weight mismatch", items, w)
 	}
-	if w != int(t.root.Weight()) {
-		return fmt.Errorf("%w: weight mismatch (%d != %d)", ErrInvalidConfig, w, t.root.Weight())
-	}
-	return nil
+	return report, nil
+}
+
+// checker walks a tree and records violations, keeping track of the path from
+// the root to the current node.
+type checker[I SummarizedItem[S], S, E any] struct {
+	tree   *Tree[I, S, E]
+	report *CheckReport
+	path   []int
 }
 
-// checkNode validates one subtree and returns:
+func (c *checker[I, S, E]) violation(level int, invariant string, expected, actual any) {
+	c.report.add(c.path, level, invariant, expected, actual)
+}
+
+// checkNode validates one subtree, located at level of the tree, and returns:
 //   - total item count under that subtree
 //   - subtree height
 //
-// The function enforces occupancy rules and uniform child heights.
-func (t *Tree[I, S, E]) checkNode(n treeNode[I, S, E], isRoot bool) (items int, height int, err error) {
-	if n == nil {
-		return 0, 0, fmt.Errorf("%w: nil node", ErrInvalidConfig)
-	}
+// The function enforces occupancy rules and uniform child heights. Violations
+// are reported with the level the node should have according to the tree's
+// height.
+func (c *checker[I, S, E]) checkNode(n treeNode[I, S, E], level int, isRoot bool) (items int, height int) {
+	c.report.Nodes++
+	t := c.tree
 	if n.isLeaf() {
-		leaf := n.(*leafNode[I, S, E])
-		if leaf == nil {
-			return 0, 0, fmt.Errorf("%w: nil leaf node", ErrInvalidConfig)
-		}
-		if err := t.checkLeafInvariants(leaf); err != nil {
-			return 0, 0, err
+		leaf := mustLeaf[I, S, E](n)
+		c.checkLeafInvariants(leaf, level)
+		count := len(leaf.items)
+		switch {
+		case isRoot && count == 0:
+			c.violation(level, "root leaf must not be empty", "items > 0", count)
+		case !isRoot && count < t.base():
+			c.violation(level, "leaf underflow", fmt.Sprintf(">= %d items", t.base()), count)
+		case !isRoot && count > t.maxItems():
+			c.violation(level, "leaf overflow", fmt.Sprintf("<= %d items", t.maxItems()), count)
 		}
-		if isRoot {
-			if len(leaf.items) == 0 {
-				return 0, 0, fmt.Errorf("%w: root leaf must not be empty", ErrInvalidConfig)
+		sum, ext := t.cfg.Monoid.Zero(), c.zeroExt()
+		for _, item := range leaf.items {
+			s := item.Summary()
+			sum = t.cfg.Monoid.Add(sum, s)
+			if t.cfg.Extension != nil {
+				ext = t.cfg.Extension.Add(ext, t.cfg.Extension.FromItem(item, s))
 			}
-		} else {
-			if len(leaf.items) < Base {
-				return 0, 0, fmt.Errorf("%w: leaf underflow (%d < %d)",
-					ErrInvalidConfig, len(leaf.items), Base)
-			}
-			if len(leaf.items) > MaxLeafItems {
-				return 0, 0, fmt.Errorf("%w: leaf overflow (%d > %d)",
-					ErrInvalidConfig, len(leaf.items), MaxLeafItems)
-			}
-		}
-		return len(leaf.items), 1, nil
-	}
-	inner := n.(*innerNode[I, S, E])
-	if err := t.checkInnerInvariants(inner); err != nil {
-		return 0, 0, err
-	}
-	if len(inner.children) == 0 {
-		return 0, 0, fmt.Errorf("%w: internal node has no children", ErrInvalidConfig)
-	}
-	if isRoot {
-		if len(inner.children) == 1 {
-			return 0, 0, fmt.Errorf("%w: root has a single child and should be collapsed", ErrInvalidConfig)
-		}
-	} else {
-		if len(inner.children) < Base {
-			return 0, 0, fmt.Errorf("%w: child count %d under min fill %d",
-				ErrInvalidConfig, len(inner.children), Base)
 		}
-		if len(inner.children) > MaxChildren {
-			return 0, 0, fmt.Errorf("%w: child count %d exceeds degree %d",
-				ErrInvalidConfig, len(inner.children), MaxChildren)
+		c.checkAggregates(n, level, sum, ext)
+		if n.Weight() != int64(count) {
+			c.violation(level, "weight mismatch", count, n.Weight())
 		}
+		return count, 1
 	}
-	var totalItems int
-	var childHeight int
+	inner := mustInner[I, S, E](n)
+	c.checkInnerInvariants(inner, level)
+	count := len(inner.children)
+	switch {
+	case count == 0:
+		c.violation(level, "internal node has no children", "children > 0", 0)
+		return 0, 0
+	case isRoot && count == 1:
+		c.violation(level, "root has a single child and should be collapsed", "children > 1", 1)
+	case !isRoot && count < t.base():
+		c.violation(level, "child count under min fill", fmt.Sprintf(">= %d children", t.base()), count)
+	case !isRoot && count > t.maxItems():
+		c.violation(level, "child count exceeds degree", fmt.Sprintf("<= %d children", t.maxItems()), count)
+	}
+	var totalItems, childHeight int
+	sum, ext := t.cfg.Monoid.Zero(), c.zeroExt()
+	var weight int64
 	for i, child := range inner.children {
 		if child == nil {
-			return 0, 0, fmt.Errorf("%w: nil child at index %d", ErrInvalidConfig, i)
-		}
-		cItems, cHeight, cErr := t.checkNode(child, false)
-		if cErr != nil {
-			return 0, 0, cErr
+			c.violation(level, "nil child", "node", fmt.Sprintf("nil at index %d", i))
+			continue
 		}
+		c.path = append(c.path, i)
+		cItems, cHeight := c.checkNode(child, level-1, false)
+		c.path = c.path[:len(c.path)-1]
 		totalItems += cItems
 		if i == 0 {
 			childHeight = cHeight
 		} else if cHeight != childHeight {
-			return 0, 0, fmt.Errorf("%w: non-uniform subtree heights", ErrInvalidConfig)
+			c.violation(level, "non-uniform subtree heights",
+				fmt.Sprintf("height %d for child %d", childHeight, i), cHeight)
 		}
+		sum = t.cfg.Monoid.Add(sum, child.Summary())
+		if t.cfg.Extension != nil {
+			ext = t.cfg.Extension.Add(ext, child.Ext())
+		}
+		weight += child.Weight()
+	}
+	c.checkAggregates(n, level, sum, ext)
+	if n.Weight() != weight {
+		c.violation(level, "weight mismatch", weight, n.Weight())
+	}
+	return totalItems, childHeight + 1
+}
+
+func (c *checker[I, S, E]) zeroExt() (ext E) {
+	if c.tree.cfg.Extension != nil {
+		ext = c.tree.cfg.Extension.Zero()
 	}
-	return totalItems, childHeight + 1, nil
+	return ext
 }
 
-// checkLeafInvariants verifies fixed-array backing/view consistency for a leaf.
-func (t *Tree[I, S, E]) checkLeafInvariants(leaf *leafNode[I, S, E]) error {
-	if leaf == nil {
-		return fmt.Errorf("%w: nil leaf node", ErrInvalidConfig)
+// checkAggregates compares the cached summary and extension value of n with
+// their recomputation.
+func (c *checker[I, S, E]) checkAggregates(n treeNode[I, S, E], level int, sum S, ext E) {
+	if want, have := fmt.Sprint(sum), fmt.Sprint(n.Summary()); want != have {
+		c.violation(level, "summary mismatch", want, have)
 	}
+	if c.tree.cfg.Extension == nil {
+		return
+	}
+	if want, have := fmt.Sprint(ext), fmt.Sprint(n.Ext()); want != have {
+		c.violation(level, "extension mismatch", want, have)
+	}
+}
+
+// checkLeafInvariants verifies fixed-capacity backing/view consistency for a leaf.
+func (c *checker[I, S, E]) checkLeafInvariants(leaf *leafNode[I, S, E], level int) {
+	size := c.tree.storageSize()
 	if int(leaf.n) != len(leaf.items) {
-		return fmt.Errorf("%w: leaf occupancy mismatch (%d != %d)", ErrInvalidConfig, leaf.n, len(leaf.items))
+		c.violation(level, "leaf occupancy mismatch", leaf.n, len(leaf.items))
+	}
+	if len(leaf.itemStore) != size {
+		c.violation(level, "leaf storage size mismatch", size, len(leaf.itemStore))
 	}
 	if len(leaf.items) > len(leaf.itemStore) {
-		return fmt.Errorf("%w: leaf len exceeds storage (%d > %d)", ErrInvalidConfig, len(leaf.items), len(leaf.itemStore))
+		c.violation(level, "leaf len exceeds storage", len(leaf.itemStore), len(leaf.items))
 	}
 	if cap(leaf.items) != len(leaf.itemStore) {
-		return fmt.Errorf("%w: leaf view cap mismatch (%d != %d)", ErrInvalidConfig, cap(leaf.items), len(leaf.itemStore))
+		c.violation(level, "leaf view cap mismatch", len(leaf.itemStore), cap(leaf.items))
 	}
-	if len(leaf.items) > 0 && &leaf.items[0] != &leaf.itemStore[0] {
-		return fmt.Errorf("%w: leaf view is not backed by fixed storage", ErrInvalidConfig)
+	if len(leaf.items) > 0 && len(leaf.itemStore) > 0 && &leaf.items[0] != &leaf.itemStore[0] {
+		c.violation(level, "leaf view is not backed by fixed storage", "shared backing", "detached view")
 	}
-	return nil
 }
 
-// checkInnerInvariants verifies fixed-array backing/view consistency for internals.
-func (t *Tree[I, S, E]) checkInnerInvariants(inner *innerNode[I, S, E]) error {
-	if inner == nil {
-		return fmt.Errorf("%w: nil internal node", ErrInvalidConfig)
-	}
+// checkInnerInvariants verifies fixed-capacity backing/view consistency for internals.
+func (c *checker[I, S, E]) checkInnerInvariants(inner *innerNode[I, S, E], level int) {
+	size := c.tree.storageSize()
 	if int(inner.n) != len(inner.children) {
-		return fmt.Errorf("%w: child occupancy mismatch (%d != %d)", ErrInvalidConfig, inner.n, len(inner.children))
+		c.violation(level, "child occupancy mismatch", inner.n, len(inner.children))
+	}
+	if len(inner.childStore) != size {
+		c.violation(level, "child storage size mismatch", size, len(inner.childStore))
 	}
 	if len(inner.children) > len(inner.childStore) {
-		return fmt.Errorf("%w: child len exceeds storage (%d > %d)", ErrInvalidConfig, len(inner.children), len(inner.childStore))
+		c.violation(level, "child len exceeds storage", len(inner.childStore), len(inner.children))
 	}
 	if cap(inner.children) != len(inner.childStore) {
-		return fmt.Errorf("%w: child view cap mismatch (%d != %d)", ErrInvalidConfig, cap(inner.children), len(inner.childStore))
+		c.violation(level, "child view cap mismatch", len(inner.childStore), cap(inner.children))
 	}
-	if len(inner.children) > 0 && &inner.children[0] != &inner.childStore[0] {
-		return fmt.Errorf("%w: child view is not backed by fixed storage", ErrInvalidConfig)
+	if len(inner.children) > 0 && len(inner.childStore) > 0 && &inner.children[0] != &inner.childStore[0] {
+		c.violation(level, "child view is not backed by fixed storage", "shared backing", "detached view")
 	}
-	return nil
 }
@@ -1,6 +1,7 @@
 package btree
 
 import (
+	"errors"
 	"strings"
 	"testing"
 )
@@ -40,3 +41,44 @@ func TestFixedBackendDetectsChildViewDrift(t *testing.T) {
 		t.Fatalf("unexpected error: %v", err)
 	}
 }
+
+func TestValidateReportsAllViolationsWithPaths(t *testing.T) {
+	tree, err := FromItems(Config[textChunk, textSummary, NO_EXT]{Monoid: textMonoid{}}, textItems(100))
+	if err != nil {
+		t.Fatalf("bulk load failed: %v", err)
+	}
+	report, err := tree.Validate()
+	if err != nil || !report.OK() {
+		t.Fatalf("expected valid tree, got %v, %v", report, err)
+	}
+	root := tree.root.(*innerNode[textChunk, textSummary, NO_EXT])
+	leaf := root.children[2].(*leafNode[textChunk, textSummary, NO_EXT])
+	leaf.summary.Lines = 99 // corrupt a cached summary on purpose
+	leaf.n--                // and the logical length
+	report, err = tree.Validate()
+	if err != nil {
+		t.Fatalf("unexpected validation error: %v", err)
+	}
+	if len(report.Violations) < 2 {
+		t.Fatalf("expected several violations, got %s", report)
+	}
+	var found bool
+	for _, v := range report.Violations {
+		// the corruption shows at the leaf and again at its parent
+		if v.Invariant == "summary mismatch" && v.Level == 1 {
+			found = true
+			if len(v.Path) != 1 || v.Path[0] != 2 {
+				t.Fatalf("unexpected location of summary mismatch: %s", v)
+			}
+			if !strings.Contains(v.Actual, "99") {
+				t.Fatalf("expected actual summary to be reported, got %s", v)
+			}
+		}
+	}
+	if !found {
+		t.Fatalf("expected summary mismatch to be reported, got %s", report)
+	}
+	if err := tree.Check(); !errors.Is(err, ErrInvalidConfig) || !strings.Contains(err.Error(), "more violations") {
+		t.Fatalf("expected Check to summarize violations, got %v", err)
+	}
+}
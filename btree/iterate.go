@@ -17,7 +17,7 @@ func (t *Tree[I, S, E]) ForEachItem(fn func(item I) bool) {
 func (t *Tree[I, S, E]) forEachItemNode(n treeNode[I, S, E], fn func(item I) bool) bool {
 	assert(n != nil, "forEachItemNode called with nil node")
 	if n.isLeaf() {
-		leaf := n.(*leafNode[I, S, E])
+		leaf := mustLeaf[I, S, E](n)
 		for _, item := range leaf.items {
 			if !fn(item) {
 				return false
@@ -25,7 +25,7 @@ func (t *Tree[I, S, E]) forEachItemNode(n treeNode[I, S, E], fn func(item I) boo
 		}
 		return true
 	}
-	inner := n.(*innerNode[I, S, E])
+	inner := mustInner[I, S, E](n)
 	for _, child := range inner.children {
 		if !t.forEachItemNode(child, fn) {
 			return false
@@ -60,7 +60,7 @@ func forEachNode[I SummarizedItem[S], S, E, K any](
 	assert(n != nil, "forEachNode called with nil node")
 	if n.isLeaf() {
 		assert(height == 1, "forEachNode called with leaf node at height > 1")
-		leaf := n.(*leafNode[I, S, E])
+		leaf := mustLeaf[I, S, E](n)
 		k := m.Zero()
 		for _, item := range leaf.items {
 			l, ok := m.Leaf(item)
@@ -75,7 +75,7 @@ func forEachNode[I SummarizedItem[S], S, E, K any](
 		}
 		return k, false
 	}
-	inner := n.(*innerNode[I, S, E])
+	inner := mustInner[I, S, E](n)
 	k := m.Zero()
 	for _, child := range inner.children {
 		n, ok := forEachNode(child, m, height-1)
@@ -107,6 +107,7 @@ type where[I any] struct {
 	acc      int64               // item count to the left of current leaf, variable
 	from, to int64               // const
 	fn       func(int64, I) bool // const
+	stopped  bool                // fn returned false
 }
 
 func (t *Tree[I, S, E]) forEachItemRange(fn func(int64, I) bool, from, to int64) (int64, error) {
@@ -129,11 +130,11 @@ func (t *Tree[I, S, E]) traverseItems(n treeNode[I, S, E], w *where[I], height i
 	//
 	assert(n != nil, "traverseItems called with nil node")
 	assert(height > 0, "traverseItems called with non-positive height")
-	if w.acc >= w.to {
+	if w.stopped || w.acc >= w.to {
 		return w.acc, nil // we are done
 	}
 	if height == 1 { // we are in a leaf node
-		leaf := n.(*leafNode[I, S, E])
+		leaf := mustLeaf[I, S, E](n)
 		assert(w.acc < w.to, "traverseItems: travelled too far")
 		if w.acc+int64(leaf.n) >= w.from { // leaf contains items in range
 			for i := range leaf.n { // iterate over all items of leaf
@@ -143,13 +144,16 @@ func (t *Tree[I, S, E]) traverseItems(n treeNode[I, S, E], w *where[I], height i
 					break // past range
 				}
 				// now: from <= acc + i < to
-				w.fn(w.acc+int64(i), leaf.items[i]) // may be `yield(…)`
+				if !w.fn(w.acc+int64(i), leaf.items[i]) { // may be `yield(…)`
+					w.stopped = true
+					return w.acc + int64(i), nil
+				}
 			}
 		}
 		w.acc += int64(leaf.n) // jump past leaf
 		return w.acc, nil      // possibly go up to next recursion step
 	}
-	inner := n.(*innerNode[I, S, E])
+	inner := mustInner[I, S, E](n)
 	for _, child := range inner.children {
 		// todo remove
 		//itemcnt := t.countItems(child)
@@ -164,3 +168,53 @@ func (t *Tree[I, S, E]) traverseItems(n treeNode[I, S, E], w *where[I], height i
 	}
 	return w.acc, nil
 }
+
+// Filter returns an iterator over the items of all subtrees accepted by keep,
+// in order.
+//
+// keep is called with the summary of all items preceding a subtree and the
+// summary of the subtree itself; subtrees rejected by keep are skipped without
+// descending into them. Single items are tested the same way. keep has to be
+// monotone: if it accepts a part of a subtree, it has to accept the subtree.
+// The iterator yields each accepted item together with the summary of all
+// items before it.
+//
+// Filter supports queries over augmented summaries, like finding intervals
+// which reach beyond a position, in time proportional to the number of
+// subtrees visited rather than to the number of items.
+func (t *Tree[I, S, E]) Filter(keep func(prefix, summary S) bool) iter.Seq2[S, I] {
+	return func(yield func(S, I) bool) {
+		if t == nil || t.root == nil || keep == nil {
+			return
+		}
+		t.filterNode(t.root, t.cfg.Monoid.Zero(), keep, yield)
+	}
+}
+
+// filterNode visits the accepted items of subtree n, which is preceded by
+// items summarized as prefix. It returns false if yield asked to stop.
+func (t *Tree[I, S, E]) filterNode(n treeNode[I, S, E], prefix S,
+	keep func(S, S) bool, yield func(S, I) bool) bool {
+	//
+	assert(n != nil, "filterNode called with nil node")
+	if n.isLeaf() {
+		leaf := mustLeaf[I, S, E](n)
+		for _, item := range leaf.items {
+			s := item.Summary()
+			if keep(prefix, s) && !yield(prefix, item) {
+				return false
+			}
+			prefix = t.cfg.Monoid.Add(prefix, s)
+		}
+		return true
+	}
+	inner := mustInner[I, S, E](n)
+	for _, child := range inner.children {
+		s := child.Summary()
+		if keep(prefix, s) && !t.filterNode(child, prefix, keep, yield) {
+			return false
+		}
+		prefix = t.cfg.Monoid.Add(prefix, s)
+	}
+	return true
+}
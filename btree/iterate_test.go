@@ -169,3 +169,69 @@ func (m metric) Apply(k testM, summary textSummary, height int) (testM, bool) {
 	fmt.Printf("%s(%s)\n", strings.Repeat("| ", m.treeHeight-height), k.s)
 	return k, true
 }
+
+func TestItemRangeStopsWhenConsumerBreaks(t *testing.T) {
+	tree := buildTextTree(t, 100)
+	var seen []int64
+	for i := range tree.ItemRange(10, 90) {
+		seen = append(seen, i)
+		if i == 42 {
+			break
+		}
+	}
+	if len(seen) != 33 || seen[len(seen)-1] != 42 {
+		t.Fatalf("expected iteration to stop at 42, saw %d items", len(seen))
+	}
+}
+
+func TestFilterPrunesSubtreesAndReportsPrefix(t *testing.T) {
+	tree, err := New(Config[textChunk, textSummary, NO_EXT]{Monoid: textMonoid{}})
+	if err != nil {
+		t.Fatalf("new tree failed: %v", err)
+	}
+	var want []string
+	var wantOffsets []uint64
+	var offset uint64
+	for i := range 300 {
+		s := strconv.Itoa(i)
+		if i%37 == 0 {
+			s += "\n"
+			want = append(want, s)
+			wantOffsets = append(wantOffsets, offset)
+		}
+		offset += uint64(len(s))
+		if tree, err = tree.InsertAt(tree.Len(), fromString(s)); err != nil {
+			t.Fatalf("insert %d failed: %v", i, err)
+		}
+	}
+	calls := 0
+	keep := func(_, s textSummary) bool {
+		calls++
+		return s.Lines > 0
+	}
+	var got []string
+	var gotOffsets []uint64
+	for prefix, item := range tree.Filter(keep) {
+		got = append(got, item.String())
+		gotOffsets = append(gotOffsets, prefix.Bytes)
+	}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Fatalf("filtered items mismatch: got=%q want=%q", got, want)
+	}
+	if fmt.Sprint(gotOffsets) != fmt.Sprint(wantOffsets) {
+		t.Fatalf("prefix offsets mismatch: got=%v want=%v", gotOffsets, wantOffsets)
+	}
+	if calls >= 300 {
+		t.Fatalf("expected filter to prune subtrees, keep called %d times", calls)
+	}
+	n := 0
+	for range tree.Filter(keep) {
+		n++
+		if n == 2 {
+			break
+		}
+	}
+	if n != 2 {
+		t.Fatalf("expected filter to stop after 2 items, got %d", n)
+	}
+}
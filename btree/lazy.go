@@ -0,0 +1,306 @@
+package btree
+
+import (
+	"container/list"
+	"fmt"
+	"sync"
+)
+
+// LeafID identifies the items of one leaf, or of one subtree, within a
+// NodeStore.
+type LeafID uint64
+
+// NodeStore loads the items of unloaded leaves on demand.
+//
+// Implementations must be safe for concurrent use and must return the items of
+// a leaf in order. The returned slice is not retained beyond building the
+// resident leaf and may be reused by the store afterwards. For a stored
+// subtree, LoadLeaf returns the items of all of its leaves.
+type NodeStore[I any] interface {
+	LoadLeaf(id LeafID) ([]I, error)
+}
+
+// StoredLeaf describes an unloaded leaf: where to find its items and the
+// aggregates needed to route queries without loading it.
+//
+// With a Height above 1, it describes an unloaded subtree of that height
+// instead, which is built from its items when it is first needed. Storing
+// subtrees makes the unit of loading larger, so that far fewer descriptors are
+// held in memory.
+type StoredLeaf[S, E any] struct {
+	ID      LeafID
+	Count   int // number of items in the leaf or subtree
+	Height  int // height of a stored subtree; 0 and 1 denote a leaf
+	Summary S
+	Ext     E // ignored if the tree has no extension configured
+}
+
+// MaxStoredHeight is the maximum height of a stored subtree.
+const MaxStoredHeight = 5
+
+// FromStore creates a tree over leaves held by a node store.
+//
+// Only the leaf descriptors are kept in memory; internal nodes are rebuilt from
+// them. Leaf items are loaded when first needed and kept in an LRU cache of at
+// most resident stored leaves or subtrees (a minimum of one is always cached).
+// Summary-only queries and seeks therefore touch the store only along one
+// root-to-leaf path, and edits materialize only the leaves on their path-copy
+// spine.
+//
+// Leaves must hold between Base and 2*Base items each, unless there is only a
+// single leaf. All stored nodes must be of the same height h; stored subtrees
+// hold between Base^h and (2*Base)^h items each. A single stored subtree may
+// hold fewer, but more than (2*Base)^(h-1). If a leaf cannot be loaded later
+// on, error-returning operations report an error wrapping ErrNodeStore; other
+// operations panic.
+func FromStore[I SummarizedItem[S], S, E any](cfg Config[I, S, E], store NodeStore[I],
+	leaves []StoredLeaf[S, E], resident int) (*Tree[I, S, E], error) {
+	//
+	tree, err := New(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if store == nil {
+		return nil, fmt.Errorf("%w: node store is nil", ErrIllegalArguments)
+	}
+	if len(leaves) == 0 {
+		return tree, nil
+	}
+	height := max(1, leaves[0].Height)
+	if height > MaxStoredHeight {
+		return nil, fmt.Errorf("%w: stored subtrees of height %d exceed %d",
+			ErrIllegalArguments, height, MaxStoredHeight)
+	}
+	minItems, maxItems := tree.storedBounds(height, len(leaves) == 1)
+	src := &leafSource[I, S, E]{
+		shell:    &Tree[I, S, E]{cfg: tree.cfg},
+		store:    store,
+		capacity: max(1, resident),
+		lru:      list.New(),
+		resident: make(map[LeafID]*list.Element),
+	}
+	level := make([]treeNode[I, S, E], len(leaves))
+	for i, l := range leaves {
+		if h := max(1, l.Height); h != height {
+			return nil, fmt.Errorf("%w: stored leaf %d has height %d, expected %d",
+				ErrIllegalArguments, l.ID, h, height)
+		}
+		if l.Count < minItems || l.Count > maxItems {
+			return nil, fmt.Errorf("%w: stored leaf %d has invalid item count %d",
+				ErrIllegalArguments, l.ID, l.Count)
+		}
+		stub := &lazyNode[I, S, E]{
+			summary: l.Summary,
+			count:   l.Count,
+			height:  uint8(height),
+			root:    len(leaves) == 1,
+			id:      l.ID,
+			src:     src,
+		}
+		if tree.cfg.Extension != nil {
+			stub.ext = l.Ext
+		}
+		level[i] = stub
+	}
+	tree.root, tree.height = tree.buildLevels(level, height, 1)
+	return tree, nil
+}
+
+// storedBounds returns the minimum and maximum number of items of a stored
+// node of the given height. A single stored node is the root of its tree.
+func (t *Tree[I, S, E]) storedBounds(height int, root bool) (minItems, maxItems int) {
+	minItems, maxItems = 1, 1
+	for range height {
+		minItems *= t.base()
+		maxItems *= t.maxItems()
+	}
+	if root {
+		minItems = maxItems/t.maxItems() + 1
+		if height == 1 {
+			minItems = 1
+		}
+	}
+	return minItems, maxItems
+}
+
+// storedFanout returns the number of children of a stored subtree of n items
+// and the given height above 1, spreading the items evenly over as few
+// children as possible.
+func (t *Tree[I, S, E]) storedFanout(n, height int, root bool) int {
+	_, span := t.storedBounds(height-1, false)
+	k := (n + span - 1) / span
+	if !root {
+		k = max(k, t.base())
+	}
+	return k
+}
+
+// buildSubtree builds a stored subtree of the given height over items, which
+// have to be within storedBounds.
+func (t *Tree[I, S, E]) buildSubtree(items []I, height int, root bool) treeNode[I, S, E] {
+	if height == 1 {
+		return t.makeLeaf(items)
+	}
+	k := t.storedFanout(len(items), height, root)
+	children := make([]treeNode[I, S, E], k)
+	q, r := len(items)/k, len(items)%k
+	for i, start := 0, 0; i < k; i++ {
+		size := q
+		if i < r {
+			size++
+		}
+		children[i] = t.buildSubtree(items[start:start+size], height-1, false)
+		start += size
+	}
+	return t.makeInternal(children...)
+}
+
+// storedLeaves returns the number of leaves buildSubtree builds for n items.
+func (t *Tree[I, S, E]) storedLeaves(n, height int, root bool) int64 {
+	if height == 1 {
+		return 1
+	}
+	k := t.storedFanout(n, height, root)
+	q, r := n/k, n%k
+	leaves := int64(k-r) * t.storedLeaves(q, height-1, false)
+	if r > 0 {
+		leaves += int64(r) * t.storedLeaves(q+1, height-1, false)
+	}
+	return leaves
+}
+
+// lazyNode is a placeholder for a leaf, or a subtree of leaves, whose items
+// live in a node store. It carries the aggregates of the node, so it can be
+// routed over like a resident node.
+//
+// Code which needs leaf items resolves a lazyNode with leafOf or mustLeaf,
+// code which needs children with innerOf or mustInner.
+type lazyNode[I SummarizedItem[S], S, E any] struct {
+	summary S
+	ext     E
+	count   int   // number of items
+	height  uint8 // 1 for a leaf
+	root    bool  // single stored node of a tree, exempt from minimum occupancy
+	id      LeafID
+	src     *leafSource[I, S, E]
+}
+
+func (l *lazyNode[I, S, E]) isLeaf() bool  { return l.height == 1 }
+func (l *lazyNode[I, S, E]) Summary() S    { return l.summary }
+func (l *lazyNode[I, S, E]) Weight() int64 { return int64(l.count) }
+func (l *lazyNode[I, S, E]) Ext() E        { return l.ext }
+
+func (l *lazyNode[I, S, E]) String() string {
+	return fmt.Sprintf("[%d items @%d]", l.count, l.id)
+}
+
+// load returns the resident node for l. It panics with a loadFailure if the
+// node store fails, which is converted to an error by catchLoadFailure.
+func (l *lazyNode[I, S, E]) load() treeNode[I, S, E] {
+	n, err := l.src.load(l)
+	if err != nil {
+		panic(loadFailure{err: err})
+	}
+	return n
+}
+
+// leafSource connects unloaded nodes of a tree to their node store and caches
+// resident nodes in LRU order.
+type leafSource[I SummarizedItem[S], S, E any] struct {
+	shell    *Tree[I, S, E] // carries the configuration for building nodes
+	store    NodeStore[I]
+	capacity int
+	mu       sync.Mutex
+	lru      *list.List // of residentNode, most recently used first
+	resident map[LeafID]*list.Element
+}
+
+type residentNode[I SummarizedItem[S], S, E any] struct {
+	id   LeafID
+	node treeNode[I, S, E]
+}
+
+func (src *leafSource[I, S, E]) load(l *lazyNode[I, S, E]) (treeNode[I, S, E], error) {
+	src.mu.Lock()
+	defer src.mu.Unlock()
+	if e, ok := src.resident[l.id]; ok {
+		src.lru.MoveToFront(e)
+		return e.Value.(residentNode[I, S, E]).node, nil
+	}
+	items, err := src.store.LoadLeaf(l.id)
+	if err != nil {
+		return nil, fmt.Errorf("%w: leaf %d: %w", ErrNodeStore, l.id, err)
+	}
+	if len(items) != l.count {
+		return nil, fmt.Errorf("%w: leaf %d: expected %d items, loaded %d",
+			ErrNodeStore, l.id, l.count, len(items))
+	}
+	tracer().Debugf("btree: loaded leaf %d with %d items", l.id, len(items))
+	n := src.shell.buildSubtree(items, int(l.height), l.root)
+	src.resident[l.id] = src.lru.PushFront(residentNode[I, S, E]{id: l.id, node: n})
+	for src.lru.Len() > src.capacity {
+		oldest := src.lru.Remove(src.lru.Back()).(residentNode[I, S, E])
+		delete(src.resident, oldest.id)
+	}
+	return n, nil
+}
+
+// leafOf returns the resident leaf for a leaf-level node, loading unloaded
+// leaves through their node store. ok is false if n is not a leaf.
+func leafOf[I SummarizedItem[S], S, E any](n treeNode[I, S, E]) (leaf *leafNode[I, S, E], ok bool) {
+	switch l := n.(type) {
+	case *leafNode[I, S, E]:
+		return l, true
+	case *lazyNode[I, S, E]:
+		if l.height == 1 {
+			return l.load().(*leafNode[I, S, E]), true
+		}
+	}
+	return nil, false
+}
+
+// mustLeaf is like leafOf, but treats non-leaf nodes as an internal error.
+func mustLeaf[I SummarizedItem[S], S, E any](n treeNode[I, S, E]) *leafNode[I, S, E] {
+	leaf, ok := leafOf[I, S, E](n)
+	assert(ok, "expected leaf node")
+	return leaf
+}
+
+// innerOf returns the resident internal node for n, loading unloaded subtrees
+// through their node store. ok is false if n is a leaf.
+func innerOf[I SummarizedItem[S], S, E any](n treeNode[I, S, E]) (inner *innerNode[I, S, E], ok bool) {
+	switch v := n.(type) {
+	case *innerNode[I, S, E]:
+		return v, true
+	case *lazyNode[I, S, E]:
+		if v.height > 1 {
+			return v.load().(*innerNode[I, S, E]), true
+		}
+	}
+	return nil, false
+}
+
+// mustInner is like innerOf, but treats leaves as an internal error.
+func mustInner[I SummarizedItem[S], S, E any](n treeNode[I, S, E]) *innerNode[I, S, E] {
+	inner, ok := innerOf[I, S, E](n)
+	assert(ok, "expected internal node")
+	return inner
+}
+
+// loadFailure is the panic value used to unwind from a failed leaf load deep
+// inside tree algorithms.
+type loadFailure struct {
+	err error
+}
+
+// catchLoadFailure converts a loadFailure panic into an error result. It has to
+// be deferred directly by public operations with a named error result.
+func catchLoadFailure(err *error) {
+	if r := recover(); r != nil {
+		if f, ok := r.(loadFailure); ok {
+			*err = f.err
+			return
+		}
+		panic(r)
+	}
+}
@@ -8,6 +8,8 @@ func (t *Tree[I, S, E]) cloneNode(n treeNode[I, S, E]) treeNode[I, S, E] {
 	switch n := n.(type) {
 	case *leafNode[I, S, E]:
 		return t.cloneLeaf(n)
+	case *lazyNode[I, S, E]:
+		return t.cloneNode(n.load())
 	case *innerNode[I, S, E]:
 		return t.cloneInner(n)
 	default:
@@ -17,11 +19,10 @@ func (t *Tree[I, S, E]) cloneNode(n treeNode[I, S, E]) treeNode[I, S, E] {
 
 func (t *Tree[I, S, E]) cloneLeaf(leaf *leafNode[I, S, E]) *leafNode[I, S, E] {
 	assert(leaf != nil, "cloneLeaf called with nil leaf")
-	cloned := &leafNode[I, S, E]{
-		summary: leaf.summary,
-		ext:     leaf.ext,
-		n:       leaf.n,
-	}
+	cloned := t.newLeafNode()
+	cloned.summary = leaf.summary
+	cloned.ext = leaf.ext
+	cloned.n = leaf.n
 	copy(cloned.itemStore[:int(cloned.n)], leaf.itemStore[:int(leaf.n)])
 	cloned.items = cloned.itemStore[:int(cloned.n)]
 	return cloned
@@ -33,12 +34,11 @@ func (t *Tree[I, S, E]) cloneLeaf(leaf *leafNode[I, S, E]) *leafNode[I, S, E] {
 // operation.
 func (t *Tree[I, S, E]) cloneInner(inner *innerNode[I, S, E]) *innerNode[I, S, E] {
 	assert(inner != nil, "cloneInner called with nil inner node")
-	cloned := &innerNode[I, S, E]{
-		summary: inner.summary,
-		weight:  inner.weight,
-		ext:     inner.ext,
-		n:       inner.n,
-	}
+	cloned := t.newInnerNode()
+	cloned.summary = inner.summary
+	cloned.weight = inner.weight
+	cloned.ext = inner.ext
+	cloned.n = inner.n
 	copy(cloned.childStore[:int(cloned.n)], inner.childStore[:int(inner.n)])
 	cloned.children = cloned.childStore[:int(cloned.n)]
 	return cloned
@@ -197,7 +197,7 @@ func (t *Tree[I, S, E]) removeLeafItemsRange(leaf *leafNode[I, S, E], from, to i
 
 // leafOverflow reports whether leaf exceeds the allowed non-overflow occupancy.
 func (t *Tree[I, S, E]) leafOverflow(leaf *leafNode[I, S, E]) bool {
-	return leaf != nil && len(leaf.items) > MaxLeafItems
+	return leaf != nil && len(leaf.items) > t.maxItems()
 }
 
 // leafUnderflow reports whether a non-root leaf violates minimum occupancy.
@@ -206,12 +206,12 @@ func (t *Tree[I, S, E]) leafUnderflow(leaf *leafNode[I, S, E], isRoot bool) bool
 	if isRoot {
 		return false
 	}
-	return len(leaf.items) < Base
+	return len(leaf.items) < t.base()
 }
 
 // innerOverflow reports whether internal node exceeds maximum children.
 func (t *Tree[I, S, E]) innerOverflow(inner *innerNode[I, S, E]) bool {
-	return inner != nil && len(inner.children) > MaxChildren
+	return inner != nil && len(inner.children) > t.maxItems()
 }
 
 // innerUnderflow reports whether a non-root internal node is below min fill.
@@ -220,7 +220,7 @@ func (t *Tree[I, S, E]) innerUnderflow(inner *innerNode[I, S, E], isRoot bool) b
 	if isRoot {
 		return false
 	}
-	return len(inner.children) < Base
+	return len(inner.children) < t.base()
 }
 
 // insertIntoLeafLocal inserts items at a local leaf offset.
@@ -250,7 +250,7 @@ func (t *Tree[I, S, E]) insertIntoLeafLocal(leaf *leafNode[I, S, E], index int64
 func (t *Tree[I, S, E]) splitLeaf(leaf *leafNode[I, S, E]) (*leafNode[I, S, E], *leafNode[I, S, E]) {
 	assert(leaf != nil, "splitLeaf called with nil leaf")
 	n := len(leaf.items)
-	maxItems := MaxLeafItems
+	maxItems := t.maxItems()
 	if n <= maxItems {
 		return t.cloneLeaf(leaf), nil
 	}
@@ -258,7 +258,7 @@ func (t *Tree[I, S, E]) splitLeaf(leaf *leafNode[I, S, E]) (*leafNode[I, S, E],
 	mid := n / 2
 	left := t.makeLeaf(leaf.items[:mid])
 	right := t.makeLeaf(leaf.items[mid:])
-	assert(len(left.items) >= Base && len(right.items) >= Base,
+	assert(len(left.items) >= t.base() && len(right.items) >= t.base(),
 		"splitLeaf violates leaf occupancy bounds")
 	return left, right
 }
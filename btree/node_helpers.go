@@ -1,11 +1,53 @@
 package btree
 
-// makeLeaf materializes a new leaf backed by fixed inline storage and computes
+// defaultLeaf and defaultInner hold a node together with backing storage for
+// the default base, so that such nodes cost a single allocation.
+type defaultLeaf[I SummarizedItem[S], S, E any] struct {
+	node  leafNode[I, S, E]
+	store [OverflowStorage]I
+}
+
+type defaultInner[I SummarizedItem[S], S, E any] struct {
+	node  innerNode[I, S, E]
+	store [OverflowStorage]treeNode[I, S, E]
+}
+
+// newLeafNode allocates an empty leaf with backing storage sized for the
+// tree's branching factor.
+func (t *Tree[I, S, E]) newLeafNode() *leafNode[I, S, E] {
+	var leaf *leafNode[I, S, E]
+	if size := t.storageSize(); size == OverflowStorage {
+		d := &defaultLeaf[I, S, E]{}
+		leaf = &d.node
+		leaf.itemStore = d.store[:]
+	} else {
+		leaf = &leafNode[I, S, E]{itemStore: make([]I, size)}
+	}
+	leaf.items = leaf.itemStore[:0]
+	return leaf
+}
+
+// newInnerNode allocates an empty internal node with backing storage sized for
+// the tree's branching factor.
+func (t *Tree[I, S, E]) newInnerNode() *innerNode[I, S, E] {
+	var inner *innerNode[I, S, E]
+	if size := t.storageSize(); size == OverflowStorage {
+		d := &defaultInner[I, S, E]{}
+		inner = &d.node
+		inner.childStore = d.store[:]
+	} else {
+		inner = &innerNode[I, S, E]{childStore: make([]treeNode[I, S, E], size)}
+	}
+	inner.children = inner.childStore[:0]
+	return inner
+}
+
+// makeLeaf materializes a new leaf backed by fixed-capacity storage and computes
 // its summary.
 func (t *Tree[I, S, E]) makeLeaf(items []I) *leafNode[I, S, E] {
-	leaf := &leafNode[I, S, E]{}
+	leaf := t.newLeafNode()
 	assert(len(items) <= len(leaf.itemStore), "makeLeaf exceeds fixed leaf capacity")
-	copy(leaf.itemStore[:], items)
+	copy(leaf.itemStore, items)
 	leaf.n = uint8(len(items))
 	leaf.items = leaf.itemStore[:len(items)]
 	leaf.summary = t.cfg.Monoid.Zero()
@@ -23,12 +65,12 @@ func (t *Tree[I, S, E]) makeLeaf(items []I) *leafNode[I, S, E] {
 	return leaf
 }
 
-// makeInternal materializes a new internal node backed by fixed inline storage
+// makeInternal materializes a new internal node backed by fixed-capacity storage
 // and computes its summary from child summaries.
 func (t *Tree[I, S, E]) makeInternal(children ...treeNode[I, S, E]) *innerNode[I, S, E] {
-	inner := &innerNode[I, S, E]{}
+	inner := t.newInnerNode()
 	assert(len(children) <= len(inner.childStore), "makeInternal exceeds fixed node capacity")
-	copy(inner.childStore[:], children)
+	copy(inner.childStore, children)
 	inner.n = uint8(len(children))
 	inner.children = inner.childStore[:len(children)]
 	inner.summary = t.cfg.Monoid.Zero()
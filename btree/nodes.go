@@ -3,11 +3,17 @@ package btree
 import "fmt"
 
 const (
-	// Fixed storage capacities aligned with a TREE_BASE=6 shape.
+	// Default storage capacities aligned with a TREE_BASE=6 shape.
+	// Trees may select a different branching factor with Config.Base.
 	Base            = 6
 	MaxChildren     = 2 * Base
 	MaxLeafItems    = 2 * Base
 	OverflowStorage = MaxChildren + 1 // transient overflow before split
+
+	// MinBase and MaxBase bound Config.Base. The upper bound is dictated by the
+	// uint8 occupancy counters of nodes (2*MaxBase+1 must fit).
+	MinBase = 2
+	MaxBase = 127
 )
 
 type treeNode[I SummarizedItem[S], S, E any] interface {
@@ -22,8 +28,9 @@ type leafNode[I SummarizedItem[S], S, E any] struct {
 	ext     E
 	// n is the logical item count; valid items are itemStore[:n].
 	n uint8
-	// itemStore is the fixed backing storage for leaf items.
-	itemStore [OverflowStorage]I
+	// itemStore is the fixed-capacity backing storage for leaf items, sized
+	// 2*base+1 for the tree's branching factor.
+	itemStore []I
 	// items is a dynamic-length view over itemStore and must satisfy:
 	// len(items) == int(n), cap(items) == len(itemStore), items backed by itemStore.
 	items []I
@@ -45,8 +52,9 @@ type innerNode[I SummarizedItem[S], S, E any] struct {
 	weight int64
 	// n is the logical child count; valid children are childStore[:n].
 	n uint8
-	// childStore is the fixed backing storage for child pointers.
-	childStore [OverflowStorage]treeNode[I, S, E]
+	// childStore is the fixed-capacity backing storage for child pointers, sized
+	// 2*base+1 for the tree's branching factor.
+	childStore []treeNode[I, S, E]
 	// children is a dynamic-length view over childStore and must satisfy:
 	// len(children) == int(n), cap(children) == len(childStore), children backed by childStore.
 	children []treeNode[I, S, E]
@@ -85,9 +93,16 @@ type Config[I SummarizedItem[S], S, E any] struct {
 	// Monoid aggregates summaries up the tree.
 	Monoid    SummaryMonoid[S]
 	Extension SumExtension[I, S, E]
+	// Base is the branching factor of the tree: non-root nodes hold between
+	// Base and 2*Base items (leaves) or children (internal nodes).
+	// Zero selects the default Base. Valid values are MinBase…MaxBase.
+	Base int
 }
 
 func (cfg Config[I, S, E]) normalized() Config[I, S, E] {
+	if cfg.Base == 0 {
+		cfg.Base = Base
+	}
 	return cfg
 }
 
@@ -99,5 +114,29 @@ func (cfg Config[I, S, E]) validate() error {
 	if cfg.Extension != nil && cfg.Extension.MagicID() == "" {
 		return fmt.Errorf("%w: extension magic id is required", ErrInvalidConfig)
 	}
+	if cfg.Base < MinBase || cfg.Base > MaxBase {
+		return fmt.Errorf("%w: base %d out of range [%d,%d]", ErrInvalidConfig,
+			cfg.Base, MinBase, MaxBase)
+	}
 	return nil
 }
+
+// base returns the effective branching factor of the tree.
+func (t *Tree[I, S, E]) base() int {
+	if t.cfg.Base == 0 {
+		return Base
+	}
+	return t.cfg.Base
+}
+
+// maxItems returns the maximum occupancy of non-root nodes (leaf items or
+// children of internal nodes).
+func (t *Tree[I, S, E]) maxItems() int {
+	return 2 * t.base()
+}
+
+// storageSize returns the backing storage capacity of nodes, which allows for
+// transient overflow by one element before a split.
+func (t *Tree[I, S, E]) storageSize() int {
+	return 2*t.base() + 1
+}
@@ -0,0 +1,146 @@
+package btree
+
+import (
+	"errors"
+	"math/rand"
+	"strconv"
+	"testing"
+)
+
+func makeTextTreeWithBase(t *testing.T, base int) *Tree[textChunk, textSummary, NO_EXT] {
+	t.Helper()
+	tree, err := New[textChunk, textSummary](Config[textChunk, textSummary, NO_EXT]{
+		Monoid: textMonoid{},
+		Base:   base,
+	})
+	if err != nil {
+		t.Fatalf("failed to create tree with base %d: %v", base, err)
+	}
+	return tree
+}
+
+func TestConfigDefaultsBase(t *testing.T) {
+	tree := makeTextTree(t)
+	if tree.Config().Base != Base {
+		t.Fatalf("expected default base %d, got %d", Base, tree.Config().Base)
+	}
+}
+
+func TestConfigRejectsBaseOutOfRange(t *testing.T) {
+	for _, base := range []int{-1, 1, MaxBase + 1} {
+		_, err := New[textChunk, textSummary](Config[textChunk, textSummary, NO_EXT]{
+			Monoid: textMonoid{},
+			Base:   base,
+		})
+		if !errors.Is(err, ErrInvalidConfig) {
+			t.Fatalf("expected ErrInvalidConfig for base %d, got %v", base, err)
+		}
+	}
+}
+
+func TestCustomBaseHonorsOccupancy(t *testing.T) {
+	for _, base := range []int{MinBase, 3, 16, 64} {
+		tree := makeTextTreeWithBase(t, base)
+		rnd := rand.New(rand.NewSource(int64(base)))
+		var model []string
+		var err error
+		for i := range 40 * base {
+			at := rnd.Int63n(int64(len(model)) + 1)
+			s := strconv.Itoa(i)
+			if tree, err = tree.InsertAt(at, fromString(s)); err != nil {
+				t.Fatalf("base %d: insert %d failed: %v", base, i, err)
+			}
+			model = append(model[:at], append([]string{s}, model[at:]...)...)
+			if err := tree.Check(); err != nil {
+				t.Fatalf("base %d: invariants after insert %d: %v", base, i, err)
+			}
+		}
+		if tree.Height() < 2 {
+			t.Fatalf("base %d: expected tree to grow beyond a leaf root", base)
+		}
+		for len(model) > 0 {
+			at := rnd.Int63n(int64(len(model)))
+			if tree, err = tree.DeleteAt(at); err != nil {
+				t.Fatalf("base %d: delete at %d failed: %v", base, at, err)
+			}
+			model = append(model[:at], model[at+1:]...)
+			if err := tree.Check(); err != nil {
+				t.Fatalf("base %d: invariants after delete: %v", base, err)
+			}
+			if len(model)%(base+1) == 0 {
+				got := collectTextItems(tree)
+				for i := range model {
+					if got[i] != model[i] {
+						t.Fatalf("base %d: item mismatch at %d: %q != %q", base, i, got[i], model[i])
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestCustomBaseLeafCapacity(t *testing.T) {
+	tree := makeTextTreeWithBase(t, 16)
+	var err error
+	for i := range 32 {
+		if tree, err = tree.InsertAt(tree.Len(), fromString(strconv.Itoa(i))); err != nil {
+			t.Fatalf("insert %d failed: %v", i, err)
+		}
+	}
+	if tree.Height() != 1 {
+		t.Fatalf("expected 32 items to fit into a single leaf with base 16, height=%d", tree.Height())
+	}
+	if tree, err = tree.InsertAt(0, fromString("x")); err != nil {
+		t.Fatalf("insert failed: %v", err)
+	}
+	if tree.Height() != 2 {
+		t.Fatalf("expected leaf split at 33 items, height=%d", tree.Height())
+	}
+}
+
+func TestConcatRejectsBaseMismatch(t *testing.T) {
+	left := makeTextTreeWithBase(t, 4)
+	right := makeTextTreeWithBase(t, 8)
+	var err error
+	if left, err = left.InsertAt(0, fromString("a")); err != nil {
+		t.Fatalf("insert failed: %v", err)
+	}
+	if right, err = right.InsertAt(0, fromString("b")); err != nil {
+		t.Fatalf("insert failed: %v", err)
+	}
+	if _, err = left.Concat(right); !errors.Is(err, ErrInvalidConfig) {
+		t.Fatalf("expected ErrInvalidConfig for base mismatch, got %v", err)
+	}
+}
+
+func TestConcatWithEmptyTreeIgnoresBase(t *testing.T) {
+	empty := makeTextTreeWithBase(t, 4)
+	tree := makeTextTreeWithBase(t, 8)
+	var err error
+	if tree, err = tree.InsertAt(0, fromString("b")); err != nil {
+		t.Fatalf("insert failed: %v", err)
+	}
+	for _, pair := range [][2]*Tree[textChunk, textSummary, NO_EXT]{{empty, tree}, {tree, empty}} {
+		joined, err := pair[0].Concat(pair[1])
+		if err != nil {
+			t.Fatalf("concat with empty tree failed: %v", err)
+		}
+		if joined != tree {
+			t.Errorf("expected the non-empty operand as result")
+		}
+	}
+}
+
+func TestDefaultBaseNodeAllocations(t *testing.T) {
+	tree := makeTextTreeWithBase(t, 0)
+	if n := testing.AllocsPerRun(100, func() { _ = tree.newLeafNode() }); n != 1 {
+		t.Errorf("expected a single allocation per leaf, have %.0f", n)
+	}
+	if n := testing.AllocsPerRun(100, func() { _ = tree.newInnerNode() }); n != 1 {
+		t.Errorf("expected a single allocation per inner node, have %.0f", n)
+	}
+	tree = makeTextTreeWithBase(t, 8)
+	if leaf := tree.newLeafNode(); len(leaf.itemStore) != 17 {
+		t.Errorf("expected storage for 17 items with base 8, have %d", len(leaf.itemStore))
+	}
+}
@@ -0,0 +1,104 @@
+package btree
+
+import (
+	"runtime"
+	"sync"
+)
+
+// ParallelReduce maps every item of tree with fn and folds the results in item
+// order with combine. Disjoint subtrees are reduced concurrently by up to
+// workers goroutines; if workers is less than 1, GOMAXPROCS goroutines are used.
+//
+// combine must be associative, i.e. (fn, combine) should form a semigroup over
+// items, as summaries do. fn and combine are called concurrently and must be
+// safe for concurrent use. For an empty tree the zero value of R is returned.
+func ParallelReduce[I SummarizedItem[S], S, E, R any](tree *Tree[I, S, E],
+	fn func(I) R, combine func(R, R) R, workers int) R {
+	//
+	var zero R
+	if tree.IsEmpty() || fn == nil || combine == nil {
+		return zero
+	}
+	workers = effectiveWorkers(workers)
+	tasks, _ := tree.fanOut(workers * 4)
+	results := make([]R, len(tasks))
+	parallelFor(len(tasks), workers, func(i int) {
+		results[i] = reduceNode(tasks[i], fn, combine)
+	})
+	acc := results[0]
+	for _, r := range results[1:] {
+		acc = combine(acc, r)
+	}
+	return acc
+}
+
+// fanOut descends level by level from the root until at least n disjoint
+// subtrees are collected or the leaves are reached. It returns the subtrees in
+// item order, together with their height.
+func (t *Tree[I, S, E]) fanOut(n int) ([]treeNode[I, S, E], int) {
+	if t.root == nil {
+		return nil, 0
+	}
+	frontier := []treeNode[I, S, E]{t.root}
+	height := t.height
+	for len(frontier) < n && height > 1 {
+		var next []treeNode[I, S, E]
+		for _, node := range frontier {
+			next = append(next, mustInner[I, S, E](node).children...)
+		}
+		frontier = next
+		height--
+	}
+	return frontier, height
+}
+
+// reduceNode sequentially reduces all items below n. Subtrees are never empty,
+// so the fold may start with the first item.
+func reduceNode[I SummarizedItem[S], S, E, R any](n treeNode[I, S, E], fn func(I) R, combine func(R, R) R) R {
+	assert(n != nil, "reduceNode called with nil node")
+	if n.isLeaf() {
+		leaf := mustLeaf[I, S, E](n)
+		acc := fn(leaf.items[0])
+		for _, item := range leaf.items[1:] {
+			acc = combine(acc, fn(item))
+		}
+		return acc
+	}
+	inner := mustInner[I, S, E](n)
+	acc := reduceNode(inner.children[0], fn, combine)
+	for _, child := range inner.children[1:] {
+		acc = combine(acc, reduceNode(child, fn, combine))
+	}
+	return acc
+}
+
+// effectiveWorkers maps non-positive worker counts to GOMAXPROCS.
+func effectiveWorkers(workers int) int {
+	if workers < 1 {
+		return runtime.GOMAXPROCS(0)
+	}
+	return workers
+}
+
+// parallelFor calls fn for every index in [0,n), distributing contiguous blocks
+// of indices over up to workers goroutines, and waits for all calls to return.
+func parallelFor(n int, workers int, fn func(int)) {
+	workers = min(effectiveWorkers(workers), n)
+	if workers <= 1 {
+		for i := range n {
+			fn(i)
+		}
+		return
+	}
+	var wg sync.WaitGroup
+	block := (n + workers - 1) / workers
+	for from := 0; from < n; from += block {
+		to := min(from+block, n)
+		wg.Go(func() {
+			for i := from; i < to; i++ {
+				fn(i)
+			}
+		})
+	}
+	wg.Wait()
+}
@@ -0,0 +1,44 @@
+package btree
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParallelReduceKeepsItemOrder(t *testing.T) {
+	cfg := Config[textChunk, textSummary, NO_EXT]{Monoid: textMonoid{}}
+	items := textItems(3000)
+	tree, err := FromItems(cfg, items)
+	if err != nil {
+		t.Fatalf("FromItems failed: %v", err)
+	}
+	var want strings.Builder
+	for _, item := range items {
+		want.WriteString(string(item))
+	}
+	concat := func(a, b string) string { return a + b }
+	for _, workers := range []int{0, 1, 3, 64} {
+		got := ParallelReduce(tree, textChunk.String, concat, workers)
+		if got != want.String() {
+			t.Fatalf("workers=%d: reduce result out of order", workers)
+		}
+	}
+}
+
+func TestParallelReduceMatchesSummary(t *testing.T) {
+	tree := buildTextTree(t, 500)
+	bytes := ParallelReduce(tree, func(c textChunk) uint64 { return uint64(len(c)) },
+		func(a, b uint64) uint64 { return a + b }, 4)
+	if bytes != tree.Summary().Bytes {
+		t.Fatalf("parallel byte count %d != summary %d", bytes, tree.Summary().Bytes)
+	}
+}
+
+func TestParallelReduceEmptyTree(t *testing.T) {
+	tree := makeTextTree(t)
+	got := ParallelReduce(tree, func(c textChunk) int { return 1 },
+		func(a, b int) int { return a + b }, 4)
+	if got != 0 {
+		t.Fatalf("expected zero result for empty tree, got %d", got)
+	}
+}
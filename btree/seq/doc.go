@@ -0,0 +1,24 @@
+/*
+Package seq provides Seq, a persistent sequence of arbitrary values.
+
+Seq stores its values in a btree.Tree with a count-only summary. All edits
+return a new sequence and leave the original unchanged, sharing all untouched
+parts of the tree between versions. This makes Seq suitable for metadata kept
+alongside a text, like per-line information, diagnostics or decorations,
+which has to follow the text's edit history.
+
+	s := seq.Of("a", "b", "c")
+	s2, _ := s.Insert(1, "x") // s is unchanged
+	for i, v := range s2.All() {
+		fmt.Println(i, v)
+	}
+
+Index, insert, delete, slice and concat operations are O(log n).
+*/
+package seq
+
+func assert(condition bool, msg string) {
+	if !condition {
+		panic(msg)
+	}
+}
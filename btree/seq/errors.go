@@ -0,0 +1,6 @@
+package seq
+
+import "errors"
+
+// ErrIndexOutOfBounds signals an invalid index or range.
+var ErrIndexOutOfBounds = errors.New("seq: index out of bounds")
@@ -0,0 +1,178 @@
+package seq
+
+import (
+	"fmt"
+	"iter"
+
+	"github.com/npillmayer/cords/btree"
+)
+
+// Seq is a persistent sequence of values of type T.
+//
+// The zero value is an empty sequence, ready to use. Seq values may be copied
+// and shared between goroutines freely.
+type Seq[T any] struct {
+	tree *btree.Tree[item[T], count, btree.NO_EXT]
+}
+
+// item wraps a value as a tree item.
+type item[T any] struct {
+	value T
+}
+
+// count is the summary of a run of items: their number.
+type count int64
+
+func (item[T]) Summary() count { return 1 }
+
+type countMonoid struct{}
+
+func (countMonoid) Zero() count                 { return 0 }
+func (countMonoid) Add(left, right count) count { return left + right }
+
+func config[T any]() btree.Config[item[T], count, btree.NO_EXT] {
+	return btree.Config[item[T], count, btree.NO_EXT]{Monoid: countMonoid{}}
+}
+
+// Of creates a sequence holding values.
+func Of[T any](values ...T) Seq[T] {
+	items := make([]item[T], len(values))
+	for i, v := range values {
+		items[i] = item[T]{v}
+	}
+	tree, err := btree.FromItems(config[T](), items)
+	assert(err == nil, "seq.Of: cannot build tree")
+	return Seq[T]{tree}
+}
+
+// Len returns the number of values in s.
+func (s Seq[T]) Len() int {
+	return int(s.tree.Len())
+}
+
+// At returns the value at index i.
+func (s Seq[T]) At(i int) (T, error) {
+	var zero T
+	if i < 0 || i >= s.Len() {
+		return zero, fmt.Errorf("%w: %d", ErrIndexOutOfBounds, i)
+	}
+	it, err := s.tree.At(int64(i))
+	if err != nil {
+		return zero, err
+	}
+	return it.value, nil
+}
+
+// Set returns a sequence with the value at index i replaced by v.
+func (s Seq[T]) Set(i int, v T) (Seq[T], error) {
+	if i < 0 || i >= s.Len() {
+		return s, fmt.Errorf("%w: %d", ErrIndexOutOfBounds, i)
+	}
+	tree, err := s.tree.DeleteAt(int64(i))
+	if err != nil {
+		return s, err
+	}
+	if tree, err = tree.InsertAt(int64(i), item[T]{v}); err != nil {
+		return s, err
+	}
+	return Seq[T]{tree}, nil
+}
+
+// Insert returns a sequence with values inserted before index i. An index of
+// Len() appends values.
+func (s Seq[T]) Insert(i int, values ...T) (Seq[T], error) {
+	if i < 0 || i > s.Len() {
+		return s, fmt.Errorf("%w: %d", ErrIndexOutOfBounds, i)
+	}
+	if len(values) == 0 {
+		return s, nil
+	}
+	items := make([]item[T], len(values))
+	for j, v := range values {
+		items[j] = item[T]{v}
+	}
+	tree, err := s.ensureTree().InsertAt(int64(i), items...)
+	if err != nil {
+		return s, err
+	}
+	return Seq[T]{tree}, nil
+}
+
+// Delete returns a sequence with n values removed, starting at index i.
+func (s Seq[T]) Delete(i, n int) (Seq[T], error) {
+	if i < 0 || n < 0 || i+n > s.Len() {
+		return s, fmt.Errorf("%w: [%d,%d)", ErrIndexOutOfBounds, i, i+n)
+	}
+	if n == 0 {
+		return s, nil
+	}
+	tree, err := s.tree.DeleteRange(int64(i), int64(n))
+	if err != nil {
+		return s, err
+	}
+	return Seq[T]{tree}, nil
+}
+
+// Slice returns the sub-sequence of values in [from,to).
+func (s Seq[T]) Slice(from, to int) (Seq[T], error) {
+	if from < 0 || from > to || to > s.Len() {
+		return Seq[T]{}, fmt.Errorf("%w: [%d,%d)", ErrIndexOutOfBounds, from, to)
+	}
+	if from == to {
+		return Seq[T]{}, nil
+	}
+	left, _, err := s.tree.SplitAt(int64(to))
+	if err != nil {
+		return Seq[T]{}, err
+	}
+	_, mid, err := left.SplitAt(int64(from))
+	if err != nil {
+		return Seq[T]{}, err
+	}
+	return Seq[T]{mid}, nil
+}
+
+// Concat returns the concatenation of s and other.
+func (s Seq[T]) Concat(other Seq[T]) Seq[T] {
+	if other.Len() == 0 {
+		return s
+	}
+	if s.Len() == 0 {
+		return other
+	}
+	tree, err := s.tree.Concat(other.tree)
+	assert(err == nil, "seq.Concat: cannot concatenate trees")
+	return Seq[T]{tree}
+}
+
+// All returns an iterator over index/value pairs of s, in order.
+func (s Seq[T]) All() iter.Seq2[int, T] {
+	return func(yield func(int, T) bool) {
+		if s.Len() == 0 {
+			return
+		}
+		for i, it := range s.tree.ItemRange(0, s.tree.Len()) {
+			if !yield(int(i), it.value) {
+				return
+			}
+		}
+	}
+}
+
+// Values returns the values of s as a slice.
+func (s Seq[T]) Values() []T {
+	values := make([]T, 0, s.Len())
+	for _, v := range s.All() {
+		values = append(values, v)
+	}
+	return values
+}
+
+func (s Seq[T]) ensureTree() *btree.Tree[item[T], count, btree.NO_EXT] {
+	if s.tree != nil {
+		return s.tree
+	}
+	tree, err := btree.New(config[T]())
+	assert(err == nil, "seq: cannot create tree")
+	return tree
+}
@@ -0,0 +1,99 @@
+package seq
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestZeroSeqIsEmpty(t *testing.T) {
+	var s Seq[string]
+	if s.Len() != 0 || len(s.Values()) != 0 {
+		t.Fatalf("expected zero Seq to be empty")
+	}
+	if _, err := s.At(0); !errors.Is(err, ErrIndexOutOfBounds) {
+		t.Fatalf("expected ErrIndexOutOfBounds, got %v", err)
+	}
+	s, err := s.Insert(0, "a", "b")
+	if err != nil {
+		t.Fatalf("insert failed: %v", err)
+	}
+	if !slices.Equal(s.Values(), []string{"a", "b"}) {
+		t.Fatalf("unexpected values %v", s.Values())
+	}
+}
+
+func TestSeqEditsArePersistent(t *testing.T) {
+	values := make([]int, 500)
+	for i := range values {
+		values[i] = i
+	}
+	s := Of(values...)
+	s2, err := s.Set(250, -1)
+	if err != nil {
+		t.Fatalf("set failed: %v", err)
+	}
+	if s3, err := s2.Insert(500, 1000, 1001); err != nil || s3.Len() != 502 {
+		t.Fatalf("append failed: %v", err)
+	}
+	s2, err = s2.Insert(10, 7, 8, 9)
+	if err != nil {
+		t.Fatalf("insert failed: %v", err)
+	}
+	s2, err = s2.Delete(100, 300)
+	if err != nil {
+		t.Fatalf("delete failed: %v", err)
+	}
+	want := slices.Concat(values[:10], []int{7, 8, 9}, values[10:97], values[397:])
+	if !slices.Equal(s2.Values(), want) {
+		t.Fatalf("unexpected values after edits")
+	}
+	if !slices.Equal(s.Values(), values) {
+		t.Fatalf("original sequence changed")
+	}
+	if v, err := s.At(250); err != nil || v != 250 {
+		t.Fatalf("unexpected original value %d, %v", v, err)
+	}
+}
+
+func TestSeqSliceAndConcat(t *testing.T) {
+	s := Of("a", "b", "c", "d", "e")
+	mid, err := s.Slice(1, 4)
+	if err != nil {
+		t.Fatalf("slice failed: %v", err)
+	}
+	if !slices.Equal(mid.Values(), []string{"b", "c", "d"}) {
+		t.Fatalf("unexpected slice %v", mid.Values())
+	}
+	if empty, err := s.Slice(2, 2); err != nil || empty.Len() != 0 {
+		t.Fatalf("expected empty slice, got %v, %v", empty.Values(), err)
+	}
+	if _, err := s.Slice(3, 2); !errors.Is(err, ErrIndexOutOfBounds) {
+		t.Fatalf("expected ErrIndexOutOfBounds for inverted range, got %v", err)
+	}
+	both := mid.Concat(s).Concat(Seq[string]{})
+	if !slices.Equal(both.Values(), []string{"b", "c", "d", "a", "b", "c", "d", "e"}) {
+		t.Fatalf("unexpected concatenation %v", both.Values())
+	}
+	for i, v := range both.All() {
+		if w, _ := both.At(i); w != v {
+			t.Fatalf("All and At disagree at %d", i)
+		}
+		if i == 2 {
+			break
+		}
+	}
+}
+
+func TestSeqRejectsInvalidIndices(t *testing.T) {
+	s := Of(1, 2, 3)
+	if _, err := s.Set(3, 0); !errors.Is(err, ErrIndexOutOfBounds) {
+		t.Fatalf("expected ErrIndexOutOfBounds for Set, got %v", err)
+	}
+	if _, err := s.Insert(4, 0); !errors.Is(err, ErrIndexOutOfBounds) {
+		t.Fatalf("expected ErrIndexOutOfBounds for Insert, got %v", err)
+	}
+	if _, err := s.Delete(2, 2); !errors.Is(err, ErrIndexOutOfBounds) {
+		t.Fatalf("expected ErrIndexOutOfBounds for Delete, got %v", err)
+	}
+}
@@ -0,0 +1,298 @@
+package btree
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+)
+
+// Codec serializes leaf items and summaries for persistent node storage.
+//
+// Append methods append the encoding to buf and return the extended buffer.
+// Decode methods must not retain data.
+type Codec[I SummarizedItem[S], S any] interface {
+	AppendItems(buf []byte, items []I) ([]byte, error)
+	DecodeItems(data []byte) ([]I, error)
+	AppendSummary(buf []byte, summary S) []byte
+	DecodeSummary(data []byte) (S, error)
+}
+
+// ExtCodec serializes extension values. Codecs used for trees with an
+// extension configured have to implement it in addition to Codec.
+type ExtCodec[E any] interface {
+	AppendExt(buf []byte, ext E) []byte
+	DecodeExt(data []byte) (E, error)
+}
+
+// ErrCorruptStore signals that persisted tree data is malformed.
+var ErrCorruptStore = errors.New("btree: corrupt node store")
+
+// Persistent layout:
+//
+//	magic
+//	leaf blocks      codec-encoded items, one block per leaf
+//	index            uvarint leaf count, then per leaf:
+//	                   uvarint offset, uvarint length, uint32 crc32 of block,
+//	                   uvarint item count, uvarint len + summary,
+//	                   [uvarint len + ext, if an extension is configured]
+//	trailer          uint64 index offset, uint64 index length,
+//	                 uint32 crc32 of index, magic
+//
+// Integers in index and trailer are little-endian.
+const storeMagic = "cords.btree.v1\n\x00"
+
+const trailerSize = 8 + 8 + 4 + len(storeMagic)
+
+// Save writes all items of tree in persistent form to w, from where the tree
+// may be re-opened lazily with Open.
+//
+// Items are re-packed into evenly filled leaves, independent of the shape of
+// tree.
+func Save[I SummarizedItem[S], S, E any](w io.Writer, tree *Tree[I, S, E], codec Codec[I, S]) (err error) {
+	defer catchLoadFailure(&err)
+	if tree == nil || codec == nil {
+		return fmt.Errorf("%w: tree and codec are required", ErrIllegalArguments)
+	}
+	extCodec, err := extCodecFor(tree.cfg, codec)
+	if err != nil {
+		return err
+	}
+	sw := &storeWriter{w: bufio.NewWriter(w)}
+	sw.write([]byte(storeMagic))
+	var entries []byte
+	var leafCount int
+	if !tree.IsEmpty() {
+		sizes, _ := tree.packSizes(int(tree.Len()))
+		group := make([]I, 0, tree.maxItems())
+		var block []byte
+		tree.ForEachItem(func(item I) bool {
+			group = append(group, item)
+			if len(group) < sizes[leafCount] {
+				return true
+			}
+			if block, err = codec.AppendItems(block[:0], group); err != nil {
+				return false
+			}
+			leaf := tree.makeLeaf(group) // aggregates summary and extension
+			entries = binary.AppendUvarint(entries, sw.offset)
+			entries = binary.AppendUvarint(entries, uint64(len(block)))
+			entries = binary.LittleEndian.AppendUint32(entries, crc32.ChecksumIEEE(block))
+			entries = binary.AppendUvarint(entries, uint64(len(group)))
+			entries = appendSized(entries, codec.AppendSummary(nil, leaf.summary))
+			if extCodec != nil {
+				entries = appendSized(entries, extCodec.AppendExt(nil, leaf.ext))
+			}
+			sw.write(block)
+			leafCount++
+			group = group[:0]
+			return sw.err == nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	index := binary.AppendUvarint(nil, uint64(leafCount))
+	index = append(index, entries...)
+	indexOffset := sw.offset
+	sw.write(index)
+	trailer := binary.LittleEndian.AppendUint64(nil, indexOffset)
+	trailer = binary.LittleEndian.AppendUint64(trailer, uint64(len(index)))
+	trailer = binary.LittleEndian.AppendUint32(trailer, crc32.ChecksumIEEE(index))
+	trailer = append(trailer, storeMagic...)
+	sw.write(trailer)
+	if sw.err != nil {
+		return sw.err
+	}
+	return sw.w.Flush()
+}
+
+// Open opens a tree persisted with Save, reading only its index from r.
+//
+// size is the total size of the persisted data. Leaves are read from r when
+// they are first needed and at most resident leaves are cached (see
+// FromStore). r must stay open and unchanged as long as the tree, or any tree
+// derived from it, is in use. cfg must match the configuration of the saved
+// tree.
+func Open[I SummarizedItem[S], S, E any](cfg Config[I, S, E], r io.ReaderAt, size int64,
+	codec Codec[I, S], resident int) (*Tree[I, S, E], error) {
+	//
+	if r == nil || codec == nil {
+		return nil, fmt.Errorf("%w: reader and codec are required", ErrIllegalArguments)
+	}
+	if err := cfg.validate(); err != nil {
+		return nil, err
+	}
+	extCodec, err := extCodecFor(cfg, codec)
+	if err != nil {
+		return nil, err
+	}
+	if size < int64(len(storeMagic)+trailerSize) {
+		return nil, fmt.Errorf("%w: too short", ErrCorruptStore)
+	}
+	trailer := make([]byte, trailerSize)
+	if err := readAt(r, trailer, size-int64(trailerSize)); err != nil {
+		return nil, err
+	}
+	if string(trailer[20:]) != storeMagic {
+		return nil, fmt.Errorf("%w: bad magic", ErrCorruptStore)
+	}
+	indexOffset := binary.LittleEndian.Uint64(trailer[0:])
+	indexLen := binary.LittleEndian.Uint64(trailer[8:])
+	if indexOffset < uint64(len(storeMagic)) || indexOffset+indexLen > uint64(size-int64(trailerSize)) {
+		return nil, fmt.Errorf("%w: index out of range", ErrCorruptStore)
+	}
+	index := make([]byte, indexLen)
+	if err := readAt(r, index, int64(indexOffset)); err != nil {
+		return nil, err
+	}
+	if crc32.ChecksumIEEE(index) != binary.LittleEndian.Uint32(trailer[16:]) {
+		return nil, fmt.Errorf("%w: index checksum mismatch", ErrCorruptStore)
+	}
+	leaves, blocks, err := readIndex(bytes.NewReader(index), indexOffset, codec, extCodec)
+	if err != nil {
+		return nil, err
+	}
+	store := &fileStore[I, S]{r: r, codec: codec, blocks: blocks}
+	return FromStore(cfg, NodeStore[I](store), leaves, resident)
+}
+
+// fileStore is a NodeStore reading leaf blocks persisted by Save.
+type fileStore[I SummarizedItem[S], S any] struct {
+	r      io.ReaderAt
+	codec  Codec[I, S]
+	blocks []blockRef
+}
+
+type blockRef struct {
+	offset, length uint64
+	crc            uint32
+}
+
+// readIndex decodes the leaf index. It returns descriptors for all leaves,
+// together with the location of their blocks, which must end before limit.
+func readIndex[I SummarizedItem[S], S, E any](ir *bytes.Reader, limit uint64,
+	codec Codec[I, S], extCodec ExtCodec[E]) ([]StoredLeaf[S, E], []blockRef, error) {
+	//
+	corrupt := fmt.Errorf("%w: malformed index", ErrCorruptStore)
+	count, err := binary.ReadUvarint(ir)
+	if err != nil || count > uint64(ir.Len()) {
+		return nil, nil, corrupt
+	}
+	leaves := make([]StoredLeaf[S, E], count)
+	blocks := make([]blockRef, count)
+	for i := range leaves {
+		ref := &blocks[i]
+		if ref.offset, err = binary.ReadUvarint(ir); err != nil {
+			return nil, nil, corrupt
+		}
+		if ref.length, err = binary.ReadUvarint(ir); err != nil || ref.offset+ref.length > limit {
+			return nil, nil, corrupt
+		}
+		if err = binary.Read(ir, binary.LittleEndian, &ref.crc); err != nil {
+			return nil, nil, corrupt
+		}
+		items, err := binary.ReadUvarint(ir)
+		if err != nil || items > MaxBase*2 {
+			return nil, nil, corrupt
+		}
+		leaves[i].ID = LeafID(i)
+		leaves[i].Count = int(items)
+		data, err := readSized(ir)
+		if err != nil {
+			return nil, nil, err
+		}
+		if leaves[i].Summary, err = codec.DecodeSummary(data); err != nil {
+			return nil, nil, fmt.Errorf("%w: leaf %d summary: %w", ErrCorruptStore, i, err)
+		}
+		if extCodec == nil {
+			continue
+		}
+		if data, err = readSized(ir); err != nil {
+			return nil, nil, err
+		}
+		if leaves[i].Ext, err = extCodec.DecodeExt(data); err != nil {
+			return nil, nil, fmt.Errorf("%w: leaf %d extension: %w", ErrCorruptStore, i, err)
+		}
+	}
+	if ir.Len() != 0 {
+		return nil, nil, corrupt
+	}
+	return leaves, blocks, nil
+}
+
+// LoadLeaf reads, verifies and decodes the items of one leaf block.
+func (fs *fileStore[I, S]) LoadLeaf(id LeafID) ([]I, error) {
+	if uint64(id) >= uint64(len(fs.blocks)) {
+		return nil, fmt.Errorf("%w: unknown leaf %d", ErrCorruptStore, id)
+	}
+	ref := fs.blocks[id]
+	block := make([]byte, ref.length)
+	if err := readAt(fs.r, block, int64(ref.offset)); err != nil {
+		return nil, err
+	}
+	if crc32.ChecksumIEEE(block) != ref.crc {
+		return nil, fmt.Errorf("%w: checksum mismatch for leaf %d", ErrCorruptStore, id)
+	}
+	return fs.codec.DecodeItems(block)
+}
+
+// storeWriter tracks the write offset and keeps the first write error.
+type storeWriter struct {
+	w      *bufio.Writer
+	offset uint64
+	err    error
+}
+
+func (sw *storeWriter) write(p []byte) {
+	if sw.err != nil {
+		return
+	}
+	_, sw.err = sw.w.Write(p)
+	sw.offset += uint64(len(p))
+}
+
+// readAt fills p from r at offset off. Following io.ReaderAt, a read which fills
+// p completely may report io.EOF, which is not an error here.
+func readAt(r io.ReaderAt, p []byte, off int64) error {
+	n, err := r.ReadAt(p, off)
+	if n == len(p) {
+		return nil
+	}
+	if err == nil || err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+// appendSized appends a uvarint length prefix followed by data.
+func appendSized(buf []byte, data []byte) []byte {
+	buf = binary.AppendUvarint(buf, uint64(len(data)))
+	return append(buf, data...)
+}
+
+// readSized reads data written by appendSized.
+func readSized(r *bytes.Reader) ([]byte, error) {
+	n, err := binary.ReadUvarint(r)
+	if err != nil || n > uint64(r.Len()) {
+		return nil, fmt.Errorf("%w: truncated index", ErrCorruptStore)
+	}
+	data := make([]byte, n)
+	_, _ = io.ReadFull(r, data)
+	return data, nil
+}
+
+func extCodecFor[I SummarizedItem[S], S, E any](cfg Config[I, S, E], codec Codec[I, S]) (ExtCodec[E], error) {
+	if cfg.Extension == nil {
+		return nil, nil
+	}
+	extCodec, ok := codec.(ExtCodec[E])
+	if !ok {
+		return nil, fmt.Errorf("%w: codec cannot persist extension %q", ErrExtensionUnavailable,
+			cfg.Extension.MagicID())
+	}
+	return extCodec, nil
+}
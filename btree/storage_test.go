@@ -0,0 +1,355 @@
+package btree
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+)
+
+// textCodec persists textChunk items for tests.
+type textCodec struct{}
+
+func (textCodec) AppendItems(buf []byte, items []textChunk) ([]byte, error) {
+	for _, item := range items {
+		buf = appendSized(buf, item)
+	}
+	return buf, nil
+}
+
+func (textCodec) DecodeItems(data []byte) ([]textChunk, error) {
+	r := bytes.NewReader(data)
+	var items []textChunk
+	for r.Len() > 0 {
+		item, err := readSized(r)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, textChunk(item))
+	}
+	return items, nil
+}
+
+func (textCodec) AppendSummary(buf []byte, s textSummary) []byte {
+	buf = binary.AppendUvarint(buf, s.Bytes)
+	return binary.AppendUvarint(buf, s.Lines)
+}
+
+func (textCodec) DecodeSummary(data []byte) (textSummary, error) {
+	r := bytes.NewReader(data)
+	var s textSummary
+	var err error
+	if s.Bytes, err = binary.ReadUvarint(r); err != nil {
+		return s, err
+	}
+	s.Lines, err = binary.ReadUvarint(r)
+	return s, err
+}
+
+type textExtCodec struct{ textCodec }
+
+func (textExtCodec) AppendExt(buf []byte, ext uint64) []byte { return binary.AppendUvarint(buf, ext) }
+func (textExtCodec) DecodeExt(data []byte) (uint64, error) {
+	v, n := binary.Uvarint(data)
+	if n <= 0 {
+		return 0, ErrCorruptStore
+	}
+	return v, nil
+}
+
+// countingReader records which offsets have been read.
+type countingReader struct {
+	mu    sync.Mutex
+	data  []byte
+	reads []int64
+}
+
+func (r *countingReader) ReadAt(p []byte, off int64) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.reads = append(r.reads, off)
+	return bytes.NewReader(r.data).ReadAt(p, off)
+}
+
+func (r *countingReader) count() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.reads)
+}
+
+func saveTextTree(t *testing.T, tree *Tree[textChunk, textSummary, NO_EXT]) *countingReader {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := Save(&buf, tree, textCodec{}); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	return &countingReader{data: buf.Bytes()}
+}
+
+func openTextTree(t *testing.T, r *countingReader, resident int) *Tree[textChunk, textSummary, NO_EXT] {
+	t.Helper()
+	cfg := Config[textChunk, textSummary, NO_EXT]{Monoid: textMonoid{}}
+	tree, err := Open(cfg, r, int64(len(r.data)), textCodec{}, resident)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	return tree
+}
+
+func TestSaveOpenRoundTrip(t *testing.T) {
+	for _, n := range []int{0, 1, 13, 500} {
+		orig := buildTextTree(t, n)
+		r := saveTextTree(t, orig)
+		opened := openTextTree(t, r, 4)
+		if err := opened.Check(); err != nil {
+			t.Fatalf("n=%d: opened tree invalid: %v", n, err)
+		}
+		got, want := collectTextItems(opened), collectTextItems(orig)
+		if len(got) != len(want) {
+			t.Fatalf("n=%d: length mismatch %d != %d", n, len(got), len(want))
+		}
+		for i := range want {
+			if got[i] != want[i] {
+				t.Fatalf("n=%d: item mismatch at %d", n, i)
+			}
+		}
+	}
+}
+
+func TestOpenLoadsLeavesLazily(t *testing.T) {
+	orig := buildTextTree(t, 1000)
+	r := saveTextTree(t, orig)
+	tree := openTextTree(t, r, 2)
+	afterOpen := r.count()
+	if tree.Len() != 1000 || tree.Summary() != orig.Summary() {
+		t.Fatalf("unexpected summary for opened tree")
+	}
+	if r.count() != afterOpen {
+		t.Fatalf("summary queries read %d leaves", r.count()-afterOpen)
+	}
+	cursor, err := NewCursor(tree, byteDimension{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := cursor.Seek(1000); err != nil {
+		t.Fatal(err)
+	}
+	if r.count() != afterOpen+1 {
+		t.Fatalf("expected seek to read a single leaf, read %d", r.count()-afterOpen)
+	}
+	reads := r.count()
+	item, err := tree.At(777)
+	if err != nil || string(item) != "777" {
+		t.Fatalf("unexpected item at 777: %q, %v", item, err)
+	}
+	if r.count() != reads+1 {
+		t.Fatalf("expected At to read a single leaf, read %d", r.count()-reads)
+	}
+	_, _ = tree.At(776) // same leaf, resident
+	if r.count() != reads+1 {
+		t.Fatalf("expected resident leaf to be reused")
+	}
+	_, _ = tree.At(0)
+	_, _ = tree.At(500)
+	_, _ = tree.At(777) // evicted with a capacity of 2 leaves
+	if r.count() != reads+4 {
+		t.Fatalf("expected LRU eviction to force a reload, reads=%d", r.count()-reads)
+	}
+}
+
+func TestOpenedTreeSupportsEdits(t *testing.T) {
+	orig := buildTextTree(t, 300)
+	r := saveTextTree(t, orig)
+	tree := openTextTree(t, r, 1)
+	edited, err := tree.InsertAt(150, fromString("X"))
+	if err != nil {
+		t.Fatalf("insert failed: %v", err)
+	}
+	if edited, err = edited.DeleteAt(10); err != nil {
+		t.Fatalf("delete failed: %v", err)
+	}
+	if err := edited.Check(); err != nil {
+		t.Fatalf("edited tree invalid: %v", err)
+	}
+	if got := collectTextItems(edited); got[149] != "X" || got[10] != "11" {
+		t.Fatalf("unexpected edit result")
+	}
+	if got := collectTextItems(tree); len(got) != 300 || got[150] != "150" {
+		t.Fatalf("opened tree changed by edit")
+	}
+}
+
+func TestOpenDetectsCorruption(t *testing.T) {
+	orig := buildTextTree(t, 100)
+	r := saveTextTree(t, orig)
+	r.data[len(storeMagic)+1] ^= 0xff // first leaf block
+	tree := openTextTree(t, r, 1)
+	if _, err := tree.At(0); !errors.Is(err, ErrNodeStore) || !errors.Is(err, ErrCorruptStore) {
+		t.Fatalf("expected corrupt leaf to be reported, got %v", err)
+	}
+	if _, err := tree.At(99); err != nil {
+		t.Fatalf("expected intact leaf to load, got %v", err)
+	}
+	r.data[len(r.data)-trailerSize-1] ^= 0xff // index
+	cfg := Config[textChunk, textSummary, NO_EXT]{Monoid: textMonoid{}}
+	if _, err := Open(cfg, r, int64(len(r.data)), textCodec{}, 1); !errors.Is(err, ErrCorruptStore) {
+		t.Fatalf("expected corrupt index to be reported, got %v", err)
+	}
+	if _, err := Open(cfg, r, 10, textCodec{}, 1); !errors.Is(err, ErrCorruptStore) {
+		t.Fatalf("expected short input to be reported, got %v", err)
+	}
+}
+
+func TestSaveOpenWithExtension(t *testing.T) {
+	cfg := Config[textChunk, textSummary, uint64]{Monoid: textMonoid{}, Extension: countingExt{id: "bytes"}}
+	tree, err := FromItems(cfg, textItems(200))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := Save(&buf, tree, textCodec{}); !errors.Is(err, ErrExtensionUnavailable) {
+		t.Fatalf("expected codec without extension support to be rejected, got %v", err)
+	}
+	if err := Save(&buf, tree, Codec[textChunk, textSummary](textExtCodec{})); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	opened, err := Open(cfg, bytes.NewReader(buf.Bytes()), int64(buf.Len()),
+		Codec[textChunk, textSummary](textExtCodec{}), 1)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	want, _ := tree.Ext()
+	if got, ok := opened.Ext(); !ok || got != want {
+		t.Fatalf("extension mismatch: %d != %d", got, want)
+	}
+}
+
+// failingStore is a NodeStore which cannot load any leaf.
+type failingStore struct{}
+
+func (failingStore) LoadLeaf(id LeafID) ([]textChunk, error) {
+	return nil, fmt.Errorf("leaf %d is gone", id)
+}
+
+func TestFromStoreReportsLoadFailures(t *testing.T) {
+	cfg := Config[textChunk, textSummary, NO_EXT]{Monoid: textMonoid{}}
+	leaves := []StoredLeaf[textSummary, NO_EXT]{
+		{ID: 0, Count: Base, Summary: textSummary{Bytes: Base}},
+		{ID: 1, Count: Base, Summary: textSummary{Bytes: Base}},
+	}
+	tree, err := FromStore(cfg, NodeStore[textChunk](failingStore{}), leaves, 1)
+	if err != nil {
+		t.Fatalf("FromStore failed: %v", err)
+	}
+	if tree.Len() != 2*Base || tree.Summary().Bytes != 2*Base {
+		t.Fatalf("unexpected summary for stored tree")
+	}
+	if _, err := tree.At(0); !errors.Is(err, ErrNodeStore) {
+		t.Fatalf("expected ErrNodeStore, got %v", err)
+	}
+	if _, err := tree.InsertAt(3, fromString("x")); !errors.Is(err, ErrNodeStore) {
+		t.Fatalf("expected ErrNodeStore from edit, got %v", err)
+	}
+	leaves[1].Count = 1
+	if _, err := FromStore(cfg, NodeStore[textChunk](failingStore{}), leaves, 1); !errors.Is(err, ErrIllegalArguments) {
+		t.Fatalf("expected underfull leaf to be rejected, got %v", err)
+	}
+}
+
+// subtreeStore is a NodeStore holding the items of stored subtrees in memory.
+type subtreeStore struct {
+	units [][]textChunk
+	loads *int
+}
+
+func (s subtreeStore) LoadLeaf(id LeafID) ([]textChunk, error) {
+	*s.loads++
+	return s.units[id], nil
+}
+
+func storeSubtrees(t *testing.T, counts []int, height int) (subtreeStore, []StoredLeaf[textSummary, NO_EXT]) {
+	cfg := Config[textChunk, textSummary, NO_EXT]{Monoid: textMonoid{}}
+	store := subtreeStore{loads: new(int)}
+	var leaves []StoredLeaf[textSummary, NO_EXT]
+	items := textItems(1000)
+	start := 0
+	for i, n := range counts {
+		unit := items[start : start+n]
+		tree, err := FromItems(cfg, unit)
+		if err != nil {
+			t.Fatal(err)
+		}
+		store.units = append(store.units, unit)
+		leaves = append(leaves, StoredLeaf[textSummary, NO_EXT]{
+			ID: LeafID(i), Count: n, Height: height, Summary: tree.Summary(),
+		})
+		start += n
+	}
+	return store, leaves
+}
+
+func TestFromStoreLoadsSubtrees(t *testing.T) {
+	cfg := Config[textChunk, textSummary, NO_EXT]{Monoid: textMonoid{}}
+	for _, counts := range [][]int{{13}, {36, 144, 50}, {144}} {
+		store, leaves := storeSubtrees(t, counts, 2)
+		tree, err := FromStore(cfg, NodeStore[textChunk](store), leaves, 1)
+		if err != nil {
+			t.Fatalf("FromStore(%v) failed: %v", counts, err)
+		}
+		total := 0
+		for _, n := range counts {
+			total += n
+		}
+		if tree.Len() != int64(total) || *store.loads != 0 {
+			t.Fatalf("unexpected length %d or loads %d for %v", tree.Len(), *store.loads, counts)
+		}
+		u := MemoryUsage(tree)
+		if u.Leaves != u.LazyLeaves || u.Items != int64(total) {
+			t.Fatalf("unexpected usage of stored subtrees %v: %v", counts, u)
+		}
+		if err := tree.Check(); err != nil {
+			t.Fatalf("tree over stored subtrees %v invalid: %v", counts, err)
+		}
+		if *store.loads != len(counts) {
+			t.Fatalf("expected each subtree to be loaded once, got %d loads", *store.loads)
+		}
+		edited, err := tree.InsertAt(int64(total/2), fromString("X"))
+		if err != nil {
+			t.Fatalf("insert failed: %v", err)
+		}
+		if edited, err = edited.DeleteAt(0); err != nil {
+			t.Fatalf("delete failed: %v", err)
+		}
+		if err := edited.Check(); err != nil {
+			t.Fatalf("edited tree invalid: %v", err)
+		}
+		if got := collectTextItems(edited); len(got) != total || got[total/2-1] != "X" || got[0] != "1" {
+			t.Fatalf("unexpected edit result for %v", counts)
+		}
+	}
+}
+
+func TestFromStoreRejectsInvalidSubtrees(t *testing.T) {
+	cfg := Config[textChunk, textSummary, NO_EXT]{Monoid: textMonoid{}}
+	for _, tc := range []struct {
+		counts []int
+		height int
+	}{
+		{[]int{12}, 2},     // fits into a single leaf
+		{[]int{145}, 2},    // exceeds (2*Base)^2
+		{[]int{36, 35}, 2}, // second subtree under Base^2
+		{[]int{300}, MaxStoredHeight + 1},
+	} {
+		_, leaves := storeSubtrees(t, tc.counts, tc.height)
+		if _, err := FromStore(cfg, NodeStore[textChunk](failingStore{}), leaves, 1); !errors.Is(err, ErrIllegalArguments) {
+			t.Errorf("expected stored subtrees %v of height %d to be rejected, got %v", tc.counts, tc.height, err)
+		}
+	}
+	_, leaves := storeSubtrees(t, []int{36, 6}, 2)
+	leaves[1].Height = 1
+	if _, err := FromStore(cfg, NodeStore[textChunk](failingStore{}), leaves, 1); !errors.Is(err, ErrIllegalArguments) {
+		t.Errorf("expected mixed heights to be rejected, got %v", err)
+	}
+}
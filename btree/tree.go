@@ -71,9 +71,6 @@ func (t *Tree[I, S, E]) Len() int64 {
 	if t == nil || t.root == nil {
 		return 0
 	}
-	assert(t.countItems(t.root) == t.root.Weight(), "btree: weight not set for root node")
-	// todo substitute
-	//return t.countItems(t.root)
 	return t.root.Weight()
 }
 
@@ -94,7 +91,12 @@ func (t *Tree[I, S, E]) Summary() S {
 }
 
 // InsertAt inserts items at an item index and returns a new tree.
-func (t *Tree[I, S, E]) InsertAt(index int64, items ...I) (*Tree[I, S, E], error) {
+//
+// Multiple items are bulk-loaded into a subtree (see FromItems), which is
+// joined into the tree at index. Inserting k items therefore takes
+// O(log n + k) time.
+func (t *Tree[I, S, E]) InsertAt(index int64, items ...I) (_ *Tree[I, S, E], err error) {
+	defer catchLoadFailure(&err)
 	if t == nil {
 		return nil, fmt.Errorf("%w: nil tree", ErrInvalidConfig)
 	}
@@ -106,11 +108,14 @@ func (t *Tree[I, S, E]) InsertAt(index int64, items ...I) (*Tree[I, S, E], error
 		return t, nil
 	}
 	cloned := t.Clone()
-	for i, item := range items {
-		if err := cloned.insertOneAt(index+int64(i), item); err != nil {
+	if len(items) == 1 {
+		if err := cloned.insertOneAt(index, items[0]); err != nil {
 			return nil, err
 		}
+		return cloned, nil
 	}
+	inserted, height := t.buildNodes(items, 1)
+	cloned.splice(index, index, inserted, height)
 	return cloned, nil
 }
 
@@ -119,7 +124,8 @@ func (t *Tree[I, S, E]) InsertAt(index int64, items ...I) (*Tree[I, S, E], error
 // Delete uses recursive path-copy with sibling borrow/merge rebalancing.
 // While delete coverage is broad, unresolved occupancy repair still reports
 // ErrUnimplemented.
-func (t *Tree[I, S, E]) DeleteAt(index int64) (*Tree[I, S, E], error) {
+func (t *Tree[I, S, E]) DeleteAt(index int64) (_ *Tree[I, S, E], err error) {
+	defer catchLoadFailure(&err)
 	if t == nil {
 		return nil, fmt.Errorf("%w: nil tree", ErrInvalidConfig)
 	}
@@ -143,9 +149,10 @@ func (t *Tree[I, S, E]) DeleteAt(index int64) (*Tree[I, S, E], error) {
 
 // DeleteRange removes count items starting at index and returns a new tree.
 //
-// This implementation is intentionally compositional: split at range start,
-// delete from the right fragment, then concat.
-func (t *Tree[I, S, E]) DeleteRange(index, count int64) (*Tree[I, S, E], error) {
+// The tree is split at both ends of the range and the outer parts are joined,
+// which takes O(log n) time independent of count.
+func (t *Tree[I, S, E]) DeleteRange(index, count int64) (_ *Tree[I, S, E], err error) {
+	defer catchLoadFailure(&err)
 	if t == nil {
 		return nil, fmt.Errorf("%w: nil tree", ErrInvalidConfig)
 	}
@@ -159,29 +166,31 @@ func (t *Tree[I, S, E]) DeleteRange(index, count int64) (*Tree[I, S, E], error)
 	if count == 1 {
 		return t.DeleteAt(index)
 	}
-	left, right, err := t.SplitAt(index)
-	if err != nil {
-		return nil, err
-	}
-	trimmed := right
-	for range count {
-		trimmed, err = trimmed.DeleteAt(0)
-		if err != nil {
-			return nil, err
-		}
-	}
-	out, err := left.Concat(trimmed)
-	if err != nil {
-		return nil, err
-	}
-	return out, nil
+	cloned := t.Clone()
+	cloned.splice(index, index+count, nil, 0)
+	return cloned, nil
+}
+
+// splice replaces the items in [from,to) by the subtree n of the given height,
+// which may be nil. n has to satisfy the invariants of a tree with n as root.
+//
+// splice operates in place and callers should use a private clone, though
+// nodes of the original tree are never mutated.
+func (t *Tree[I, S, E]) splice(from, to int64, n treeNode[I, S, E], height int) {
+	assert(from <= to, "splice called with inverted range")
+	left, leftHeight, rest, restHeight := t.splitNodePathCopy(t.root, t.height, from)
+	_, _, right, rightHeight := t.splitNodePathCopy(rest, restHeight, to-from)
+	left, leftHeight = t.joinNodes(left, leftHeight, n, height)
+	t.root, t.height = t.joinNodes(left, leftHeight, right, rightHeight)
+	t.normalizeRoot()
 }
 
 // SplitAt splits a tree at an item index and returns left and right trees.
 //
 // The operation is persistent: only nodes on the split seam are rebuilt,
 // untouched subtrees are shared between input and outputs.
-func (t *Tree[I, S, E]) SplitAt(index int64) (*Tree[I, S, E], *Tree[I, S, E], error) {
+func (t *Tree[I, S, E]) SplitAt(index int64) (_, _ *Tree[I, S, E], err error) {
+	defer catchLoadFailure(&err)
 	if t == nil {
 		return nil, nil, fmt.Errorf("%w: nil tree", ErrInvalidConfig)
 	}
@@ -206,16 +215,9 @@ func (t *Tree[I, S, E]) SplitAt(index int64) (*Tree[I, S, E], *Tree[I, S, E], er
 		}
 		return t, empty, nil
 	}
-	leftRoot, rightRoot, err := t.splitNodePathCopy(t.root, t.height, index)
-	if err != nil {
-		return nil, nil, err
-	}
 	left := t.Clone()
 	right := t.Clone()
-	left.root = leftRoot
-	right.root = rightRoot
-	left.height = left.subtreeHeight(left.root)
-	right.height = right.subtreeHeight(right.root)
+	left.root, left.height, right.root, right.height = t.splitNodePathCopy(t.root, t.height, index)
 	left.normalizeRoot()
 	right.normalizeRoot()
 	return left, right, nil
@@ -226,7 +228,8 @@ func (t *Tree[I, S, E]) SplitAt(index int64) (*Tree[I, S, E], *Tree[I, S, E], er
 // Concat requires extension compatibility: both trees must expose identical
 // extension MagicID values (including both empty). The join is structural and
 // path-copy based; untouched subtrees are shared.
-func (t *Tree[I, S, E]) Concat(other *Tree[I, S, E]) (*Tree[I, S, E], error) {
+func (t *Tree[I, S, E]) Concat(other *Tree[I, S, E]) (_ *Tree[I, S, E], err error) {
+	defer catchLoadFailure(&err)
 	if t == nil || other == nil {
 		return nil, fmt.Errorf("%w: nil tree", ErrInvalidConfig)
 	}
@@ -241,31 +244,37 @@ func (t *Tree[I, S, E]) Concat(other *Tree[I, S, E]) (*Tree[I, S, E], error) {
 	if other.IsEmpty() {
 		return t, nil
 	}
-	left, right, height, err := t.concatNodes(t.root, t.height, other.root, other.height)
-	if err != nil {
-		return nil, err
+	if t.base() != other.base() {
+		return nil, fmt.Errorf("%w: branching factor mismatch (%d != %d)",
+			ErrInvalidConfig, t.base(), other.base())
 	}
 	combined := t.Clone()
-	left = normalizeNode[I, S, E](left)
-	right = normalizeNode[I, S, E](right)
-	if left == nil {
-		combined.root = right
-		combined.height = height
-		combined.normalizeRoot()
-		return combined, nil
-	}
-	if right == nil {
-		combined.root = left
-		combined.height = height
-		combined.normalizeRoot()
-		return combined, nil
-	}
-	combined.root = t.makeInternal(left, right)
-	combined.height = height + 1
+	combined.root, combined.height = t.joinNodes(t.root, t.height, other.root, other.height)
 	combined.normalizeRoot()
 	return combined, nil
 }
 
+// joinNodes concatenates two subtrees, each of which satisfies the invariants
+// of a tree with itself as root, and returns root and height of the result.
+// This is the structural primitive used by Concat and SplitAt.
+func (t *Tree[I, S, E]) joinNodes(left treeNode[I, S, E], leftHeight int,
+	right treeNode[I, S, E], rightHeight int) (treeNode[I, S, E], int) {
+	//
+	l, r, height, err := t.concatNodes(left, leftHeight, right, rightHeight)
+	if err != nil {
+		assert(false, err.Error())
+	}
+	l = normalizeNode[I, S, E](l)
+	r = normalizeNode[I, S, E](r)
+	switch {
+	case l == nil:
+		return r, height
+	case r == nil:
+		return l, height
+	}
+	return t.makeInternal(l, r), height + 1
+}
+
 // concatNodes joins two subtrees that may have different heights.
 //
 // The function returns up to two nodes at the same output height:
@@ -299,7 +308,7 @@ func (t *Tree[I, S, E]) concatNodes(
 	}
 
 	if leftHeight > rightHeight {
-		inner, ok := left.(*innerNode[I, S, E])
+		inner, ok := innerOf[I, S, E](left)
 		assert(ok, "concatNodes expected internal left node at greater height")
 		cloned := t.cloneInner(inner)
 		last := len(cloned.children) - 1
@@ -324,7 +333,7 @@ func (t *Tree[I, S, E]) concatNodes(
 		return cloned, nil, leftHeight, nil
 	}
 
-	inner, ok := right.(*innerNode[I, S, E])
+	inner, ok := innerOf[I, S, E](right)
 	assert(ok, "concatNodes expected internal right node at greater height")
 	cloned := t.cloneInner(inner)
 	first := 0
@@ -352,116 +361,115 @@ func (t *Tree[I, S, E]) concatNodes(
 // concatSameHeight attempts an in-place height-preserving join.
 //
 // When the combined occupancy fits into a single node, it returns that merged
-// node and nil right sibling. Otherwise it returns the original pair unchanged,
-// signaling the caller to keep them as two siblings.
+// node and nil right sibling. Otherwise it returns two siblings: the original
+// pair if both satisfy minimum occupancy, or else a pair with entries
+// redistributed evenly. The latter happens if one of them is the root of a
+// tree being concatenated, which is exempt from minimum occupancy.
 func (t *Tree[I, S, E]) concatSameHeight(left, right treeNode[I, S, E], height int) (treeNode[I, S, E], treeNode[I, S, E], error) {
 	assert(height > 0, "concatSameHeight called with non-positive height")
 	if height == 1 {
-		leftLeaf, lok := left.(*leafNode[I, S, E])
-		rightLeaf, rok := right.(*leafNode[I, S, E])
+		leftLeaf, lok := leafOf[I, S, E](left)
+		rightLeaf, rok := leafOf[I, S, E](right)
 		assert(lok && rok, "concatSameHeight expected leaf nodes at height 1")
 		total := len(leftLeaf.items) + len(rightLeaf.items)
-		if total <= MaxLeafItems {
-			merged := make([]I, 0, total)
-			merged = append(merged, leftLeaf.items...)
-			merged = append(merged, rightLeaf.items...)
+		if total > t.maxItems() && len(leftLeaf.items) >= t.base() && len(rightLeaf.items) >= t.base() {
+			return left, right, nil
+		}
+		merged := make([]I, 0, total)
+		merged = append(merged, leftLeaf.items...)
+		merged = append(merged, rightLeaf.items...)
+		if total <= t.maxItems() {
 			return t.makeLeaf(merged), nil, nil
 		}
-		return left, right, nil
+		return t.makeLeaf(merged[:total/2]), t.makeLeaf(merged[total/2:]), nil
 	}
-	leftInner, lok := left.(*innerNode[I, S, E])
-	rightInner, rok := right.(*innerNode[I, S, E])
+	leftInner, lok := innerOf[I, S, E](left)
+	rightInner, rok := innerOf[I, S, E](right)
 	assert(lok && rok, "concatSameHeight expected internal nodes")
 	total := len(leftInner.children) + len(rightInner.children)
-	if total <= MaxChildren {
-		children := make([]treeNode[I, S, E], 0, total)
-		children = append(children, leftInner.children...)
-		children = append(children, rightInner.children...)
+	if total > t.maxItems() && len(leftInner.children) >= t.base() && len(rightInner.children) >= t.base() {
+		return left, right, nil
+	}
+	children := make([]treeNode[I, S, E], 0, total)
+	children = append(children, leftInner.children...)
+	children = append(children, rightInner.children...)
+	if total <= t.maxItems() {
 		return t.makeInternal(children...), nil, nil
 	}
-	return left, right, nil
+	return t.makeInternal(children[:total/2]...), t.makeInternal(children[total/2:]...), nil
 }
 
-// countItems returns the total number of leaf items under n.
-//
-// TODO: Cached subtree sizes for better performance.
-// This is intentionally recursive and simple for now; we need to replace it
-// with cached subtree sizes later.
+// countItems recounts the total number of leaf items under n, cross-checking
+// the cached weights of inner nodes. It is O(n) and meant for tests only;
+// tree operations use the cached weights.
 func (t *Tree[I, S, E]) countItems(n treeNode[I, S, E]) int64 {
 	if n == nil {
 		return 0
 	}
 	if n.isLeaf() {
 		return n.Weight()
-		//return len(n.(*leafNode[I, S, E]).items)
 	}
 	var total int64 = 0
-	for _, child := range n.(*innerNode[I, S, E]).children {
+	for _, child := range mustInner[I, S, E](n).children {
 		total += t.countItems(child)
-		assert(t.countItems(child) == child.Weight(), "child weight mismatch")
 	}
 	assert(total == n.Weight(), "node weight mismatch")
 	return total
 }
 
-// splitNodePathCopy splits subtree n at index using path-copy semantics.
+// splitNodePathCopy splits subtree n at index using path-copy semantics and
+// returns the roots and heights of both parts.
 //
 // Only nodes on the split seam are rebuilt; untouched siblings are shared.
-// This is the structural primitive used by public SplitAt.
-func (t *Tree[I, S, E]) splitNodePathCopy(n treeNode[I, S, E], height int, index int64) (treeNode[I, S, E], treeNode[I, S, E], error) {
+// Each level contributes the siblings left and right of the seam, which are
+// joined with the parts split off below. As joining rebalances the seam, both
+// parts satisfy all tree invariants. This is the structural primitive used by
+// public SplitAt.
+func (t *Tree[I, S, E]) splitNodePathCopy(n treeNode[I, S, E], height int, index int64) (
+	left treeNode[I, S, E], leftHeight int, right treeNode[I, S, E], rightHeight int) {
+	//
 	if n == nil {
 		assert(index == 0, "splitNodePathCopy called with nil node and non-zero index")
-		return nil, nil, nil
+		return nil, 0, nil, 0
 	}
-	// todo remove
-	//total := t.countItems(n)
 	total := n.Weight()
-	assert(t.countItems(n) == total, "child weight mismatch")
 	assert(index >= 0 && index <= total, "splitNodePathCopy index out of bounds")
 	if index == 0 {
-		return nil, n, nil
+		return nil, 0, n, height
 	}
 	if index == total {
-		return n, nil, nil
+		return n, height, nil, 0
 	}
 	if height == 1 {
-		leaf, ok := n.(*leafNode[I, S, E])
+		leaf, ok := leafOf[I, S, E](n)
 		assert(ok, "splitNodePathCopy expected leaf at height 1")
-		left := t.makeLeaf(leaf.items[:index])
-		right := t.makeLeaf(leaf.items[index:])
-		return left, right, nil
+		return t.makeLeaf(leaf.items[:index]), 1, t.makeLeaf(leaf.items[index:]), 1
 	}
-	inner, ok := n.(*innerNode[I, S, E])
+	inner, ok := innerOf[I, S, E](n)
 	assert(ok, "splitNodePathCopy expected internal node")
 	slot, local, err := t.locateChildForInsert(inner, index)
 	if err != nil {
 		assert(false, err.Error())
 	}
-	childLeft, childRight, err := t.splitNodePathCopy(inner.children[slot], height-1, local)
-	if err != nil {
-		assert(false, err.Error())
-	}
-	var leftNode treeNode[I, S, E]
-	var rightNode treeNode[I, S, E]
-	leftChildren := make([]treeNode[I, S, E], 0, slot+1)
-	leftChildren = append(leftChildren, inner.children[:slot]...)
-	childLeft = normalizeNode[I, S, E](childLeft)
-	if childLeft != nil {
-		leftChildren = append(leftChildren, childLeft)
-	}
-	if len(leftChildren) > 0 {
-		leftNode = t.makeInternal(leftChildren...)
-	}
-	rightChildren := make([]treeNode[I, S, E], 0, len(inner.children)-slot)
-	childRight = normalizeNode[I, S, E](childRight)
-	if childRight != nil {
-		rightChildren = append(rightChildren, childRight)
-	}
-	rightChildren = append(rightChildren, inner.children[slot+1:]...)
-	if len(rightChildren) > 0 {
-		rightNode = t.makeInternal(rightChildren...)
+	childLeft, clHeight, childRight, crHeight := t.splitNodePathCopy(inner.children[slot], height-1, local)
+	siblings, sHeight := t.siblingsNode(inner.children[:slot], height)
+	left, leftHeight = t.joinNodes(siblings, sHeight, childLeft, clHeight)
+	siblings, sHeight = t.siblingsNode(inner.children[slot+1:], height)
+	right, rightHeight = t.joinNodes(childRight, crHeight, siblings, sHeight)
+	return left, leftHeight, right, rightHeight
+}
+
+// siblingsNode groups a run of children of an internal node at the given
+// height into a subtree which is valid as a root: nil for no children, the
+// child itself for a single one, and a new internal node otherwise.
+func (t *Tree[I, S, E]) siblingsNode(children []treeNode[I, S, E], height int) (treeNode[I, S, E], int) {
+	switch len(children) {
+	case 0:
+		return nil, 0
+	case 1:
+		return children[0], height - 1
 	}
-	return leftNode, rightNode, nil
+	return t.makeInternal(children...), height
 }
 
 // subtreeHeight computes height by following the left spine.
@@ -476,7 +484,7 @@ func (t *Tree[I, S, E]) subtreeHeight(n treeNode[I, S, E]) int {
 		if cur.isLeaf() {
 			return h
 		}
-		inner := cur.(*innerNode[I, S, E])
+		inner := mustInner[I, S, E](cur)
 		if len(inner.children) == 0 {
 			return h
 		}
@@ -501,7 +509,7 @@ func (t *Tree[I, S, E]) normalizeRoot() {
 		return
 	}
 	for {
-		inner, ok := t.root.(*innerNode[I, S, E])
+		inner, ok := innerOf[I, S, E](t.root)
 		if !ok {
 			t.height = 1
 			return
@@ -550,12 +558,12 @@ func (t *Tree[I, S, E]) assertDeleteRootNormalized() {
 		return
 	}
 	if t.root.isLeaf() {
-		leaf := t.root.(*leafNode[I, S, E])
+		leaf := mustLeaf[I, S, E](t.root)
 		assert(len(leaf.items) > 0, "delete root normalization: root leaf must be non-empty")
 		assert(t.height == 1, "delete root normalization: root leaf must have height 1")
 		return
 	}
-	inner := t.root.(*innerNode[I, S, E])
+	inner := mustInner[I, S, E](t.root)
 	assert(len(inner.children) > 1, "delete root normalization: root inner must have at least 2 children")
 	assert(t.height >= 2, "delete root normalization: root inner must have height >= 2")
 }
@@ -575,7 +583,7 @@ func (t *Tree[I, S, E]) deleteRecursive(
 	assert(n != nil, "deleteRecursive called with nil node")
 	assert(height > 0, "deleteRecursive called with invalid height")
 	if height == 1 {
-		leaf, ok := n.(*leafNode[I, S, E])
+		leaf, ok := leafOf[I, S, E](n)
 		assert(ok, "deleteRecursive expected leaf at height 1")
 		//if index < 0 || index >= len(leaf.items) {
 		if index < 0 || index >= leaf.Weight() {
@@ -593,7 +601,7 @@ func (t *Tree[I, S, E]) deleteRecursive(
 		return cloned, !isRoot && t.leafUnderflow(cloned, false), nil
 	}
 
-	inner, ok := n.(*innerNode[I, S, E])
+	inner, ok := innerOf[I, S, E](n)
 	assert(ok, "deleteRecursive expected internal node")
 	cloned := t.cloneInner(inner)
 	slot, localIndex, err := t.locateChildForDelete(cloned, index)
@@ -659,7 +667,7 @@ func (t *Tree[I, S, E]) insertRecursive(n treeNode[I, S, E], height int, index i
 	assert(n != nil, "insertRecursive called with nil node")
 	assert(height > 0, "insertRecursive called with invalid height")
 	if height == 1 {
-		leaf, ok := n.(*leafNode[I, S, E])
+		leaf, ok := leafOf[I, S, E](n)
 		assert(ok, "insertRecursive expected leaf at height 1")
 		left, right, err := t.insertIntoLeafLocal(leaf, index, item)
 		assert(err == nil, "insert into leaf failed")
@@ -670,7 +678,7 @@ func (t *Tree[I, S, E]) insertRecursive(n treeNode[I, S, E], height int, index i
 		return left, normalizeNode[I, S, E](right), nil
 	}
 
-	inner, ok := n.(*innerNode[I, S, E])
+	inner, ok := innerOf[I, S, E](n)
 	assert(ok, "insertRecursive expected internal node")
 	cloned := t.cloneInner(inner)
 	slot, localIndex, err := t.locateChildForInsert(cloned, index)
@@ -716,10 +724,7 @@ func (t *Tree[I, S, E]) locateChildForInsert(inner *innerNode[I, S, E], index in
 	assert(index >= 0, "locateChildForInsert called with negative index")
 	remaining := index
 	for i, child := range inner.children {
-		// todo substitute
-		//childItems := t.countItems(child)
 		childItems := child.Weight()
-		assert(childItems == t.countItems(child), "child weight mismatch")
 		if remaining <= childItems {
 			return i, remaining, nil
 		}
@@ -743,10 +748,7 @@ func (t *Tree[I, S, E]) locateChildForDelete(inner *innerNode[I, S, E], index in
 	}
 	remaining := index
 	for i, child := range inner.children {
-		// todo substitute
-		//childItems := t.countItems(child)
 		childItems := child.Weight()
-		assert(childItems == t.countItems(child), "child weight mismatch")
 		if remaining < childItems {
 			return i, remaining, nil
 		}
@@ -799,7 +801,7 @@ func (t *Tree[I, S, E]) applyRebalancePolicy(
 }
 
 func (t *Tree[I, S, E]) rebalanceLeafChild(parent *innerNode[I, S, E], slot int) bool {
-	child, ok := parent.children[slot].(*leafNode[I, S, E])
+	child, ok := leafOf[I, S, E](parent.children[slot])
 	assert(ok, "rebalanceLeafChild expected leaf child")
 	if !t.leafUnderflow(child, false) {
 		return true
@@ -807,9 +809,9 @@ func (t *Tree[I, S, E]) rebalanceLeafChild(parent *innerNode[I, S, E], slot int)
 	return t.applyRebalancePolicy(
 		parent, slot,
 		func() bool {
-			left, lok := parent.children[slot-1].(*leafNode[I, S, E])
+			left, lok := leafOf[I, S, E](parent.children[slot-1])
 			assert(lok, "rebalanceLeafChild expected leaf left sibling")
-			if len(left.items) <= Base {
+			if len(left.items) <= t.base() {
 				return false
 			}
 			leftClone := t.cloneLeaf(left)
@@ -824,9 +826,9 @@ func (t *Tree[I, S, E]) rebalanceLeafChild(parent *innerNode[I, S, E], slot int)
 			return true
 		},
 		func() bool {
-			right, rok := parent.children[slot+1].(*leafNode[I, S, E])
+			right, rok := leafOf[I, S, E](parent.children[slot+1])
 			assert(rok, "rebalanceLeafChild expected leaf right sibling")
-			if len(right.items) <= Base {
+			if len(right.items) <= t.base() {
 				return false
 			}
 			rightClone := t.cloneLeaf(right)
@@ -841,7 +843,7 @@ func (t *Tree[I, S, E]) rebalanceLeafChild(parent *innerNode[I, S, E], slot int)
 			return true
 		},
 		func() bool {
-			left, lok := parent.children[slot-1].(*leafNode[I, S, E])
+			left, lok := leafOf[I, S, E](parent.children[slot-1])
 			assert(lok, "rebalanceLeafChild expected leaf left sibling for merge")
 			merged := make([]I, 0, len(left.items)+len(child.items))
 			merged = append(merged, left.items...)
@@ -851,7 +853,7 @@ func (t *Tree[I, S, E]) rebalanceLeafChild(parent *innerNode[I, S, E], slot int)
 			return true
 		},
 		func() bool {
-			right, rok := parent.children[slot+1].(*leafNode[I, S, E])
+			right, rok := leafOf[I, S, E](parent.children[slot+1])
 			assert(rok, "rebalanceLeafChild expected leaf right sibling for merge")
 			merged := make([]I, 0, len(child.items)+len(right.items))
 			merged = append(merged, child.items...)
@@ -870,7 +872,7 @@ func (t *Tree[I, S, E]) rebalanceLeafChild(parent *innerNode[I, S, E], slot int)
 //
 // TODO refactor this (ugly)
 func (t *Tree[I, S, E]) rebalanceInnerChild(parent *innerNode[I, S, E], slot int) bool {
-	child, ok := parent.children[slot].(*innerNode[I, S, E])
+	child, ok := innerOf[I, S, E](parent.children[slot])
 	assert(ok, "rebalanceInnerChild expected internal child")
 	if !t.innerUnderflow(child, false) {
 		return true
@@ -878,9 +880,9 @@ func (t *Tree[I, S, E]) rebalanceInnerChild(parent *innerNode[I, S, E], slot int
 	return t.applyRebalancePolicy(
 		parent, slot,
 		func() bool {
-			left, lok := parent.children[slot-1].(*innerNode[I, S, E])
+			left, lok := innerOf[I, S, E](parent.children[slot-1])
 			assert(lok, "rebalanceInnerChild expected internal left sibling")
-			if len(left.children) <= Base {
+			if len(left.children) <= t.base() {
 				return false
 			}
 			leftClone := t.cloneInner(left)
@@ -892,9 +894,9 @@ func (t *Tree[I, S, E]) rebalanceInnerChild(parent *innerNode[I, S, E], slot int
 			return true
 		},
 		func() bool {
-			right, rok := parent.children[slot+1].(*innerNode[I, S, E])
+			right, rok := innerOf[I, S, E](parent.children[slot+1])
 			assert(rok, "rebalanceInnerChild expected internal right sibling")
-			if len(right.children) <= Base {
+			if len(right.children) <= t.base() {
 				return false
 			}
 			rightClone := t.cloneInner(right)
@@ -906,7 +908,7 @@ func (t *Tree[I, S, E]) rebalanceInnerChild(parent *innerNode[I, S, E], slot int
 			return true
 		},
 		func() bool {
-			left, lok := parent.children[slot-1].(*innerNode[I, S, E])
+			left, lok := innerOf[I, S, E](parent.children[slot-1])
 			assert(lok, "rebalanceInnerChild expected internal left sibling for merge")
 			mergedChildren := make([]treeNode[I, S, E], 0, len(left.children)+len(child.children))
 			mergedChildren = append(mergedChildren, left.children...)
@@ -916,7 +918,7 @@ func (t *Tree[I, S, E]) rebalanceInnerChild(parent *innerNode[I, S, E], slot int
 			return true
 		},
 		func() bool {
-			right, rok := parent.children[slot+1].(*innerNode[I, S, E])
+			right, rok := innerOf[I, S, E](parent.children[slot+1])
 			assert(rok, "rebalanceInnerChild expected internal right sibling for merge")
 			mergedChildren := make([]treeNode[I, S, E], 0, len(child.children)+len(right.children))
 			mergedChildren = append(mergedChildren, child.children...)
@@ -934,7 +936,7 @@ func (t *Tree[I, S, E]) rebalanceInnerChild(parent *innerNode[I, S, E], slot int
 func (t *Tree[I, S, E]) splitInner(inner *innerNode[I, S, E]) (*innerNode[I, S, E], *innerNode[I, S, E], error) {
 	assert(inner != nil, "splitInner called with nil inner node")
 	n := len(inner.children)
-	maxChildren := MaxChildren
+	maxChildren := t.maxItems()
 	if n <= maxChildren {
 		return t.cloneInner(inner), nil, nil
 	}
@@ -944,7 +946,7 @@ func (t *Tree[I, S, E]) splitInner(inner *innerNode[I, S, E]) (*innerNode[I, S,
 	rightChildren := append([]treeNode[I, S, E](nil), inner.children[mid:]...)
 	left := t.makeInternal(leftChildren...)
 	right := t.makeInternal(rightChildren...)
-	assert(len(left.children) >= Base && len(right.children) >= Base,
+	assert(len(left.children) >= t.base() && len(right.children) >= t.base(),
 		"splitInner violates internal occupancy bounds")
 	return left, right, nil
 }
@@ -960,6 +962,10 @@ func normalizeNode[I SummarizedItem[S], S, E any](n treeNode[I, S, E]) treeNode[
 		if v == nil {
 			return nil
 		}
+	case *lazyNode[I, S, E]:
+		if v == nil {
+			return nil
+		}
 	case *innerNode[I, S, E]:
 		if v == nil {
 			return nil
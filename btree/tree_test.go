@@ -2,6 +2,7 @@ package btree
 
 import (
 	"errors"
+	"strings"
 	"strconv"
 	"testing"
 
@@ -108,13 +109,13 @@ func collectTextItems[E any](tree *Tree[textChunk, textSummary, E]) []string {
 	var walk func(treeNode[textChunk, textSummary, E])
 	walk = func(n treeNode[textChunk, textSummary, E]) {
 		if n.isLeaf() {
-			leaf := n.(*leafNode[textChunk, textSummary, E])
+			leaf := mustLeaf[textChunk, textSummary, E](n)
 			for _, item := range leaf.items {
 				out = append(out, string(item))
 			}
 			return
 		}
-		inner := n.(*innerNode[textChunk, textSummary, E])
+		inner := mustInner[textChunk, textSummary, E](n)
 		for _, child := range inner.children {
 			walk(child)
 		}
@@ -795,6 +796,76 @@ func TestDeleteRangeKeepsOrderAndPersistence(t *testing.T) {
 	}
 }
 
+func TestDeleteRangeLargeRangesSplitAndJoin(t *testing.T) {
+	cfg := Config[textChunk, textSummary, NO_EXT]{Monoid: textMonoid{}}
+	base, err := FromItems(cfg, textItems(2000))
+	if err != nil {
+		t.Fatalf("bulk load failed: %v", err)
+	}
+	model := collectTextItems(base)
+	for _, r := range [][2]int64{{0, 2000}, {0, 1999}, {1, 1999}, {17, 1500}, {1500, 400}, {990, 20}} {
+		deleted, err := base.DeleteRange(r[0], r[1])
+		if err != nil {
+			t.Fatalf("DeleteRange(%d, %d) failed: %v", r[0], r[1], err)
+		}
+		if err := deleted.Check(); err != nil {
+			t.Fatalf("DeleteRange(%d, %d) broke invariants: %v", r[0], r[1], err)
+		}
+		want := append(append([]string{}, model[:r[0]]...), model[r[0]+r[1]:]...)
+		got := collectTextItems(deleted)
+		if strings.Join(got, ",") != strings.Join(want, ",") {
+			t.Fatalf("DeleteRange(%d, %d) produced wrong items", r[0], r[1])
+		}
+	}
+	if got := collectTextItems(base); len(got) != 2000 {
+		t.Fatalf("base tree changed unexpectedly")
+	}
+	// untouched subtrees left of the range are shared
+	deleted, _ := base.DeleteRange(1500, 400)
+	leftmost := func(n treeNode[textChunk, textSummary, NO_EXT]) treeNode[textChunk, textSummary, NO_EXT] {
+		for !n.isLeaf() {
+			n = n.(*innerNode[textChunk, textSummary, NO_EXT]).children[0]
+		}
+		return n
+	}
+	if leftmost(deleted.root) != leftmost(base.root) {
+		t.Fatalf("expected leftmost leaf to be shared after range delete")
+	}
+}
+
+func TestInsertAtManyItemsJoinsSubtree(t *testing.T) {
+	for _, base := range []int{MinBase, Base} {
+		cfg := Config[textChunk, textSummary, NO_EXT]{Monoid: textMonoid{}, Base: base}
+		orig, err := FromItems(cfg, textItems(300))
+		if err != nil {
+			t.Fatalf("bulk load failed: %v", err)
+		}
+		model := collectTextItems(orig)
+		for _, k := range []int{2, base, 5 * base, 700} {
+			items := make([]textChunk, k)
+			for i := range items {
+				items[i] = fromString("x" + strconv.Itoa(i))
+			}
+			for _, at := range []int64{0, 1, 150, 299, 300} {
+				tree, err := orig.InsertAt(at, items...)
+				if err != nil {
+					t.Fatalf("InsertAt(%d, %d items) failed: %v", at, k, err)
+				}
+				if err := tree.Check(); err != nil {
+					t.Fatalf("InsertAt(%d, %d items) broke invariants: %v", at, k, err)
+				}
+				want := append(append(append([]string{}, model[:at]...), collectStrings(items)...), model[at:]...)
+				if strings.Join(collectTextItems(tree), ",") != strings.Join(want, ",") {
+					t.Fatalf("InsertAt(%d, %d items) produced wrong items", at, k)
+				}
+			}
+		}
+		if got := collectTextItems(orig); strings.Join(got, ",") != strings.Join(model, ",") {
+			t.Fatalf("original tree changed unexpectedly")
+		}
+	}
+}
+
 func TestDeleteRangeSingleEqualsDeleteAt(t *testing.T) {
 	base, err := New[textChunk, textSummary](Config[textChunk, textSummary, NO_EXT]{
 		Monoid: textMonoid{},
@@ -1204,12 +1275,15 @@ func TestSplitAtSharesUntouchedSubtree(t *testing.T) {
 		}
 	}
 	root, ok := tree.root.(*innerNode[textChunk, textSummary, NO_EXT])
-	if !ok || len(root.children) < 2 {
-		t.Fatalf("expected an internal root with at least 2 children")
+	if !ok || len(root.children) < 3 {
+		t.Fatalf("expected an internal root with at least 3 children")
 	}
+	// Force the split into the 3rd root child. The split-off fragment is
+	// joined with the 2nd child, but the 1st one stays untouched.
+	prefix := root.children[0].Weight() + root.children[1].Weight()
 	// todo remove this
-	inx := tree.countItems(root.children[0]) + 1 // force split into 2nd root child
-	splitIndex := root.children[0].Weight() + 1  // force split into 2nd root child
+	inx := tree.countItems(root.children[0]) + tree.countItems(root.children[1]) + 1
+	splitIndex := prefix + 1
 	assert(inx == splitIndex, "countItems not replaced successfully")
 	left, _, err := tree.SplitAt(splitIndex)
 	if err != nil {
@@ -1224,6 +1298,90 @@ func TestSplitAtSharesUntouchedSubtree(t *testing.T) {
 	}
 }
 
+func TestSplitAtRebalancesSeam(t *testing.T) {
+	tree, err := New[textChunk, textSummary](Config[textChunk, textSummary, NO_EXT]{
+		Monoid: textMonoid{},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for i := range 20 {
+		tree, err = tree.InsertAt(tree.Len(), fromString(strconv.Itoa(i)))
+		if err != nil {
+			t.Fatalf("insert %d failed: %v", i, err)
+		}
+	}
+	// Splitting off a single item of a root child leaves a fragment smaller
+	// than Base, which has to be joined with its neighbour.
+	for i := range tree.Len() + 1 {
+		left, right, err := tree.SplitAt(i)
+		if err != nil {
+			t.Fatalf("split at %d failed: %v", i, err)
+		}
+		if err := left.Check(); err != nil {
+			t.Fatalf("left part of split at %d invalid: %v", i, err)
+		}
+		if err := right.Check(); err != nil {
+			t.Fatalf("right part of split at %d invalid: %v", i, err)
+		}
+		if left.Len()+right.Len() != tree.Len() {
+			t.Fatalf("split at %d lost items", i)
+		}
+	}
+}
+
+func TestConcatRebalancesSeam(t *testing.T) {
+	build := func(n, start int) *Tree[textChunk, textSummary, NO_EXT] {
+		tree, err := New[textChunk, textSummary](Config[textChunk, textSummary, NO_EXT]{
+			Monoid: textMonoid{},
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		for i := range n {
+			tree, err = tree.InsertAt(tree.Len(), fromString(strconv.Itoa(start+i)))
+			if err != nil {
+				t.Fatalf("insert failed: %v", err)
+			}
+		}
+		return tree
+	}
+	// A full leaf root cannot absorb a small one, so the entries of both
+	// roots have to be redistributed across two leaves.
+	full := build(2*Base, 0)
+	for n := 1; n < Base; n++ {
+		small := build(n, 1000)
+		for _, pair := range [][2]*Tree[textChunk, textSummary, NO_EXT]{{small, full}, {full, small}} {
+			joined, err := pair[0].Concat(pair[1])
+			if err != nil {
+				t.Fatalf("concat failed: %v", err)
+			}
+			if err := joined.Check(); err != nil {
+				t.Fatalf("concat with %d-item tree invalid: %v", n, err)
+			}
+			if joined.Len() != full.Len()+int64(n) {
+				t.Fatalf("concat with %d-item tree lost items", n)
+			}
+		}
+	}
+}
+
+func TestSplitAtCopiesOnlySeam(t *testing.T) {
+	tree := buildTreeWithRootChildren(t, 0, 3)
+	for _, i := range []int64{1, Base - 1, tree.Len() / 3, tree.Len() / 2, tree.Len() - 1} {
+		left, right, err := tree.SplitAt(i)
+		if err != nil {
+			t.Fatalf("split at %d failed: %v", i, err)
+		}
+		// Both parts copy at most the nodes along the seam and their
+		// neighbours used for rebalancing; everything else is shared.
+		fresh := MemoryUsage(tree, left, right).Nodes - MemoryUsage(tree).Nodes
+		if limit := int64(4 * tree.Height()); fresh > limit {
+			t.Fatalf("split at %d allocated %d nodes, expected at most %d", i, fresh, limit)
+		}
+	}
+}
+
 func buildTreeWithRootChildren(t *testing.T, startValue int, minRootChildren int) *Tree[textChunk, textSummary, NO_EXT] {
 	t.Helper()
 	tree, err := New[textChunk, textSummary](Config[textChunk, textSummary, NO_EXT]{
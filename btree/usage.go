@@ -0,0 +1,97 @@
+package btree
+
+import (
+	"fmt"
+	"unsafe"
+)
+
+// PayloadSizer is implemented by items which can report the number of bytes
+// of payload they carry, e.g. text chunks, which hold fewer bytes of text than
+// their fixed-size storage provides.
+type PayloadSizer interface {
+	PayloadSize() int64
+}
+
+// Usage reports the memory held by a set of trees. Nodes shared between the
+// trees are counted once.
+//
+// Byte counts are estimates derived from the in-memory layout of nodes and
+// items. Memory referenced from items or summaries, like slices or strings, is
+// not included.
+type Usage struct {
+	Trees      int   // number of non-empty trees
+	Nodes      int64 // distinct nodes, inner nodes and leaves
+	Leaves     int64 // distinct leaves, including leaves not loaded
+	LazyLeaves int64 // distinct leaves whose items live in a node store only
+	Items      int64 // items of distinct leaves, including leaves not loaded
+	Payload    int64 // payload bytes of resident items
+	Bytes      int64 // estimated bytes of nodes, including item storage
+}
+
+// Overhead returns the estimated number of bytes not taken by item payload:
+// node headers, summaries, child pointers and unused item storage.
+func (u Usage) Overhead() int64 {
+	return u.Bytes - u.Payload
+}
+
+func (u Usage) String() string {
+	return fmt.Sprintf("%d trees: %d nodes (%d leaves, %d lazy), %d items, %d bytes (%d payload, %d overhead)",
+		u.Trees, u.Nodes, u.Leaves, u.LazyLeaves, u.Items, u.Bytes, u.Payload, u.Overhead())
+}
+
+// MemoryUsage reports the memory held by trees, counting every node reachable
+// from more than one of the trees only once. It does not load leaves from a
+// node store.
+//
+// The difference between MemoryUsage of a set of versions with and without a
+// single version is the memory which would be freed by dropping that version.
+func MemoryUsage[I SummarizedItem[S], S, E any](trees ...*Tree[I, S, E]) Usage {
+	var u Usage
+	seen := make(map[treeNode[I, S, E]]struct{})
+	for _, tree := range trees {
+		if tree.IsEmpty() {
+			continue
+		}
+		u.Trees++
+		addNodeUsage(&u, tree.root, seen)
+	}
+	return u
+}
+
+func addNodeUsage[I SummarizedItem[S], S, E any](u *Usage, n treeNode[I, S, E],
+	seen map[treeNode[I, S, E]]struct{}) {
+	//
+	assert(n != nil, "addNodeUsage called with nil node")
+	if _, ok := seen[n]; ok {
+		return
+	}
+	seen[n] = struct{}{}
+	u.Nodes++
+	switch node := n.(type) {
+	case *lazyNode[I, S, E]:
+		leaves := node.src.shell.storedLeaves(node.count, int(node.height), node.root)
+		u.Leaves += leaves
+		u.LazyLeaves += leaves
+		u.Items += node.Weight()
+		u.Bytes += int64(unsafe.Sizeof(*node))
+	case *leafNode[I, S, E]:
+		var item I
+		u.Leaves++
+		u.Items += int64(len(node.items))
+		u.Bytes += int64(unsafe.Sizeof(*node)) + int64(cap(node.itemStore))*int64(unsafe.Sizeof(item))
+		for _, item := range node.items {
+			if sizer, ok := any(item).(PayloadSizer); ok {
+				u.Payload += sizer.PayloadSize()
+			} else {
+				u.Payload += int64(unsafe.Sizeof(item))
+			}
+		}
+	case *innerNode[I, S, E]:
+		u.Bytes += int64(unsafe.Sizeof(*node)) + int64(cap(node.childStore))*int64(unsafe.Sizeof(n))
+		for _, child := range node.children {
+			addNodeUsage(u, child, seen)
+		}
+	default:
+		assert(false, "addNodeUsage: unknown node type")
+	}
+}
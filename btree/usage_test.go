@@ -0,0 +1,40 @@
+package btree
+
+import (
+	"testing"
+	"unsafe"
+)
+
+func TestMemoryUsageCountsSharedNodesOnce(t *testing.T) {
+	v1 := buildTextTree(t, 500)
+	v2, err := v1.InsertAt(250, fromString("x"))
+	if err != nil {
+		t.Fatalf("insert failed: %v", err)
+	}
+	u1 := MemoryUsage(v1)
+	if u1.Trees != 1 || u1.Items != 500 || u1.Leaves == 0 || u1.Nodes <= u1.Leaves {
+		t.Fatalf("unexpected usage of single tree: %v", u1)
+	}
+	var item textChunk
+	if u1.Payload != 500*int64(unsafe.Sizeof(item)) || u1.Overhead() <= 0 {
+		t.Errorf("expected payload of inline items and positive overhead: %v", u1)
+	}
+	if again := MemoryUsage(v1, v1, nil); again.Nodes != u1.Nodes || again.Bytes != u1.Bytes || again.Trees != 2 {
+		t.Errorf("repeated tree should not add nodes: %v vs %v", again, u1)
+	}
+	both := MemoryUsage(v1, v2)
+	if both.Nodes != u1.Nodes+int64(v2.Height()) {
+		t.Errorf("second version should add only its copied path of %d nodes: %v vs %v",
+			v2.Height(), both, u1)
+	}
+	if both.Bytes <= u1.Bytes || both.Bytes >= 2*u1.Bytes {
+		t.Errorf("expected shared bytes to be counted once: %v", both)
+	}
+}
+
+func TestMemoryUsageEmpty(t *testing.T) {
+	empty, _ := New(Config[textChunk, textSummary, NO_EXT]{Monoid: textMonoid{}})
+	if u := MemoryUsage(empty); u != (Usage{}) {
+		t.Errorf("expected zero usage for empty tree, got %v", u)
+	}
+}
@@ -0,0 +1,142 @@
+package chunk
+
+import "math/bits"
+
+// Bitmap indexes byte-local properties inside a chunk.
+//
+// Bit i corresponds to byte offset i in chunk-local coordinates. A bitmap
+// consists of Words 64-bit words, bit i living in word i/64.
+type Bitmap [Words]uint64
+
+const (
+	// MaxBase is the maximum chunk payload length in bytes.
+	MaxBase = 64 * Words
+	// MinBase is the minimum non-root occupancy target used by tree policies.
+	MinBase = MaxBase / 2
+)
+
+// Has reports whether bit offset is set.
+func (b Bitmap) Has(offset int) bool {
+	if offset < 0 || offset >= MaxBase {
+		return false
+	}
+	return b[offset/64]&(1<<uint(offset%64)) != 0
+}
+
+// IsZero reports whether no bit is set.
+func (b Bitmap) IsZero() bool {
+	return b == Bitmap{}
+}
+
+// Count returns the number of set bits.
+func (b Bitmap) Count() int {
+	n := 0
+	for _, w := range b {
+		n += bits.OnesCount64(w)
+	}
+	return n
+}
+
+// Rank returns the number of set bits below offset.
+func (b Bitmap) Rank(offset int) int {
+	return b.And(prefixMask(offset)).Count()
+}
+
+// Next returns the lowest set bit at or above offset, or -1 if there is none.
+func (b Bitmap) Next(offset int) int {
+	offset = max(offset, 0)
+	for i := offset / 64; i < Words; i++ {
+		w := b[i]
+		if i == offset/64 {
+			w &^= (1 << uint(offset%64)) - 1
+		}
+		if w != 0 {
+			return 64*i + bits.TrailingZeros64(w)
+		}
+	}
+	return -1
+}
+
+// And returns the intersection of b and other.
+func (b Bitmap) And(other Bitmap) Bitmap {
+	for i := range b {
+		b[i] &= other[i]
+	}
+	return b
+}
+
+// AndNot returns b with all bits of other cleared.
+func (b Bitmap) AndNot(other Bitmap) Bitmap {
+	for i := range b {
+		b[i] &^= other[i]
+	}
+	return b
+}
+
+// Or returns the union of b and other.
+func (b Bitmap) Or(other Bitmap) Bitmap {
+	for i := range b {
+		b[i] |= other[i]
+	}
+	return b
+}
+
+// ShiftUp moves all bits n positions towards higher offsets, dropping bits
+// shifted beyond MaxBase.
+func (b Bitmap) ShiftUp(n int) Bitmap {
+	var out Bitmap
+	words, s := n/64, uint(n%64)
+	for i := Words - 1; i >= words; i-- {
+		out[i] = b[i-words] << s
+		if s > 0 && i-words > 0 {
+			out[i] |= b[i-words-1] >> (64 - s)
+		}
+	}
+	return out
+}
+
+// ShiftDown moves all bits n positions towards lower offsets, dropping bits
+// shifted below 0.
+func (b Bitmap) ShiftDown(n int) Bitmap {
+	var out Bitmap
+	words, s := n/64, uint(n%64)
+	for i := 0; i+words < Words; i++ {
+		out[i] = b[i+words] >> s
+		if s > 0 && i+words+1 < Words {
+			out[i] |= b[i+words+1] << (64 - s)
+		}
+	}
+	return out
+}
+
+// --- Bitmap helpers --------------------------------------------------------
+
+// set sets bit offset in place. offset must be in [0,MaxBase).
+func (b *Bitmap) set(offset int) {
+	b[offset/64] |= 1 << uint(offset%64)
+}
+
+func bit(offset int) Bitmap {
+	var b Bitmap
+	if offset >= 0 && offset < MaxBase {
+		b[offset/64] = 1 << uint(offset%64)
+	}
+	return b
+}
+
+func prefixMask(offset int) Bitmap {
+	var b Bitmap
+	for i := range b {
+		switch lo := 64 * i; {
+		case offset >= lo+64:
+			b[i] = ^uint64(0)
+		case offset > lo:
+			b[i] = (1 << uint(offset-lo)) - 1
+		}
+	}
+	return b
+}
+
+func rangeMask(start, end int) Bitmap {
+	return prefixMask(end).AndNot(prefixMask(start))
+}
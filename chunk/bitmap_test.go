@@ -0,0 +1,63 @@
+package chunk
+
+import (
+	"math/rand/v2"
+	"testing"
+)
+
+func bitmapOf(set []bool) Bitmap {
+	var b Bitmap
+	for i, ok := range set {
+		if ok {
+			b = b.Or(bit(i))
+		}
+	}
+	return b
+}
+
+func TestBitmapAgainstBoolModel(t *testing.T) {
+	rnd := rand.New(rand.NewPCG(38, 1))
+	for range 200 {
+		set := make([]bool, MaxBase)
+		for i := range set {
+			set[i] = rnd.IntN(3) == 0
+		}
+		b := bitmapOf(set)
+		n := rnd.IntN(MaxBase + 1)
+		up, down := make([]bool, MaxBase), make([]bool, MaxBase)
+		copy(up[n:], set)
+		copy(down, set[n:])
+		if b.ShiftUp(n) != bitmapOf(up) {
+			t.Fatalf("ShiftUp(%d) mismatch", n)
+		}
+		if b.ShiftDown(n) != bitmapOf(down) {
+			t.Fatalf("ShiftDown(%d) mismatch", n)
+		}
+		rank, next := 0, -1
+		for i := 0; i < n; i++ {
+			if set[i] {
+				rank++
+			}
+		}
+		for i := n; i < MaxBase; i++ {
+			if set[i] {
+				next = i
+				break
+			}
+		}
+		if b.Rank(n) != rank || b.Next(n) != next {
+			t.Fatalf("Rank/Next(%d) = %d/%d, want %d/%d", n, b.Rank(n), b.Next(n), rank, next)
+		}
+		for i, ok := range set {
+			if b.Has(i) != ok {
+				t.Fatalf("Has(%d) = %v, want %v", i, !ok, ok)
+			}
+		}
+	}
+	if !(Bitmap{}).IsZero() || bit(MaxBase-1).IsZero() || !bit(MaxBase).IsZero() {
+		t.Fatalf("unexpected IsZero results")
+	}
+	if rangeMask(3, MaxBase).Count() != MaxBase-3 {
+		t.Fatalf("unexpected range mask population")
+	}
+}
@@ -4,32 +4,26 @@ import (
 	"unicode/utf8"
 )
 
-// Bitmap indexes byte-local properties inside a chunk.
-//
-// Bit i corresponds to byte offset i in chunk-local coordinates.
-type Bitmap = uint64
-
-const (
-	// MaxBase is the maximum chunk payload length in bytes.
-	MaxBase = 64
-	// MinBase is the minimum non-root occupancy target used by tree policies.
-	MinBase = MaxBase / 2
-)
-
 // Chunk stores text and bitmap indexes for fast local coordinate math.
 //
 // The chunk is immutable by convention: editing operations return a new Chunk.
 type Chunk struct {
 	chars    Bitmap
 	newlines Bitmap
+	tabs     Bitmap
+	spaces   Bitmap // ASCII whitespace
+	words    Bitmap // word starts: non-whitespace bytes after whitespace or at offset 0
 	text     [MaxBase]byte
-	n        uint8
+	n        uint16
 }
 
 // ChunkSlice is a lightweight view over a chunk range with shifted bitmaps.
 type ChunkSlice struct {
 	chars    Bitmap
 	newlines Bitmap
+	tabs     Bitmap
+	spaces   Bitmap
+	words    Bitmap
 	text     []byte
 }
 
@@ -37,24 +31,17 @@ type ChunkSlice struct {
 //
 // Returns an error if the text is not valid UTF-8 or exceeds MaxBase bytes.
 func New(text string) (Chunk, error) {
-	if !utf8.ValidString(text) {
-		return Chunk{}, ErrInvalidUTF8
-	}
 	if len(text) > MaxBase {
+		if !utf8.ValidString(text) {
+			return Chunk{}, ErrInvalidUTF8
+		}
 		return Chunk{}, ErrChunkTooLarge
 	}
 	var c Chunk
 	copy(c.text[:], text)
-	c.n = uint8(len(text))
-	// Byte-local ascii properties.
-	for i := 0; i < len(text); i++ {
-		if text[i] == '\n' {
-			c.newlines |= bit(i)
-		}
-	}
-	// Rune-local boundaries.
-	for i := range text {
-		c.chars |= bit(i)
+	c.n = uint16(len(text))
+	if !c.index() {
+		return Chunk{}, ErrInvalidUTF8
 	}
 	return c, nil
 }
@@ -68,35 +55,42 @@ func New(text string) (Chunk, error) {
 // validates UTF-8 and will reject byte slices that start/end in the middle of
 // a multi-byte rune.
 func NewBytes(text []byte) (Chunk, error) {
-	if !utf8.Valid(text) {
-		return Chunk{}, ErrInvalidUTF8
-	}
 	if len(text) > MaxBase {
+		if !utf8.Valid(text) {
+			return Chunk{}, ErrInvalidUTF8
+		}
 		return Chunk{}, ErrChunkTooLarge
 	}
 	var c Chunk
 	copy(c.text[:], text)
-	c.n = uint8(len(text))
-	// Byte-local ascii properties.
-	for i, b := range text {
-		if b == '\n' {
-			c.newlines |= bit(i)
-		}
-	}
-	// Rune-local boundaries.
-	for i := 0; i < len(text); {
-		c.chars |= bit(i)
-		_, n := utf8.DecodeRune(text[i:])
-		i += n
+	c.n = uint16(len(text))
+	if !c.index() {
+		return Chunk{}, ErrInvalidUTF8
 	}
 	return c, nil
 }
 
+// isSpace reports whether b is ASCII whitespace.
+func isSpace(b byte) bool {
+	switch b {
+	case ' ', '\t', '\n', '\v', '\f', '\r':
+		return true
+	}
+	return false
+}
+
 // Len returns the text length in bytes.
 func (c Chunk) Len() int {
 	return int(c.n)
 }
 
+// PayloadSize returns the text length in bytes. It implements
+// btree.PayloadSizer, separating text from unused chunk capacity in memory
+// usage reports.
+func (c Chunk) PayloadSize() int64 {
+	return int64(c.n)
+}
+
 // IsEmpty reports whether the chunk has no bytes.
 func (c Chunk) IsEmpty() bool {
 	return c.n == 0
@@ -128,6 +122,25 @@ func (c Chunk) Newlines() Bitmap {
 	return c.newlines
 }
 
+// Tabs returns the bitmap of tab characters.
+func (c Chunk) Tabs() Bitmap {
+	return c.tabs
+}
+
+// Whitespace returns the bitmap of ASCII whitespace bytes (space, tab,
+// newline, carriage return, vertical tab and form feed).
+func (c Chunk) Whitespace() Bitmap {
+	return c.spaces
+}
+
+// WordStarts returns the bitmap of word starts, where a word is a maximal run
+// of bytes which are not ASCII whitespace. A chunk starting with a
+// non-whitespace byte has a word start at offset 0, even if the word continues
+// a word of the preceding chunk.
+func (c Chunk) WordStarts() Bitmap {
+	return c.words
+}
+
 // IsCharBoundary reports whether offset is a UTF-8 boundary inside this chunk.
 func (c Chunk) IsCharBoundary(offset int) bool {
 	if offset == c.Len() {
@@ -136,7 +149,7 @@ func (c Chunk) IsCharBoundary(offset int) bool {
 	if offset < 0 || offset > c.Len() {
 		return false
 	}
-	return c.chars&bit(offset) != 0
+	return c.chars.Has(offset)
 }
 
 // AsSlice returns a zero-offset view over the full chunk.
@@ -144,6 +157,9 @@ func (c Chunk) AsSlice() ChunkSlice {
 	return ChunkSlice{
 		chars:    c.chars,
 		newlines: c.newlines,
+		tabs:     c.tabs,
+		spaces:   c.spaces,
+		words:    c.words,
 		text:     c.text[:c.n],
 	}
 }
@@ -156,12 +172,7 @@ func (c Chunk) Slice(start, end int) (ChunkSlice, error) {
 	if !c.IsCharBoundary(start) || !c.IsCharBoundary(end) {
 		return ChunkSlice{}, ErrNotCharBoundary
 	}
-	m := rangeMask(start, end)
-	return ChunkSlice{
-		chars:    (c.chars & m) >> uint(start),
-		newlines: (c.newlines & m) >> uint(start),
-		text:     c.text[start:end],
-	}, nil
+	return c.AsSlice().slice(start, end), nil
 }
 
 // SplitAt splits a chunk into left/right views at byte offset mid.
@@ -191,11 +202,17 @@ func (c Chunk) Append(slice ChunkSlice) (Chunk, bool) {
 		return c, false
 	}
 	out := c
-	shift := uint(base)
-	out.chars |= slice.chars << shift
-	out.newlines |= slice.newlines << shift
+	out.chars = out.chars.Or(slice.chars.ShiftUp(base))
+	out.newlines = out.newlines.Or(slice.newlines.ShiftUp(base))
+	out.tabs = out.tabs.Or(slice.tabs.ShiftUp(base))
+	out.spaces = out.spaces.Or(slice.spaces.ShiftUp(base))
+	words := slice.words
+	if base > 0 && !isSpace(c.text[base-1]) {
+		words = words.AndNot(bit(0)) // the slice continues the chunk's last word
+	}
+	out.words = out.words.Or(words.ShiftUp(base))
 	copy(out.text[base:total], slice.text)
-	out.n = uint8(total)
+	out.n = uint16(total)
 	return out, true
 }
 
@@ -227,7 +244,7 @@ func (s ChunkSlice) IsCharBoundary(offset int) bool {
 	if offset < 0 || offset > s.Len() {
 		return false
 	}
-	return s.chars&bit(offset) != 0
+	return s.chars.Has(offset)
 }
 
 // Slice returns a sub-view [start,end) in slice-local byte offsets.
@@ -238,12 +255,24 @@ func (s ChunkSlice) Slice(start, end int) (ChunkSlice, error) {
 	if !s.IsCharBoundary(start) || !s.IsCharBoundary(end) {
 		return ChunkSlice{}, ErrNotCharBoundary
 	}
+	return s.slice(start, end), nil
+}
+
+// slice returns the view [start,end) without checking bounds.
+func (s ChunkSlice) slice(start, end int) ChunkSlice {
 	m := rangeMask(start, end)
-	return ChunkSlice{
-		chars:    (s.chars & m) >> uint(start),
-		newlines: (s.newlines & m) >> uint(start),
+	out := ChunkSlice{
+		chars:    s.chars.And(m).ShiftDown(start),
+		newlines: s.newlines.And(m).ShiftDown(start),
+		tabs:     s.tabs.And(m).ShiftDown(start),
+		spaces:   s.spaces.And(m).ShiftDown(start),
+		words:    s.words.And(m).ShiftDown(start),
 		text:     s.text[start:end],
-	}, nil
+	}
+	if start < end && !isSpace(out.text[0]) {
+		out.words = out.words.Or(bit(0)) // word starts are local to the view
+	}
+	return out
 }
 
 // SplitAt splits a slice into left/right views at byte offset mid.
@@ -258,27 +287,3 @@ func (s ChunkSlice) SplitAt(mid int) (ChunkSlice, ChunkSlice, error) {
 	}
 	return left, right, nil
 }
-
-// --- Bitmap helpers --------------------------------------------------------
-
-func bit(offset int) Bitmap {
-	if offset < 0 || offset >= MaxBase {
-		return 0
-	}
-	return Bitmap(1) << uint(offset)
-}
-
-func prefixMask(offset int) Bitmap {
-	switch {
-	case offset <= 0:
-		return 0
-	case offset >= MaxBase:
-		return ^Bitmap(0)
-	default:
-		return (Bitmap(1) << uint(offset)) - 1
-	}
-}
-
-func rangeMask(start, end int) Bitmap {
-	return prefixMask(end) &^ prefixMask(start)
-}
@@ -2,6 +2,7 @@ package chunk
 
 import (
 	"errors"
+	"fmt"
 	"strings"
 	"testing"
 )
@@ -16,11 +17,11 @@ func TestNewBuildsBitmaps(t *testing.T) {
 	}
 	// char starts at offsets: 0 ('a'), 1 ('\n'), 2 ('😀'), 6 ('b')
 	for _, off := range []int{0, 1, 2, 6} {
-		if c.Chars()&bit(off) == 0 {
+		if !c.Chars().Has(off) {
 			t.Fatalf("expected chars bit at %d", off)
 		}
 	}
-	if c.Newlines()&bit(1) == 0 {
+	if !c.Newlines().Has(1) {
 		t.Fatalf("expected newline bit at 1")
 	}
 }
@@ -176,3 +177,67 @@ func TestAppendFitAndOverflow(t *testing.T) {
 		t.Fatalf("overflow append should return unchanged chunk")
 	}
 }
+
+func offsets(b Bitmap) []int {
+	var out []int
+	for i := b.Next(0); i >= 0; i = b.Next(i + 1) {
+		out = append(out, i)
+	}
+	return out
+}
+
+func TestWhitespaceTabAndWordBitmaps(t *testing.T) {
+	c, err := New("  ab\tcd\n ä")
+	if err != nil {
+		t.Fatalf("unexpected New error: %v", err)
+	}
+	if got := offsets(c.Tabs()); fmt.Sprint(got) != "[4]" {
+		t.Errorf("tabs at %v", got)
+	}
+	if got := offsets(c.Whitespace()); fmt.Sprint(got) != "[0 1 4 7 8]" {
+		t.Errorf("whitespace at %v", got)
+	}
+	if got := offsets(c.WordStarts()); fmt.Sprint(got) != "[2 5 9]" {
+		t.Errorf("word starts at %v", got)
+	}
+	// Views treat their first byte as a word start, appends join words.
+	mid, err := c.Slice(3, 7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := offsets(mid.words); fmt.Sprint(got) != "[0 2]" {
+		t.Errorf("word starts of view at %v", got)
+	}
+	head, _ := New("xy")
+	joined, ok := head.Append(mid)
+	if !ok || fmt.Sprint(offsets(joined.WordStarts())) != "[0 4]" {
+		t.Errorf("word starts after append at %v", offsets(joined.WordStarts()))
+	}
+	if fmt.Sprint(offsets(joined.Tabs())) != "[3]" || fmt.Sprint(offsets(joined.Whitespace())) != "[3]" {
+		t.Errorf("tabs/whitespace after append at %v/%v", offsets(joined.Tabs()), offsets(joined.Whitespace()))
+	}
+	fresh, _ := New(joined.String())
+	if fresh != joined {
+		t.Errorf("appended chunk differs from freshly built chunk")
+	}
+}
+
+func BenchmarkNewBytes(b *testing.B) {
+	for _, bench := range []struct {
+		name string
+		text string
+	}{
+		{"ascii", strings.Repeat("the quick brown fox\tjumps\n", MaxBase)[:MaxBase]},
+		{"mixed", strings.Repeat("Größe 0123456789 fox\n", MaxBase)[:MaxBase-2]},
+	} {
+		text := []byte(bench.text)
+		b.Run(bench.name, func(b *testing.B) {
+			b.SetBytes(int64(len(text)))
+			for b.Loop() {
+				if _, err := NewBytes(text); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
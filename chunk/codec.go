@@ -0,0 +1,74 @@
+package chunk
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// Codec serializes chunks and chunk summaries. It is used for persistent
+// storage of chunk trees (see btree.Save and btree.Open).
+//
+// Chunks are stored as UTF-8 text prefixed by its length as a uvarint;
+// bitmaps are recomputed when decoding. Lengths below 128 take a single byte,
+// so the encoding of 64-byte chunks does not depend on the chunk layout.
+type Codec struct{}
+
+// AppendItems appends the encoding of chunks to buf.
+func (Codec) AppendItems(buf []byte, chunks []Chunk) ([]byte, error) {
+	buf = binary.AppendUvarint(buf, uint64(len(chunks)))
+	for _, c := range chunks {
+		buf = binary.AppendUvarint(buf, uint64(c.n))
+		buf = append(buf, c.text[:c.n]...)
+	}
+	return buf, nil
+}
+
+// DecodeItems decodes chunks encoded by AppendItems.
+func (Codec) DecodeItems(data []byte) ([]Chunk, error) {
+	count, k := binary.Uvarint(data)
+	if k <= 0 || count > uint64(len(data)) {
+		return nil, fmt.Errorf("%w: malformed chunk count", ErrInvalidEncoding)
+	}
+	data = data[k:]
+	chunks := make([]Chunk, count)
+	for i := range chunks {
+		n, k := binary.Uvarint(data)
+		if k <= 0 || n > uint64(len(data)-k) {
+			return nil, fmt.Errorf("%w: truncated chunk %d", ErrInvalidEncoding, i)
+		}
+		c, err := NewBytes(data[k : k+int(n)])
+		if err != nil {
+			return nil, err
+		}
+		chunks[i] = c
+		data = data[k+int(n):]
+	}
+	if len(data) != 0 {
+		return nil, fmt.Errorf("%w: trailing bytes after chunks", ErrInvalidEncoding)
+	}
+	return chunks, nil
+}
+
+// AppendSummary appends the encoding of a summary to buf.
+func (Codec) AppendSummary(buf []byte, s Summary) []byte {
+	buf = binary.AppendUvarint(buf, s.Bytes)
+	buf = binary.AppendUvarint(buf, s.Chars)
+	return binary.AppendUvarint(buf, s.Lines)
+}
+
+// DecodeSummary decodes a summary encoded by AppendSummary.
+func (Codec) DecodeSummary(data []byte) (Summary, error) {
+	var s Summary
+	for _, field := range []*uint64{&s.Bytes, &s.Chars, &s.Lines} {
+		v, k := binary.Uvarint(data)
+		if k <= 0 {
+			return Summary{}, fmt.Errorf("%w: malformed summary", ErrInvalidEncoding)
+		}
+		*field = v
+		data = data[k:]
+	}
+	if len(data) != 0 {
+		return Summary{}, fmt.Errorf("%w: trailing bytes after summary", ErrInvalidEncoding)
+	}
+	return s, nil
+}
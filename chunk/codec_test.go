@@ -0,0 +1,68 @@
+package chunk
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestCodecRoundTrip(t *testing.T) {
+	var chunks []Chunk
+	for _, s := range []string{"", "a\n😀b", "Hello\nWorld\n", strings.Repeat("x", MaxBase)} {
+		c, err := New(s)
+		if err != nil {
+			t.Fatalf("unexpected New error: %v", err)
+		}
+		chunks = append(chunks, c)
+	}
+	var codec Codec
+	data, err := codec.AppendItems(nil, chunks)
+	if err != nil {
+		t.Fatalf("unexpected encoding error: %v", err)
+	}
+	decoded, err := codec.DecodeItems(data)
+	if err != nil {
+		t.Fatalf("unexpected decoding error: %v", err)
+	}
+	if len(decoded) != len(chunks) {
+		t.Fatalf("expected %d chunks, got %d", len(chunks), len(decoded))
+	}
+	for i := range chunks {
+		if decoded[i] != chunks[i] {
+			t.Fatalf("chunk %d differs after round trip: %q", i, decoded[i].String())
+		}
+	}
+	s := chunks[1].Summary()
+	sum, err := codec.DecodeSummary(codec.AppendSummary(nil, s))
+	if err != nil || sum != s {
+		t.Fatalf("summary differs after round trip: %v, %v", sum, err)
+	}
+}
+
+func TestCodecRejectsMalformedData(t *testing.T) {
+	var codec Codec
+	c, _ := New("abc")
+	data, _ := codec.AppendItems(nil, []Chunk{c})
+	if _, err := codec.DecodeItems(data[:len(data)-1]); !errors.Is(err, ErrInvalidEncoding) {
+		t.Fatalf("expected ErrInvalidEncoding for truncated chunk, got %v", err)
+	}
+	if _, err := codec.DecodeItems(append(data, 0)); !errors.Is(err, ErrInvalidEncoding) {
+		t.Fatalf("expected ErrInvalidEncoding for trailing bytes, got %v", err)
+	}
+	if _, err := codec.DecodeSummary([]byte{1}); !errors.Is(err, ErrInvalidEncoding) {
+		t.Fatalf("expected ErrInvalidEncoding for short summary, got %v", err)
+	}
+}
+
+func TestCodecEncodingOfSmallChunksIsLayoutIndependent(t *testing.T) {
+	c, _ := New(strings.Repeat("ab", 32))
+	data, err := Codec{}.AppendItems(nil, []Chunk{c})
+	if err != nil {
+		t.Fatalf("unexpected encoding error: %v", err)
+	}
+	want := append([]byte{1, 64}, strings.Repeat("ab", 32)...)
+	if !bytes.Equal(data, want) {
+		t.Fatalf("unexpected encoding % x", data)
+	}
+}
@@ -11,4 +11,6 @@ var (
 	ErrIndexOutOfBounds = errors.New("chunk: index out of bounds")
 	// ErrNotCharBoundary signals non-UTF-8-boundary offsets.
 	ErrNotCharBoundary = errors.New("chunk: offset is not a char boundary")
+	// ErrInvalidEncoding signals malformed serialized chunk data.
+	ErrInvalidEncoding = errors.New("chunk: invalid encoding")
 )
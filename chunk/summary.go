@@ -1,7 +1,5 @@
 package chunk
 
-import "math/bits"
-
 // Summary aggregates chunk-level text metrics for tree routing.
 //
 // Tree-level code uses this summary to navigate and aggregate, while chunk
@@ -23,11 +21,10 @@ func (s ChunkSlice) Summary() Summary {
 }
 
 func summarize(n int, chars Bitmap, newlines Bitmap) Summary {
-	mask := prefixMask(n)
 	return Summary{
 		Bytes: uint64(n),
-		Chars: uint64(bits.OnesCount64(uint64(chars & mask))),
-		Lines: uint64(bits.OnesCount64(uint64(newlines & mask))),
+		Chars: uint64(chars.Rank(n)),
+		Lines: uint64(newlines.Rank(n)),
 	}
 }
 
@@ -0,0 +1,108 @@
+package chunk
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math/bits"
+	"unicode/utf8"
+)
+
+// Chunk construction works on 8 bytes at a time, held in a uint64 ("SIMD
+// within a register"). Byte tests produce the high bit of every matching
+// byte, and gather packs these 8 bits into 8 consecutive bitmap bits.
+
+const (
+	lsbs = 0x0101010101010101 // lowest bit of every byte
+	msbs = 0x8080808080808080 // highest bit of every byte
+	low7 = 0x7f7f7f7f7f7f7f7f // all but the highest bit of every byte
+)
+
+// index builds the bitmaps of c from its text, validating UTF-8 in the same
+// pass. It reports false if the text is not valid UTF-8. Words of pure ASCII
+// are validated by a single test; others are decoded rune by rune.
+//
+// The text array has to be zero behind the text, which is true for chunks
+// created from the zero value.
+func (c *Chunk) index() bool {
+	n := int(c.n)
+	valid := 0             // text[:valid] is valid UTF-8
+	prevSpace := uint64(1) // offset 0 counts as following whitespace
+	for i := 0; i < n; i += 8 {
+		w := binary.LittleEndian.Uint64(c.text[i:])
+		if high := w & msbs; high == 0 {
+			valid = i + 8
+		} else {
+			for end := min(i+8, n); valid < end; {
+				if c.text[valid] < utf8.RuneSelf {
+					valid++
+					continue
+				}
+				r, size := utf8.DecodeRune(c.text[valid:n])
+				if r == utf8.RuneError && size == 1 {
+					return false
+				}
+				valid += size
+			}
+		}
+		mask := uint64(0xff)
+		if n-i < 8 {
+			mask = 1<<uint(n-i) - 1
+		}
+		continuation := w &^ (w << 1) & msbs // 10xxxxxx
+		spaces := gather(eqBytes(w, ' ')|inRange(w, '\t', '\r')) & mask
+		word, shift := i/64, uint(i%64)
+		c.chars[word] |= (gather(^continuation&msbs) & mask) << shift
+		c.newlines[word] |= gather(eqBytes(w, '\n')) << shift
+		c.tabs[word] |= gather(eqBytes(w, '\t')) << shift
+		c.spaces[word] |= spaces << shift
+		c.words[word] |= (^spaces & (spaces<<1 | prevSpace) & mask) << shift
+		prevSpace = spaces >> 7 & 1
+	}
+	return true
+}
+
+// SummarizeText returns the summary of text of any length, which equals the
+// sum of the summaries of chunks holding the text, without building chunks.
+// It returns ErrInvalidUTF8 if text is not valid UTF-8.
+func SummarizeText(text []byte) (Summary, error) {
+	if !utf8.Valid(text) {
+		return Summary{}, ErrInvalidUTF8
+	}
+	continuations := 0
+	i := 0
+	for ; i+8 <= len(text); i += 8 {
+		w := binary.LittleEndian.Uint64(text[i:])
+		continuations += bits.OnesCount64(w &^ (w << 1) & msbs)
+	}
+	for ; i < len(text); i++ {
+		if !utf8.RuneStart(text[i]) {
+			continuations++
+		}
+	}
+	return Summary{
+		Bytes: uint64(len(text)),
+		Chars: uint64(len(text) - continuations),
+		Lines: uint64(bytes.Count(text, []byte{'\n'})),
+	}, nil
+}
+
+// eqBytes returns the high bit of every byte of w equal to b.
+func eqBytes(w uint64, b byte) uint64 {
+	x := w ^ lsbs*uint64(b)
+	return ^((x&low7 + low7) | x) & msbs
+}
+
+// inRange returns the high bit of every byte b of w with lo <= b <= hi.
+// lo and hi have to be ASCII, with lo > 0.
+func inRange(w uint64, lo, hi byte) uint64 {
+	x := w & low7
+	atLeastLo := x + lsbs*uint64(0x80-lo)
+	aboveHi := x + lsbs*uint64(0x7f-hi)
+	return atLeastLo &^ aboveHi &^ w & msbs
+}
+
+// gather packs the high bits of the bytes of m into the lowest 8 bits, the
+// high bit of byte k becoming bit k.
+func gather(m uint64) uint64 {
+	return (m >> 7) * 0x0102040810204080 >> 56
+}
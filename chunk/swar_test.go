@@ -0,0 +1,81 @@
+package chunk
+
+import (
+	"math/rand/v2"
+	"testing"
+	"unicode/utf8"
+)
+
+// naiveChunk builds the bitmaps of a chunk byte by byte.
+func naiveChunk(text []byte) Chunk {
+	var c Chunk
+	copy(c.text[:], text)
+	c.n = uint16(len(text))
+	for i, b := range text {
+		switch b {
+		case '\n':
+			c.newlines.set(i)
+		case '\t':
+			c.tabs.set(i)
+		}
+		if isSpace(b) {
+			c.spaces.set(i)
+		} else if i == 0 || isSpace(text[i-1]) {
+			c.words.set(i)
+		}
+	}
+	for i := range string(text) {
+		c.chars.set(i)
+	}
+	return c
+}
+
+func TestSWARIndexMatchesNaive(t *testing.T) {
+	alphabet := []string{"a", "Z", " ", "\t", "\n", "\v", "\f", "\r", "\x00", "\x08", "\x0e", "\x7f",
+		"ä", "€", "🙂"}
+	invalid := []string{"\x80", "\xc3", "\xed\xa0\x80", "\xf4\x90\x80\x80", "\xc0\xaf"}
+	rnd := rand.New(rand.NewPCG(1, 40))
+	for range 20000 {
+		var text []byte
+		for len(text) < MaxBase {
+			if rnd.IntN(200) == 0 {
+				text = append(text, invalid[rnd.IntN(len(invalid))]...)
+			} else {
+				text = append(text, alphabet[rnd.IntN(len(alphabet))]...)
+			}
+		}
+		text = text[:rnd.IntN(MaxBase+1)]
+		c, err := NewBytes(text)
+		if !utf8.Valid(text) {
+			if err != ErrInvalidUTF8 {
+				t.Fatalf("NewBytes(%q): expected ErrInvalidUTF8, got %v", text, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("NewBytes(%q) failed: %v", text, err)
+		}
+		if c != naiveChunk(text) {
+			t.Fatalf("NewBytes(%q) differs from byte-wise construction:\n got %+v\nwant %+v",
+				text, c, naiveChunk(text))
+		}
+	}
+}
+
+func TestSummarizeText(t *testing.T) {
+	text := []byte("Größe 🙂\nlorem\tipsum\n€")
+	var want Summary
+	for _, s := range []string{"Größe 🙂\n", "lorem\tipsum\n€"} {
+		c, err := New(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want = Monoid{}.Add(want, c.Summary())
+	}
+	if got, err := SummarizeText(text); err != nil || got != want {
+		t.Errorf("SummarizeText = %+v, %v; want %+v", got, err, want)
+	}
+	if _, err := SummarizeText(text[:len(text)-1]); err != ErrInvalidUTF8 {
+		t.Errorf("expected ErrInvalidUTF8 for truncated rune, got %v", err)
+	}
+}
@@ -0,0 +1,7 @@
+//go:build chunk128 && !chunk256
+
+package chunk
+
+// Words is the number of 64-bit words per chunk bitmap, giving chunks of
+// 128 bytes.
+const Words = 2
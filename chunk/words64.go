@@ -0,0 +1,7 @@
+//go:build !chunk128 && !chunk256
+
+package chunk
+
+// Words is the number of 64-bit words per chunk bitmap, giving chunks of
+// 64 bytes. Build with tag chunk128 or chunk256 for wider chunks.
+const Words = 1
@@ -0,0 +1,81 @@
+package cordext
+
+import (
+	"unicode/utf8"
+
+	"github.com/npillmayer/cords/chunk"
+)
+
+// Fragmentation returns the share of the cord's chunks which a perfect
+// rechunking would save: 0 for a cord stored in the minimal number of chunks,
+// approaching 1 for a cord made of many tiny chunks. It is O(1) and may be used
+// to decide whether calling Compact is worthwhile.
+func (cord CordEx[E]) Fragmentation() float64 {
+	if cord.IsVoid() {
+		return 0
+	}
+	chunks := cord.tree.Len()
+	minimal := (int64(cord.Len()) + chunk.MaxBase - 1) / chunk.MaxBase
+	return 1 - float64(minimal)/float64(chunks)
+}
+
+// compactRunLimit is the maximum number of chunks Compact repacks at once,
+// which bounds the text it has to copy for a single run.
+const compactRunLimit = 1024
+
+// Compact returns a cord with the same text, in which runs of adjacent chunks
+// which are not full are repacked into chunks filled up to chunk.MaxBase,
+// wherever this saves chunks.
+//
+// Full chunks are never touched, and all subtrees without such runs are shared
+// with cord. Compacting a cord which has not been fragmented by edits
+// therefore is cheap in memory, though it takes a pass over all chunks.
+func (cord CordEx[E]) Compact() CordEx[E] {
+	if cord.IsVoid() {
+		return cord
+	}
+	type run struct{ from, to int64 }
+	var runs []run
+	start, i, size := int64(-1), int64(0), int64(0)
+	flush := func() {
+		// Repacking fills each chunk but the last to within a rune of MaxBase.
+		packed := (size + chunk.MaxBase - utf8.UTFMax) / (chunk.MaxBase - utf8.UTFMax + 1)
+		if start >= 0 && packed < i-start {
+			runs = append(runs, run{start, i})
+		}
+		start, size = -1, 0
+	}
+	cord.tree.ForEachItem(func(c chunk.Chunk) bool {
+		if c.Len() >= chunk.MaxBase || start >= 0 && i-start >= compactRunLimit {
+			flush()
+		}
+		if c.Len() < chunk.MaxBase {
+			if start < 0 {
+				start = i
+			}
+			size += int64(c.Len())
+		}
+		i++
+		return true
+	})
+	flush()
+	// Replace runs back to front, keeping chunk indexes of earlier runs valid.
+	tree := cord.tree
+	for k := len(runs) - 1; k >= 0; k-- {
+		r := runs[k]
+		var text []byte
+		for _, c := range tree.ItemRange(r.from, r.to) {
+			text = append(text, c.String()...)
+		}
+		parts, err := splitToChunks(text)
+		assert(err == nil, "Compact: chunk text is not valid UTF-8")
+		if int64(len(parts)) >= r.to-r.from {
+			continue
+		}
+		tree, err = tree.DeleteRange(r.from, r.to-r.from)
+		assert(err == nil, "Compact: cannot delete chunk run")
+		tree, err = tree.InsertAt(r.from, parts...)
+		assert(err == nil, "Compact: cannot insert merged chunks")
+	}
+	return cordExFromTree(tree, cord.ext)
+}
@@ -0,0 +1,85 @@
+package cordext
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/npillmayer/cords/chunk"
+)
+
+func TestCompactMergesSmallChunksAndKeepsExtension(t *testing.T) {
+	cord, err := FromStringWithExtension(strings.Repeat("x", 10*chunk.MaxBase), newlineExt{})
+	if err != nil {
+		t.Fatalf("FromStringWithExtension failed: %v", err)
+	}
+	if f := cord.Fragmentation(); f != 0 {
+		t.Fatalf("expected fresh cord to be unfragmented, got %.2f", f)
+	}
+	want := cord.String()
+	// Type a line, one character at a time, into the middle of the cord.
+	for i := range 100 {
+		s := "ä"
+		if i%10 == 9 {
+			s = "\n"
+		}
+		c, err := FromStringWithExtension(s, newlineExt{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		pos := uint64(5*chunk.MaxBase + len(want) - 10*chunk.MaxBase)
+		if cord, err = cord.Insert(c, pos); err != nil {
+			t.Fatalf("insert %d failed: %v", i, err)
+		}
+		want = want[:pos] + s + want[pos:]
+	}
+	before := cord.Fragmentation()
+	if before < 0.5 {
+		t.Fatalf("expected typing to fragment the cord, got %.2f", before)
+	}
+	compacted := cord.Compact()
+	if compacted.String() != want {
+		t.Fatalf("compaction changed the text")
+	}
+	if after := compacted.Fragmentation(); after >= before || after > 0.2 {
+		t.Errorf("expected compaction to reduce fragmentation: %.2f -> %.2f", before, after)
+	}
+	if ext, _ := compacted.Ext(); ext != 10 {
+		t.Errorf("expected 10 newlines in extension, got %d", ext)
+	}
+	if err := compacted.Tree().Check(); err != nil {
+		t.Errorf("compacted tree invalid: %v", err)
+	}
+	again := compacted.Compact()
+	if again.Tree() != compacted.Tree() || again.FragmentCount() != compacted.FragmentCount() {
+		t.Errorf("expected compacting a compact cord to leave chunks unchanged")
+	}
+}
+
+func TestCompactRepacksHalfFilledChunks(t *testing.T) {
+	b := NewBuilderNoExt()
+	line := strings.Repeat("y", chunk.MinBase+7) + "\n"
+	for range 200 {
+		c, err := chunk.New(line)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := b.AppendChunk(c); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cord := b.Cord()
+	before := cord.Fragmentation()
+	if before < 0.3 {
+		t.Fatalf("expected chunks of %d bytes to fragment the cord, got %.2f", len(line), before)
+	}
+	compacted := cord.Compact()
+	if compacted.String() != strings.Repeat(line, 200) {
+		t.Fatalf("compaction changed the text")
+	}
+	if after := compacted.Fragmentation(); after != 0 {
+		t.Errorf("expected compaction to repack chunks above MinBase: %.2f -> %.2f", before, after)
+	}
+	if err := compacted.Tree().Check(); err != nil {
+		t.Errorf("compacted tree invalid: %v", err)
+	}
+}
@@ -34,13 +34,13 @@ func TestSegmentRangeBounded(t *testing.T) {
 		t.Fatalf("newline extension mismatch: got=%d want=%d", ext, newlineCount)
 	}
 	for seg := range cord.RangeTextSegment() {
-		nl := seg.Newlines() != 0
+		nl := !seg.Newlines().IsZero()
 		t.Logf("1. (nl=%v) @ %s", nl, seg.String())
 	}
 	t.Log("-----------------------------------------------------------")
 	count := 0
 	for seg := range cord.TextSegmentRangeBounded(1, 2) {
-		nl := seg.Newlines() != 0
+		nl := !seg.Newlines().IsZero()
 		t.Logf("2. [nl=%v] @ %s", nl, seg.String())
 		count++
 	}
@@ -0,0 +1,207 @@
+package cordext
+
+import (
+	"bytes"
+
+	"github.com/npillmayer/cords/chunk"
+)
+
+// LineEnding is a convention for terminating lines of text.
+type LineEnding uint8
+
+const (
+	LF   LineEnding = iota // "\n", as on Unix
+	CRLF                   // "\r\n", as on Windows
+	CR                     // "\r", as on classic Mac OS
+)
+
+func (le LineEnding) String() string {
+	switch le {
+	case CRLF:
+		return "CRLF"
+	case CR:
+		return "CR"
+	}
+	return "LF"
+}
+
+// Marker returns the bytes terminating a line in convention le.
+func (le LineEnding) Marker() string {
+	switch le {
+	case CRLF:
+		return "\r\n"
+	case CR:
+		return "\r"
+	}
+	return "\n"
+}
+
+// LineEndingCounts holds the number of line endings of a text by convention.
+// A "\r\n" counts as a single CRLF line ending.
+type LineEndingCounts struct {
+	LF, CRLF, CR int64
+}
+
+// Dominant returns the most frequent convention, preferring LF, then CRLF on
+// ties. It reports false if there are no line endings at all.
+func (c LineEndingCounts) Dominant() (LineEnding, bool) {
+	switch {
+	case c.LF == 0 && c.CRLF == 0 && c.CR == 0:
+		return LF, false
+	case c.LF >= c.CRLF && c.LF >= c.CR:
+		return LF, true
+	case c.CRLF >= c.CR:
+		return CRLF, true
+	}
+	return CR, true
+}
+
+// LineEndings counts the line endings of the cord by convention, in a single
+// pass over its chunks.
+func (cord CordEx[E]) LineEndings() LineEndingCounts {
+	var counts LineEndingCounts
+	if cord.IsVoid() {
+		return counts
+	}
+	var crs, lfs int64
+	afterCR := false
+	buf := make([]byte, 0, chunk.MaxBase)
+	cord.tree.ForEachItem(func(c chunk.Chunk) bool {
+		text := c.Bytes(buf)
+		lfs += int64(c.Newlines().Count())
+		if bytes.IndexByte(text, '\r') < 0 {
+			if afterCR && len(text) > 0 && text[0] == '\n' {
+				counts.CRLF++
+			}
+			afterCR = false
+			return true
+		}
+		for i, b := range text {
+			switch {
+			case b == '\r':
+				crs++
+			case b == '\n' && (i > 0 && text[i-1] == '\r' || i == 0 && afterCR):
+				counts.CRLF++
+			}
+		}
+		afterCR = text[len(text)-1] == '\r'
+		return true
+	})
+	counts.LF = lfs - counts.CRLF
+	counts.CR = crs - counts.CRLF
+	return counts
+}
+
+// NormalizeLineEndings returns a cord with all line endings, "\r\n", "\r" and
+// "\n", replaced by the marker of convention style.
+//
+// Chunks are streamed through one by one. Converted chunks are fed into a
+// builder, and each run of them is spliced into the result as soon as it
+// ends. All other subtrees are shared with cord.
+func (cord CordEx[E]) NormalizeLineEndings(style LineEnding) CordEx[E] {
+	if cord.IsVoid() {
+		return cord
+	}
+	tree := cord.tree
+	b := &BuilderEx[E]{ext: cord.ext}
+	from := int64(-1) // first chunk of the current run of converted chunks
+	var delta int64   // chunks added to tree by the runs spliced so far
+	// splice replaces the current run, ending in front of chunk to, by the
+	// chunks fed into b.
+	splice := func(to int64) {
+		if from < 0 {
+			return
+		}
+		left, rest, err := tree.SplitAt(from + delta)
+		assert(err == nil, "NormalizeLineEndings: cannot split off chunk run")
+		_, right, err := rest.SplitAt(to - from)
+		assert(err == nil, "NormalizeLineEndings: cannot split off chunk run")
+		if run := b.Cord().tree; run != nil {
+			left, err = left.Concat(run)
+			assert(err == nil, "NormalizeLineEndings: cannot insert converted chunks")
+			delta += run.Len()
+		}
+		tree, err = left.Concat(right)
+		assert(err == nil, "NormalizeLineEndings: cannot insert converted chunks")
+		delta -= to - from
+		from = -1
+		b.Reset()
+	}
+	var prev byte // last byte in front of the current chunk
+	bufs := [2][]byte{make([]byte, 0, chunk.MaxBase), make([]byte, 0, chunk.MaxBase)}
+	var cur, next, converted []byte
+	i := int64(-1)
+	// convert decides on chunk i, held in cur, with next being the following
+	// chunk or nil.
+	convert := func() {
+		var after byte
+		if len(next) > 0 {
+			after = next[0]
+		}
+		converted = appendNormalized(converted[:0], cur, prev, after, style)
+		if bytes.Equal(converted, cur) {
+			splice(i)
+		} else {
+			if from < 0 {
+				from = i
+			}
+			err := b.AppendBytes(converted)
+			assert(err == nil, "NormalizeLineEndings: converted text is not valid UTF-8")
+		}
+		if len(cur) > 0 {
+			prev = cur[len(cur)-1]
+		}
+	}
+	cord.tree.ForEachItem(func(c chunk.Chunk) bool {
+		next = c.Bytes(bufs[(i+1)%2])
+		if i >= 0 {
+			convert()
+		}
+		cur, next = next, nil
+		i++
+		return true
+	})
+	convert()
+	splice(i + 1)
+	return cordExFromTree(tree, cord.ext)
+}
+
+// appendNormalized appends text to out with every line ending replaced by the
+// marker of style. prev and next are the bytes around text, 0 at the borders
+// of the cord. Each byte of a "\r\n" maps to a fixed part of the marker, so
+// that a pair split between chunks is converted consistently, and a chunk
+// already following style maps to itself.
+func appendNormalized(out, text []byte, prev, next byte, style LineEnding) []byte {
+	marker := style.Marker()
+	head, tail := marker[:len(marker)/2], marker[len(marker)/2:] // "" and "\n" for LF
+	if style == CR {
+		head, tail = marker, ""
+	}
+	for i, b := range text {
+		switch b {
+		case '\r':
+			after := next
+			if i+1 < len(text) {
+				after = text[i+1]
+			}
+			if after == '\n' {
+				out = append(out, head...)
+			} else {
+				out = append(out, marker...)
+			}
+		case '\n':
+			before := prev
+			if i > 0 {
+				before = text[i-1]
+			}
+			if before == '\r' {
+				out = append(out, tail...)
+			} else {
+				out = append(out, marker...)
+			}
+		default:
+			out = append(out, b)
+		}
+	}
+	return out
+}
@@ -0,0 +1,92 @@
+package cordext
+
+import (
+	"math/rand/v2"
+	"strings"
+	"testing"
+
+	"github.com/npillmayer/cords/btree"
+)
+
+func normalizedModel(s string, style LineEnding) string {
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	s = strings.ReplaceAll(s, "\r", "\n")
+	return strings.ReplaceAll(s, "\n", style.Marker())
+}
+
+func TestNormalizeLineEndingsAgainstModel(t *testing.T) {
+	rnd := rand.New(rand.NewPCG(4, 5))
+	pieces := []string{"lorem ", "ipsum", "\n", "\r\n", "\r", "ä", "\r\r\n\n"}
+	for range 200 {
+		var sb strings.Builder
+		for sb.Len() < 1000 {
+			sb.WriteString(pieces[rnd.IntN(len(pieces))])
+		}
+		text := sb.String()
+		cord := FromStringNoExt(text)
+		counts := cord.LineEndings()
+		wantCRLF := int64(strings.Count(text, "\r\n"))
+		want := LineEndingCounts{
+			LF:   int64(strings.Count(text, "\n")) - wantCRLF,
+			CRLF: wantCRLF,
+			CR:   int64(strings.Count(text, "\r")) - wantCRLF,
+		}
+		if counts != want {
+			t.Fatalf("LineEndings = %+v, want %+v", counts, want)
+		}
+		for _, style := range []LineEnding{LF, CRLF, CR} {
+			normalized := cord.NormalizeLineEndings(style)
+			if got, want := normalized.String(), normalizedModel(text, style); got != want {
+				t.Fatalf("NormalizeLineEndings(%v) of %q:\n got %q\nwant %q", style, text, got, want)
+			}
+			if err := normalized.tree.Check(); err != nil {
+				t.Fatalf("invalid tree after normalization: %v", err)
+			}
+			if again := normalized.NormalizeLineEndings(style); again.tree != normalized.tree {
+				t.Fatalf("normalizing a normalized cord to %v changed it", style)
+			}
+		}
+	}
+}
+
+func TestNormalizeLineEndingsSharesUnchangedChunks(t *testing.T) {
+	text := strings.Repeat("lorem ipsum dolor sit amet\n", 1000)
+	cord := FromStringNoExt(text + "last line\r\n")
+	normalized := cord.NormalizeLineEndings(LF)
+	if normalized.String() != text+"last line\n" {
+		t.Fatalf("unexpected normalization result")
+	}
+	one, both := btree.MemoryUsage(cord.tree), btree.MemoryUsage(cord.tree, normalized.tree)
+	if both.Nodes-one.Nodes > 2*int64(normalized.tree.Height()) {
+		t.Errorf("expected normalization to copy a single path, copied %d nodes", both.Nodes-one.Nodes)
+	}
+	if le, ok := normalized.LineEndings().Dominant(); !ok || le != LF {
+		t.Errorf("expected dominant LF, have %v", le)
+	}
+	if le, _ := FromStringNoExt("a\r\nb\r\nc\n").LineEndings().Dominant(); le != CRLF {
+		t.Errorf("expected dominant CRLF, have %v", le)
+	}
+	if _, ok := FromStringNoExt("no line ending").LineEndings().Dominant(); ok {
+		t.Errorf("expected no dominant line ending")
+	}
+}
+
+func TestNormalizeLineEndingsWithExtension(t *testing.T) {
+	// Every chunk changes, so the whole cord is a single run of converted
+	// chunks, built with the cord's extension.
+	text := strings.Repeat("lorem ipsum\r\n", 2000)
+	cord, err := FromStringWithExtension(text, newlineExt{})
+	if err != nil {
+		t.Fatalf("FromStringWithExtension failed: %v", err)
+	}
+	normalized := cord.NormalizeLineEndings(LF)
+	if normalized.String() != strings.ReplaceAll(text, "\r\n", "\n") {
+		t.Fatalf("unexpected normalization result")
+	}
+	if err := normalized.tree.Check(); err != nil {
+		t.Fatalf("invalid tree after normalization: %v", err)
+	}
+	if ext, ok := normalized.Ext(); !ok || ext != 2000 {
+		t.Errorf("expected extension to count 2000 lines, have %d", ext)
+	}
+}
@@ -74,6 +74,22 @@ func (s TextSegment) Newlines() chunk.Bitmap {
 	return s.chunk.Newlines()
 }
 
+// Tabs returns the tab bitmap for this segment.
+func (s TextSegment) Tabs() chunk.Bitmap {
+	return s.chunk.Tabs()
+}
+
+// Whitespace returns the ASCII whitespace bitmap for this segment.
+func (s TextSegment) Whitespace() chunk.Bitmap {
+	return s.chunk.Whitespace()
+}
+
+// WordStarts returns the word-start bitmap for this segment, see
+// chunk.Chunk.WordStarts.
+func (s TextSegment) WordStarts() chunk.Bitmap {
+	return s.chunk.WordStarts()
+}
+
 // IsCharBoundary reports whether offset is a UTF-8 boundary inside this segment.
 func (s TextSegment) IsCharBoundary(offset int) bool {
 	return s.chunk.IsCharBoundary(offset)
@@ -0,0 +1,70 @@
+package cordext
+
+// TextStats aggregates word, tab and whitespace counts of a text.
+//
+// Words are maximal runs of bytes which are not ASCII whitespace, as counted
+// by wc -w. TextStats values are produced by TextStatsExtension.
+type TextStats struct {
+	Words      uint64 // number of words
+	Tabs       uint64 // number of tab characters
+	Whitespace uint64 // number of ASCII whitespace bytes, including tabs and newlines
+	bytes      uint64
+	leading    bool // text starts within a word
+	trailing   bool // text ends within a word
+}
+
+// TextStatsExtension is a text segment extension computing TextStats from the
+// chunk bitmaps, without decoding text.
+//
+//	cord, _ := cordext.FromStringWithExtension(text, cordext.TextStatsExtension{})
+//	stats, _ := cord.Ext()
+//	fmt.Println(stats.Words)
+type TextStatsExtension struct{}
+
+var _ TextSegmentExtension[TextStats] = TextStatsExtension{}
+
+// MagicID identifies the extension.
+func (TextStatsExtension) MagicID() string { return "cords:textstats" }
+
+// Zero returns the statistics of the empty text.
+func (TextStatsExtension) Zero() TextStats { return TextStats{} }
+
+// FromSegment computes the statistics of one segment.
+func (TextStatsExtension) FromSegment(seg TextSegment) TextStats {
+	n := seg.Len()
+	if n == 0 {
+		return TextStats{}
+	}
+	spaces := seg.Whitespace()
+	return TextStats{
+		Words:      uint64(seg.WordStarts().Count()),
+		Tabs:       uint64(seg.Tabs().Count()),
+		Whitespace: uint64(spaces.Count()),
+		bytes:      uint64(n),
+		leading:    !spaces.Has(0),
+		trailing:   !spaces.Has(n - 1),
+	}
+}
+
+// Add combines the statistics of two adjacent texts. A word spanning the
+// border is counted once.
+func (TextStatsExtension) Add(left, right TextStats) TextStats {
+	switch {
+	case left.bytes == 0:
+		return right
+	case right.bytes == 0:
+		return left
+	}
+	sum := TextStats{
+		Words:      left.Words + right.Words,
+		Tabs:       left.Tabs + right.Tabs,
+		Whitespace: left.Whitespace + right.Whitespace,
+		bytes:      left.bytes + right.bytes,
+		leading:    left.leading,
+		trailing:   right.trailing,
+	}
+	if left.trailing && right.leading {
+		sum.Words--
+	}
+	return sum
+}
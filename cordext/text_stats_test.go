@@ -0,0 +1,43 @@
+package cordext
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTextStatsExtensionCountsAcrossChunks(t *testing.T) {
+	text := strings.Repeat("lorem\tipsum  dolorsitametconsecteturadipiscing\n", 40) + "  ä"
+	cord, err := FromStringWithExtension(text, TextStatsExtension{})
+	if err != nil {
+		t.Fatalf("FromStringWithExtension failed: %v", err)
+	}
+	check := func(c CordEx[TextStats], text string) {
+		t.Helper()
+		stats, ok := c.Ext()
+		if !ok {
+			t.Fatalf("extension not available")
+		}
+		words := uint64(len(strings.Fields(text)))
+		tabs := uint64(strings.Count(text, "\t"))
+		ws := uint64(len(text) - len(strings.Join(strings.Fields(text), "")))
+		if stats.Words != words || stats.Tabs != tabs || stats.Whitespace != ws {
+			t.Fatalf("stats = %d words, %d tabs, %d whitespace; want %d, %d, %d",
+				stats.Words, stats.Tabs, stats.Whitespace, words, tabs, ws)
+		}
+	}
+	check(cord, text)
+	// Splitting inside words must not change the combined count.
+	for _, i := range []uint64{3, 64, 100, 1001} {
+		left, right, err := cord.Split(i)
+		if err != nil {
+			t.Fatalf("split at %d failed: %v", i, err)
+		}
+		check(left, text[:i])
+		check(right, text[i:])
+		joined, err := left.Concat(right)
+		if err != nil {
+			t.Fatalf("concat failed: %v", err)
+		}
+		check(joined, text)
+	}
+}
@@ -328,6 +328,26 @@ func TestCordCutAndInsert(t *testing.T) {
 	}
 }
 
+func TestCordWriteTo(t *testing.T) {
+	teardown := gotestingadapter.QuickConfig(t, "cords")
+	defer teardown()
+	//
+	text := strings.Repeat("Größe 0123456789\n", 5000)
+	cord := FromString(text)
+	var sb strings.Builder
+	n, err := cord.WriteTo(&sb)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if n != int64(len(text)) || sb.String() != text {
+		t.Fatalf("expected WriteTo to write %d bytes of text, wrote %d", len(text), n)
+	}
+	if n, err := (Cord{}).WriteTo(&sb); n != 0 || err != nil {
+		t.Errorf("expected void cord to write nothing, have n=%d, err=%v", n, err)
+	}
+	var _ io.WriterTo = cord
+}
+
 func TestCordReader(t *testing.T) {
 	teardown := gotestingadapter.QuickConfig(t, "cords")
 	defer teardown()
@@ -0,0 +1,32 @@
+package cords
+
+import (
+	"io"
+	"strconv"
+
+	"github.com/npillmayer/cords/btree"
+	"github.com/npillmayer/cords/chunk"
+)
+
+// WriteDot writes the tree structure of one or more cords in Graphviz DOT
+// format, labeling leaves with their text. Passing several versions of a cord
+// shows which parts of the tree they share.
+//
+//	cords.WriteDot(f, c1, c2) // then: dot -Tsvg -o cords.svg f
+func WriteDot(w io.Writer, cords ...Cord) error {
+	trees := make([]*btree.Tree[chunk.Chunk, chunk.Summary, btree.NO_EXT], len(cords))
+	for i, cord := range cords {
+		trees[i] = cord.tree
+	}
+	return btree.WriteDotVersions(w, chunkLabel, trees...)
+}
+
+// WriteJSON writes the tree structure of cord as indented JSON, see
+// btree.NodeDump.
+func WriteJSON(w io.Writer, cord Cord) error {
+	return btree.WriteJSON(w, cord.tree, chunkLabel)
+}
+
+func chunkLabel(c chunk.Chunk) string {
+	return strconv.Quote(c.String())
+}
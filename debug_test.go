@@ -0,0 +1,43 @@
+package cords
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/npillmayer/cords/btree"
+)
+
+func TestWriteDotShowsSharedLeaves(t *testing.T) {
+	c1 := FromString(strings.Repeat("Hello \"World\"! ", 300))
+	c2, err := Insert(c1, FromString("x"), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := WriteDot(&buf, c1, c2, Cord{}); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+	out := buf.String()
+	if !strings.HasPrefix(out, "digraph btree {") || !strings.Contains(out, `\"Hello \\\"World\\\"! `) {
+		t.Fatalf("unexpected DOT output:\n%.400s", out)
+	}
+	if strings.Count(out, "v0 -> ") != 1 || strings.Count(out, "v1 -> ") != 1 || strings.Contains(out, "v2 -> ") {
+		t.Errorf("expected one root edge per non-empty version")
+	}
+}
+
+func TestWriteJSON(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteJSON(&buf, FromString("Hello")); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+	var dump btree.NodeDump
+	if err := json.Unmarshal(buf.Bytes(), &dump); err != nil {
+		t.Fatalf("cannot decode dump: %v", err)
+	}
+	if dump.Weight != 1 || len(dump.Items) != 1 || dump.Items[0] != `"Hello"` {
+		t.Errorf("unexpected dump %+v", dump)
+	}
+}
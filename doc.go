@@ -15,6 +15,22 @@ Typical usage:
 	c2, _ := cords.Insert(c, cords.FromString(","), 5)
 	s, _ := c2.Report(0, c2.Len())
 
+Cords are safe for concurrent reads. For a single cord shared between many
+readers and one or more writers, Shared publishes new versions with an atomic
+root swap:
+
+	shared := cords.NewShared(c)
+	snapshot := shared.Load() // never changes, no locking required
+	_, _ = shared.Update(func(c cords.Cord) (cords.Cord, error) {
+		return cords.Insert(c, cords.FromString("!"), c.Len())
+	})
+
+Large texts may be persisted and re-opened lazily; text is read from the file
+only where it is accessed:
+
+	_ = cords.Persist(w, c)
+	lazy, _ := cords.OpenPersisted(f, size, 64) // f is an io.ReaderAt
+
 Extension usage:
 
 	// ext implements cordext.TextSegmentExtension[E]
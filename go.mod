@@ -8,6 +8,7 @@ require (
 	github.com/npillmayer/uax v0.1.0
 	golang.org/x/net v0.0.0-20210428140749-89ef3d95e781
 	golang.org/x/term v0.0.0-20210220032956-6a3ed077a48d
+	golang.org/x/text v0.3.6
 )
 
 require (
@@ -19,5 +20,4 @@ require (
 	github.com/onsi/ginkgo v1.16.5 // indirect
 	github.com/onsi/gomega v1.17.0 // indirect
 	golang.org/x/sys v0.0.0-20210423082822-04245dca01da // indirect
-	golang.org/x/text v0.3.6 // indirect
 )
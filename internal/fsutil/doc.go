@@ -0,0 +1,5 @@
+/*
+Package fsutil holds file system helpers shared by the packages which write
+files, textfile and journal.
+*/
+package fsutil
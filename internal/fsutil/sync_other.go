@@ -0,0 +1,8 @@
+//go:build !unix
+
+package fsutil
+
+// SyncDir does nothing on systems which cannot sync directories.
+func SyncDir(dir string) error {
+	return nil
+}
@@ -0,0 +1,22 @@
+//go:build unix
+
+package fsutil
+
+import "os"
+
+// SyncDir syncs directory dir to disk, making a rename within dir durable.
+// An empty dir denotes the current directory.
+func SyncDir(dir string) error {
+	if dir == "" {
+		dir = "."
+	}
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	err = d.Sync()
+	if cerr := d.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
@@ -0,0 +1,44 @@
+/*
+Package journal keeps a write-ahead journal of edits to a cord, so that an
+editing session survives a crash of the editor.
+
+A `Journal` applies each insert and cut to its cord and appends it to a log
+file, protected by a checksum, before returning the edited cord. From time to
+time the complete cord is written to a snapshot file and the log starts over.
+After a crash, `Recover` reconstructs the latest cord by replaying the log over
+the last snapshot. A final record torn by the crash is ignored, while a damaged
+record in the middle of the log makes recovery fail with `ErrCorrupt`.
+
+	j, err := journal.Create("session.journal", cord, journal.Options{})
+	cord, err = j.Insert(cords.FromString("text"), pos)
+	…
+	cord, snapshot, err := journal.Recover("session.journal") // after a crash
+	defer snapshot.Close()
+
+The recovered cord is backed by the snapshot file and loads its text on
+demand, so recovery does not read the complete text. The snapshot file stays
+open until the closer returned by `Recover` is called or, for `Open`, until the
+journal is closed.
+
+A journal is not safe for concurrent use.
+
+_________________________________________________________________________
+
+# BSD 3-Clause License
+
+# Copyright (c) Norbert Pillmayer
+
+All rights reserved.
+
+Please refer to the LICENSE file for details.
+*/
+package journal
+
+import (
+	"github.com/npillmayer/schuko/tracing"
+)
+
+// tracer writes to trace with key 'cords'
+func tracer() tracing.Trace {
+	return tracing.Select("cords")
+}
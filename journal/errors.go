@@ -0,0 +1,6 @@
+package journal
+
+import "errors"
+
+// ErrCorrupt signals that a journal or its snapshot is malformed.
+var ErrCorrupt = errors.New("journal: corrupt journal")
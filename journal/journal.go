@@ -0,0 +1,402 @@
+package journal
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/npillmayer/cords"
+	"github.com/npillmayer/cords/internal/fsutil"
+)
+
+// defaultSnapshotEvery is the default number of edits between snapshots.
+const defaultSnapshotEvery = 1000
+
+// residentLeaves is the number of leaves of a recovered snapshot cached in
+// memory, see cords.OpenPersisted.
+const residentLeaves = 64
+
+// snapshotSuffix is appended to the path of a journal to name its snapshot.
+const snapshotSuffix = ".snapshot"
+
+// Options control a Journal. The zero value is ready to use.
+type Options struct {
+	// SnapshotEvery is the number of edits after which a snapshot is taken
+	// automatically. It defaults to 1000; a negative value disables automatic
+	// snapshots.
+	SnapshotEvery int
+	// Sync makes every edit durable by syncing the log to disk. Otherwise an
+	// edit survives a crash of the process, but not necessarily one of the
+	// operating system.
+	Sync bool
+}
+
+// Journal records the edits of a cord in a log file at a path, next to a
+// snapshot of the cord at path + ".snapshot".
+type Journal struct {
+	path    string
+	opts    Options
+	log     *os.File
+	logSize int64
+	gen     uint64 // generation of the latest snapshot
+	edits   int    // edits logged since the latest snapshot
+	cord    cords.Cord
+	buf     bytes.Buffer // record buffer
+	err     error        // sticky error of a damaged log
+	// snapshotFile is the snapshot file the cord has been recovered from,
+	// which leaves of the cord are loaded from on demand.
+	snapshotFile *os.File
+}
+
+// Create starts a new journal at path for cord, replacing any journal found
+// there.
+func Create(path string, cord cords.Cord, opts Options) (*Journal, error) {
+	j := &Journal{path: path, opts: opts.normalized(), cord: cord}
+	// Continue the generations of a journal being replaced, so that its log is
+	// ignored by recovery if a crash prevents it from being replaced as well.
+	if err := j.snapshot(lastGeneration(path) + 1); err != nil {
+		return nil, err
+	}
+	return j, nil
+}
+
+// lastGeneration returns the latest generation found in the files of a
+// journal at path, or 0 if there is none.
+func lastGeneration(path string) uint64 {
+	var gen uint64
+	for name, magic := range map[string]string{path + snapshotSuffix: snapshotMagic, path: logMagic} {
+		f, err := os.Open(name)
+		if err != nil {
+			continue
+		}
+		if g, err := readHeader(f, magic); err == nil {
+			gen = max(gen, g)
+		}
+		_ = f.Close()
+	}
+	return gen
+}
+
+// Open recovers the journal at path, as Recover does, and continues to record
+// edits of the recovered cord. The cord reads its text from the snapshot file
+// on demand until the journal is closed; cords of the journal must not be used
+// after Close.
+func Open(path string, opts Options) (_ *Journal, err error) {
+	st, err := recoverState(path)
+	if err != nil {
+		return nil, err
+	}
+	j := &Journal{path: path, opts: opts.normalized(), gen: st.gen, edits: st.edits, cord: st.cord,
+		snapshotFile: st.snapshot}
+	defer func() {
+		if err != nil {
+			_ = j.Close()
+		}
+	}()
+	if st.logGen != st.gen {
+		if err := j.startLog(st.gen); err != nil {
+			return nil, err
+		}
+		return j, nil
+	}
+	// Drop a torn record at the end, if any, before appending.
+	if j.log, err = os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0); err != nil {
+		return nil, fmt.Errorf("journal open failed: %w", err)
+	}
+	if err := j.log.Truncate(st.logEnd); err != nil {
+		return nil, fmt.Errorf("journal open failed: %w", err)
+	}
+	j.logSize = st.logEnd
+	return j, nil
+}
+
+func (o Options) normalized() Options {
+	if o.SnapshotEvery == 0 {
+		o.SnapshotEvery = defaultSnapshotEvery
+	}
+	return o
+}
+
+// Cord returns the cord with all edits recorded so far.
+func (j *Journal) Cord() cords.Cord {
+	return j.cord
+}
+
+// Insert inserts c into the cord at byte offset i, as cords.Insert does, and
+// records the edit. It returns the edited cord.
+//
+// If recording fails, the edit is not applied. An error of an automatic
+// snapshot is returned together with the edited cord, as the edit has been
+// recorded in the log.
+func (j *Journal) Insert(c cords.Cord, i uint64) (cords.Cord, error) {
+	cord, err := cords.Insert(j.cord, c, i)
+	if err != nil || c.IsVoid() {
+		return j.cord, err
+	}
+	return j.record(cord, opInsert, i, c, 0)
+}
+
+// Cut removes byte range [i, i+l) from the cord, as cords.Cut does, and
+// records the edit. It returns the edited cord; errors are handled as for
+// Insert.
+func (j *Journal) Cut(i, l uint64) (cords.Cord, error) {
+	cord, _, err := cords.Cut(j.cord, i, l)
+	if err != nil || l == 0 {
+		return j.cord, err
+	}
+	return j.record(cord, opCut, i, cords.Cord{}, l)
+}
+
+// record appends an edit to the log, which turns the cord into edited.
+func (j *Journal) record(edited cords.Cord, o op, pos uint64, c cords.Cord, n uint64) (cords.Cord, error) {
+	if j.err != nil {
+		return j.cord, j.err
+	}
+	j.buf.Reset()
+	if err := appendRecord(&j.buf, o, pos, c, n); err != nil {
+		return j.cord, err
+	}
+	if err := j.write(j.buf.Bytes()); err != nil {
+		return j.cord, fmt.Errorf("journal write failed: %w", err)
+	}
+	j.cord = edited
+	j.edits++
+	if j.opts.SnapshotEvery > 0 && j.edits >= j.opts.SnapshotEvery {
+		return j.cord, j.Snapshot()
+	}
+	return j.cord, nil
+}
+
+// write appends rec to the log. A partially written record is removed again,
+// as it would hide all records after it from recovery.
+func (j *Journal) write(rec []byte) error {
+	if j.log == nil {
+		return fs.ErrClosed
+	}
+	_, err := j.log.Write(rec)
+	if err == nil && j.opts.Sync {
+		err = j.log.Sync()
+	}
+	if err != nil {
+		if terr := j.log.Truncate(j.logSize); terr != nil {
+			j.err = fmt.Errorf("journal damaged: %w", errors.Join(err, terr))
+		}
+		return err
+	}
+	j.logSize += int64(len(rec))
+	return nil
+}
+
+// Snapshot writes the cord to the snapshot file and starts a new, empty log.
+func (j *Journal) Snapshot() error {
+	if j.err != nil {
+		return j.err
+	}
+	return j.snapshot(j.gen + 1)
+}
+
+// snapshot writes a snapshot of generation gen. The snapshot is in place
+// before the log is replaced, so a crash in between leaves a log of an older
+// generation, which recovery ignores. For the same reason, edits cannot be
+// recorded any more if replacing the log fails.
+func (j *Journal) snapshot(gen uint64) error {
+	err := writeAtomic(j.path+snapshotSuffix, func(w io.Writer) error {
+		if _, err := w.Write(header(snapshotMagic, gen)); err != nil {
+			return err
+		}
+		return cords.Persist(w, j.cord)
+	})
+	if err != nil {
+		return fmt.Errorf("journal snapshot failed: %w", err)
+	}
+	tracer().Debugf("journal %s: snapshot %d of %d bytes", j.path, gen, j.cord.Len())
+	if err := j.startLog(gen); err != nil {
+		j.err = fmt.Errorf("journal damaged: %w", err)
+		return j.err
+	}
+	return nil
+}
+
+// startLog replaces the log by an empty one for the snapshot of generation
+// gen.
+func (j *Journal) startLog(gen uint64) error {
+	h := header(logMagic, gen)
+	err := writeAtomic(j.path, func(w io.Writer) error {
+		_, err := w.Write(h)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("journal snapshot failed: %w", err)
+	}
+	if j.log != nil {
+		_ = j.log.Close()
+	}
+	if j.log, err = os.OpenFile(j.path, os.O_WRONLY|os.O_APPEND, 0); err != nil {
+		return fmt.Errorf("journal snapshot failed: %w", err)
+	}
+	j.logSize, j.gen, j.edits = int64(len(h)), gen, 0
+	return nil
+}
+
+// Close closes the log, and the snapshot file of a journal opened with Open.
+// The journal remains on disk for recovery.
+func (j *Journal) Close() error {
+	var err error
+	if j.log != nil {
+		err = j.log.Close()
+		j.log = nil
+	}
+	if j.snapshotFile != nil {
+		if serr := j.snapshotFile.Close(); err == nil {
+			err = serr
+		}
+		j.snapshotFile = nil
+	}
+	return err
+}
+
+// Remove closes the journal and deletes its files, e.g. after the cord has
+// been saved.
+func (j *Journal) Remove() error {
+	err := j.Close()
+	for _, name := range []string{j.path, j.path + snapshotSuffix} {
+		if rerr := os.Remove(name); rerr != nil && !errors.Is(rerr, fs.ErrNotExist) && err == nil {
+			err = rerr
+		}
+	}
+	return err
+}
+
+// Recover reconstructs the latest cord recorded in the journal at path, by
+// replaying the log over the last snapshot. A torn record at the end of the
+// log, as left by a crash while writing it, is ignored.
+//
+// The cord reads its text from the snapshot file on demand. The returned
+// closer closes the file; the cord, and all cords derived from it, must not be
+// used afterwards.
+func Recover(path string) (cords.Cord, io.Closer, error) {
+	st, err := recoverState(path)
+	if err != nil {
+		return cords.Cord{}, nil, err
+	}
+	return st.cord, st.snapshot, nil
+}
+
+// state is the state of a journal on disk.
+type state struct {
+	cord     cords.Cord
+	snapshot *os.File // open snapshot file, backing cord
+	gen      uint64   // generation of the snapshot
+	edits    int      // edits replayed from the log
+	logGen   uint64   // generation of the log, 0 if missing
+	logEnd   int64    // end of the last valid record
+}
+
+// recoverState recovers the state of the journal at path. Unless it fails,
+// the snapshot file of the state is left open.
+func recoverState(path string) (_ state, err error) {
+	snapshot, err := os.Open(path + snapshotSuffix)
+	if err != nil {
+		return state{}, fmt.Errorf("journal recovery failed: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			_ = snapshot.Close()
+		}
+	}()
+	info, err := snapshot.Stat()
+	if err != nil {
+		return state{}, fmt.Errorf("journal recovery failed: %w", err)
+	}
+	st := state{snapshot: snapshot}
+	if st.gen, err = readHeader(io.NewSectionReader(snapshot, 0, int64(headerSize)), snapshotMagic); err != nil {
+		return state{}, fmt.Errorf("journal recovery failed: snapshot: %w", err)
+	}
+	size := info.Size() - int64(headerSize)
+	text := io.NewSectionReader(snapshot, int64(headerSize), size)
+	if st.cord, err = cords.OpenPersisted(text, size, residentLeaves); err != nil {
+		return state{}, fmt.Errorf("journal recovery failed: snapshot: %w", err)
+	}
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return st, nil
+	} else if err != nil {
+		return state{}, fmt.Errorf("journal recovery failed: %w", err)
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+	if info, err = f.Stat(); err != nil {
+		return state{}, fmt.Errorf("journal recovery failed: %w", err)
+	}
+	r := bufio.NewReader(f)
+	if st.logGen, err = readHeader(r, logMagic); err != nil {
+		return state{}, fmt.Errorf("journal recovery failed: %w", err)
+	}
+	switch {
+	case st.logGen < st.gen:
+		tracer().Infof("journal %s: ignoring log %d older than snapshot %d", path, st.logGen, st.gen)
+		return st, nil
+	case st.logGen > st.gen:
+		return state{}, fmt.Errorf("journal recovery failed: %w: log %d newer than snapshot %d",
+			ErrCorrupt, st.logGen, st.gen)
+	}
+	st.logEnd = int64(headerSize)
+	for {
+		rec, n, err := readRecord(r, info.Size()-st.logEnd)
+		if err == io.EOF {
+			break
+		} else if errors.Is(err, errTorn) {
+			tracer().Infof("journal %s: ignoring torn record at offset %d", path, st.logEnd)
+			break
+		} else if err != nil {
+			return state{}, fmt.Errorf("journal recovery failed: record at offset %d: %w", st.logEnd, err)
+		}
+		if st.cord, err = rec.apply(st.cord); err != nil {
+			return state{}, fmt.Errorf("journal recovery failed: %w: record at offset %d: %w",
+				ErrCorrupt, st.logEnd, err)
+		}
+		st.logEnd += n
+		st.edits++
+	}
+	tracer().Debugf("journal %s: replayed %d edits", path, st.edits)
+	return st, nil
+}
+
+// writeAtomic writes a file name with write, replacing an existing file only
+// after the new content is on disk.
+func writeAtomic(name string, write func(w io.Writer) error) (err error) {
+	dir := filepath.Dir(name)
+	f, err := os.CreateTemp(dir, "."+filepath.Base(name)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = f.Close()
+			_ = os.Remove(f.Name())
+		}
+	}()
+	w := bufio.NewWriter(f)
+	if err = write(w); err != nil {
+		return err
+	}
+	if err = w.Flush(); err != nil {
+		return err
+	}
+	if err = f.Sync(); err != nil {
+		return err
+	}
+	if err = f.Close(); err != nil {
+		return err
+	}
+	if err = os.Rename(f.Name(), name); err != nil {
+		return err
+	}
+	return fsutil.SyncDir(dir)
+}
@@ -0,0 +1,355 @@
+package journal
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/npillmayer/cords"
+	"github.com/npillmayer/schuko/tracing/gotestingadapter"
+)
+
+// edit makes a few edits through j and returns the expected text.
+func edit(t *testing.T, j *Journal) string {
+	t.Helper()
+	steps := []func() (cords.Cord, error){
+		func() (cords.Cord, error) { return j.Insert(cords.FromString("Größe "), 0) },
+		func() (cords.Cord, error) { return j.Cut(8, 6) },
+		func() (cords.Cord, error) { return j.Insert(cords.FromString("🙂\n"), j.Cord().Len()) },
+		func() (cords.Cord, error) { return j.Insert(cords.FromString("and "), 8) },
+	}
+	for i, step := range steps {
+		if _, err := step(); err != nil {
+			t.Fatalf("edit %d failed: %v", i, err)
+		}
+	}
+	return j.Cord().String()
+}
+
+// recoverText recovers the journal at path and returns its text.
+func recoverText(t *testing.T, path string) (string, error) {
+	t.Helper()
+	cord, snapshot, err := Recover(path)
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		if err := snapshot.Close(); err != nil {
+			t.Errorf("closing snapshot failed: %v", err)
+		}
+	}()
+	return cord.String(), nil
+}
+
+func TestJournalRecover(t *testing.T) {
+	teardown := gotestingadapter.QuickConfig(t, "cords")
+	defer teardown()
+
+	path := filepath.Join(t.TempDir(), "session.journal")
+	j, err := Create(path, cords.FromString("hello world"), Options{Sync: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := edit(t, j)
+	if want != "Größe and world🙂\n" {
+		t.Fatalf("unexpected text %q", want)
+	}
+	if _, err := j.Cut(100, 1); err == nil {
+		t.Errorf("expected invalid cut to fail")
+	}
+	// no Close, as after a crash
+	text, err := recoverText(t, path)
+	if err != nil {
+		t.Fatalf("Recover failed: %v", err)
+	}
+	if text != want {
+		t.Errorf("recovered %q, expected %q", text, want)
+	}
+}
+
+func TestJournalSnapshots(t *testing.T) {
+	teardown := gotestingadapter.QuickConfig(t, "cords")
+	defer teardown()
+
+	path := filepath.Join(t.TempDir(), "session.journal")
+	j, err := Create(path, cords.FromString("hello world"), Options{SnapshotEvery: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := edit(t, j)
+	if j.gen != 2 || j.edits != 1 {
+		t.Errorf("expected one edit logged after snapshot 2, have %d after %d", j.edits, j.gen)
+	}
+	st, err := recoverState(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if st.cord.String() != want || st.edits != 1 {
+		t.Errorf("recovered %q with %d edits replayed", st.cord.String(), st.edits)
+	}
+	_ = st.snapshot.Close()
+	if err := j.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := j.Insert(cords.FromString("x"), 0); !errors.Is(err, fs.ErrClosed) {
+		t.Errorf("expected edit of closed journal to fail, got %v", err)
+	}
+}
+
+func TestJournalTornRecord(t *testing.T) {
+	teardown := gotestingadapter.QuickConfig(t, "cords")
+	defer teardown()
+
+	path := filepath.Join(t.TempDir(), "session.journal")
+	j, err := Create(path, cords.FromString("hello world"), Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	edit(t, j)
+	before := j.Cord().String()
+	info, _ := os.Stat(path)
+	if _, err := j.Insert(cords.FromString("lost"), 0); err != nil {
+		t.Fatal(err)
+	}
+	_ = j.Close()
+	// The crash has torn the last record.
+	if err := os.Truncate(path, info.Size()+5); err != nil {
+		t.Fatal(err)
+	}
+	text, err := recoverText(t, path)
+	if err != nil || text != before {
+		t.Fatalf("recovered %q, expected %q: %v", text, before, err)
+	}
+	// Editing continues after the last intact record.
+	j, err = Open(path, Options{})
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	if _, err := j.Insert(cords.FromString("new "), 0); err != nil {
+		t.Fatal(err)
+	}
+	_ = j.Close()
+	if text, err = recoverText(t, path); err != nil || text != "new "+before {
+		t.Errorf("recovered %q after reopening: %v", text, err)
+	}
+}
+
+func TestJournalStaleLog(t *testing.T) {
+	teardown := gotestingadapter.QuickConfig(t, "cords")
+	defer teardown()
+
+	path := filepath.Join(t.TempDir(), "session.journal")
+	j, err := Create(path, cords.FromString("hello world"), Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := edit(t, j)
+	log, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := j.Snapshot(); err != nil {
+		t.Fatal(err)
+	}
+	_ = j.Close()
+	// A crash after writing the snapshot, before replacing the log, leaves the
+	// log of the previous snapshot, whose edits must not be replayed again.
+	if err := os.WriteFile(path, log, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	text, err := recoverText(t, path)
+	if err != nil || text != want {
+		t.Fatalf("recovered %q, expected %q: %v", text, want, err)
+	}
+	j, err = Open(path, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := j.Cut(0, 6); err != nil {
+		t.Fatal(err)
+	}
+	if text, err = recoverText(t, path); err != nil || text != want[6:] {
+		t.Errorf("recovered %q after reopening: %v", text, err)
+	}
+	if err := j.Remove(); err != nil {
+		t.Fatal(err)
+	}
+	if entries, _ := os.ReadDir(filepath.Dir(path)); len(entries) != 0 {
+		t.Errorf("expected journal files to be removed, have %d files", len(entries))
+	}
+}
+
+func TestJournalCreateReplacesJournal(t *testing.T) {
+	teardown := gotestingadapter.QuickConfig(t, "cords")
+	defer teardown()
+
+	path := filepath.Join(t.TempDir(), "session.journal")
+	j, err := Create(path, cords.FromString("hello world"), Options{SnapshotEvery: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	edit(t, j)
+	_ = j.Close()
+	log, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if j, err = Create(path, cords.FromString("new text"), Options{}); err != nil {
+		t.Fatal(err)
+	}
+	_ = j.Close()
+	// A crash after writing the new snapshot, before replacing the log,
+	// leaves the log of the replaced journal, which is of a later generation
+	// than a new journal would start with.
+	if err := os.WriteFile(path, log, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if text, err := recoverText(t, path); err != nil || text != "new text" {
+		t.Errorf("recovered %q, expected the new journal: %v", text, err)
+	}
+}
+
+func TestJournalSnapshotFailure(t *testing.T) {
+	teardown := gotestingadapter.QuickConfig(t, "cords")
+	defer teardown()
+
+	path := filepath.Join(t.TempDir(), "session.journal")
+	j, err := Create(path, cords.FromString("hello world"), Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := edit(t, j)
+	// A directory in place of the log lets the snapshot be written, but not
+	// the new log.
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(path, 0o700); err != nil {
+		t.Fatal(err)
+	}
+	if err := j.Snapshot(); err == nil {
+		t.Fatalf("expected snapshot to fail")
+	}
+	// Edits appended to the previous log would be lost, as recovery ignores
+	// it in favour of the new snapshot.
+	if cord, err := j.Insert(cords.FromString("lost"), 0); err == nil || cord.String() != want {
+		t.Errorf("expected edit after failed snapshot to be refused, got %q, %v", cord.String(), err)
+	}
+	_ = j.Close()
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+	if text, err := recoverText(t, path); err != nil || text != want {
+		t.Errorf("recovered %q, expected %q: %v", text, want, err)
+	}
+}
+
+func TestJournalCorrupt(t *testing.T) {
+	teardown := gotestingadapter.QuickConfig(t, "cords")
+	defer teardown()
+
+	path := filepath.Join(t.TempDir(), "session.journal")
+	if _, _, err := Recover(path); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("expected missing journal to fail, got %v", err)
+	}
+	j, err := Create(path, cords.FromString("hello world"), Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = j.Close()
+	if err := os.WriteFile(path+snapshotSuffix, []byte("not a snapshot, really not"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := Recover(path); !errors.Is(err, ErrCorrupt) {
+		t.Errorf("expected ErrCorrupt, got %v", err)
+	}
+}
+
+func TestJournalCorruptRecord(t *testing.T) {
+	teardown := gotestingadapter.QuickConfig(t, "cords")
+	defer teardown()
+
+	path := filepath.Join(t.TempDir(), "session.journal")
+	j, err := Create(path, cords.FromString("hello world"), Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	edit(t, j)
+	_ = j.Close()
+	log, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Damage the first insert, which is followed by more records: its text,
+	// and its length, which then appears to reach beyond the end of the log.
+	for _, offset := range []int{headerSize + recordHeader + 3, headerSize + 2} {
+		damaged := append([]byte(nil), log...)
+		damaged[offset] ^= 0xff
+		if err := os.WriteFile(path, damaged, 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if _, _, err := Recover(path); !errors.Is(err, ErrCorrupt) {
+			t.Errorf("expected ErrCorrupt from Recover for damage at %d, got %v", offset, err)
+		}
+		if _, err := Open(path, Options{}); !errors.Is(err, ErrCorrupt) {
+			t.Errorf("expected ErrCorrupt from Open for damage at %d, got %v", offset, err)
+		}
+		if info, err := os.Stat(path); err != nil || info.Size() != int64(len(log)) {
+			t.Errorf("expected a corrupt log to be left untruncated")
+		}
+	}
+	// The same damage to the text of the final record is taken as a torn
+	// write.
+	damaged := append([]byte(nil), log...)
+	damaged[len(damaged)-1] ^= 0xff
+	if err := os.WriteFile(path, damaged, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	text, err := recoverText(t, path)
+	if err != nil || text != "Größe world🙂\n" {
+		t.Errorf("recovered %q, expected all but the last edit: %v", text, err)
+	}
+}
+
+func TestJournalRecoverLoadsSnapshotLazily(t *testing.T) {
+	teardown := gotestingadapter.QuickConfig(t, "cords")
+	defer teardown()
+
+	path := filepath.Join(t.TempDir(), "session.journal")
+	text := strings.Repeat("lorem ipsum dolor sit amet\n", 40000)
+	j, err := Create(path, cords.FromString(text), Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = j.Close()
+	cord, snapshot, err := Recover(path)
+	if err != nil {
+		t.Fatalf("Recover failed: %v", err)
+	}
+	usage := cords.MemoryUsage(cord)
+	if usage.LazyLeaves == 0 || usage.Payload >= int64(len(text)) {
+		t.Errorf("expected snapshot to be loaded on demand, have %s", usage)
+	}
+	if cord.String() != text {
+		t.Errorf("recovered text differs")
+	}
+	if err := snapshot.Close(); err != nil {
+		t.Fatal(err)
+	}
+	// An opened journal holds the snapshot file until it is closed.
+	if j, err = Open(path, Options{}); err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	if j.snapshotFile == nil {
+		t.Fatalf("expected journal to hold its snapshot file")
+	}
+	if _, err := j.Cut(0, 6); err != nil {
+		t.Fatal(err)
+	}
+	if err := j.Close(); err != nil || j.snapshotFile != nil {
+		t.Errorf("expected Close to close the snapshot file: %v", err)
+	}
+}
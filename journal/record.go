@@ -0,0 +1,173 @@
+package journal
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+
+	"github.com/npillmayer/cords"
+)
+
+// Layout of the log file:
+//
+//	magic            logMagic
+//	generation       uint64, generation of the snapshot the log applies to
+//	records          uint32 payload length, uint32 crc32 of payload,
+//	                 uint32 crc32 of the preceding 8 bytes, payload
+//
+// A payload is an op byte and a uvarint byte offset, followed by the inserted
+// text for inserts and by a uvarint length for cuts.
+//
+// Layout of the snapshot file:
+//
+//	magic            snapshotMagic
+//	generation       uint64
+//	cord             as written by cords.Persist
+//
+// Integers in headers are little-endian.
+const (
+	logMagic      = "cords.journal.v2"
+	snapshotMagic = "cords.snapshot\n\x00"
+	headerSize    = len(logMagic) + 8
+	recordHeader  = 4 + 4 + 4
+)
+
+// op is the kind of an edit.
+type op byte
+
+const (
+	opInsert op = 1
+	opCut    op = 2
+)
+
+// record is an edit as stored in the log.
+type record struct {
+	op   op
+	pos  uint64
+	len  uint64 // length of a cut
+	text []byte // text of an insert
+}
+
+// header returns the header of a log or snapshot file.
+func header(magic string, gen uint64) []byte {
+	return binary.LittleEndian.AppendUint64([]byte(magic), gen)
+}
+
+// readHeader reads the header of a log or snapshot file and returns its
+// generation.
+func readHeader(r io.Reader, magic string) (uint64, error) {
+	h := make([]byte, headerSize)
+	if _, err := io.ReadFull(r, h); err != nil {
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return 0, fmt.Errorf("%w: header missing", ErrCorrupt)
+		}
+		return 0, err
+	}
+	if string(h[:len(magic)]) != magic {
+		return 0, fmt.Errorf("%w: bad magic", ErrCorrupt)
+	}
+	return binary.LittleEndian.Uint64(h[len(magic):]), nil
+}
+
+// appendRecord appends an insert of c or a cut of n bytes at pos, with its
+// record header, to buf.
+func appendRecord(buf *bytes.Buffer, o op, pos uint64, c cords.Cord, n uint64) error {
+	start := buf.Len()
+	buf.Write(make([]byte, recordHeader))
+	buf.WriteByte(byte(o))
+	buf.Write(binary.AppendUvarint(nil, pos))
+	if o == opInsert {
+		if _, err := c.WriteTo(buf); err != nil {
+			return err
+		}
+	} else {
+		buf.Write(binary.AppendUvarint(nil, n))
+	}
+	rec := buf.Bytes()[start:]
+	payload := rec[recordHeader:]
+	binary.LittleEndian.PutUint32(rec, uint32(len(payload)))
+	binary.LittleEndian.PutUint32(rec[4:], crc32.ChecksumIEEE(payload))
+	binary.LittleEndian.PutUint32(rec[8:], crc32.ChecksumIEEE(rec[:8]))
+	return nil
+}
+
+// errTorn signals that the final record of the log has not been written
+// completely, as after a crash in the middle of an append. Replay stops in
+// front of it.
+var errTorn = errors.New("torn record")
+
+// readRecord reads the next record from r, of which at most remaining bytes
+// are left. It returns io.EOF at the end of the log and the size of the
+// record otherwise.
+//
+// Only the final record may be torn: a record cut short by the end of the
+// log, or one with an intact header whose damaged payload ends the log. A
+// damaged record in the middle of the log is reported as ErrCorrupt, as the
+// records after it cannot be replayed without it. The header has a checksum
+// of its own, so that a damaged length cannot make a record in the middle
+// appear to reach the end of the log.
+func readRecord(r *bufio.Reader, remaining int64) (record, int64, error) {
+	var h [recordHeader]byte
+	if _, err := io.ReadFull(r, h[:]); err != nil {
+		if errors.Is(err, io.EOF) {
+			return record{}, 0, io.EOF
+		} else if errors.Is(err, io.ErrUnexpectedEOF) {
+			return record{}, 0, errTorn
+		}
+		return record{}, 0, err
+	}
+	if crc32.ChecksumIEEE(h[:8]) != binary.LittleEndian.Uint32(h[8:]) {
+		return record{}, 0, fmt.Errorf("%w: bad record header", ErrCorrupt)
+	}
+	n := int64(binary.LittleEndian.Uint32(h[:]))
+	if n > remaining-recordHeader {
+		return record{}, 0, errTorn
+	}
+	payload := make([]byte, n)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return record{}, 0, errTorn
+		}
+		return record{}, 0, err
+	}
+	if crc32.ChecksumIEEE(payload) != binary.LittleEndian.Uint32(h[4:]) || n == 0 {
+		if n < remaining-recordHeader {
+			return record{}, 0, fmt.Errorf("%w: bad checksum", ErrCorrupt)
+		}
+		return record{}, 0, errTorn
+	}
+	rec := record{op: op(payload[0])}
+	pos, k := binary.Uvarint(payload[1:])
+	if k <= 0 {
+		return record{}, 0, fmt.Errorf("%w: bad offset", ErrCorrupt)
+	}
+	rec.pos, payload = pos, payload[1+k:]
+	switch rec.op {
+	case opInsert:
+		rec.text = payload
+	case opCut:
+		if rec.len, k = binary.Uvarint(payload); k != len(payload) {
+			return record{}, 0, fmt.Errorf("%w: bad length", ErrCorrupt)
+		}
+	default:
+		return record{}, 0, fmt.Errorf("%w: unknown edit %d", ErrCorrupt, rec.op)
+	}
+	return rec, recordHeader + n, nil
+}
+
+// apply applies the edit of rec to cord.
+func (rec record) apply(cord cords.Cord) (cords.Cord, error) {
+	if rec.op == opCut {
+		cord, _, err := cords.Cut(cord, rec.pos, rec.len)
+		return cord, err
+	}
+	var b cords.Builder
+	if err := b.AppendBytes(rec.text); err != nil {
+		return cord, err
+	}
+	return cords.Insert(cord, b.Cord(), rec.pos)
+}
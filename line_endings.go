@@ -0,0 +1,30 @@
+package cords
+
+import "github.com/npillmayer/cords/cordext"
+
+// LineEnding is a convention for terminating lines of text: LF, CRLF or CR.
+type LineEnding = cordext.LineEnding
+
+// Line ending conventions.
+const (
+	LF   = cordext.LF   // "\n", as on Unix
+	CRLF = cordext.CRLF // "\r\n", as on Windows
+	CR   = cordext.CR   // "\r", as on classic Mac OS
+)
+
+// LineEndingCounts holds the number of line endings of a text by convention.
+type LineEndingCounts = cordext.LineEndingCounts
+
+// LineEndings counts the line endings of the cord by convention. Use
+// Dominant on the result to find the convention of a text.
+func (cord Cord) LineEndings() LineEndingCounts {
+	return toCordext(cord).LineEndings()
+}
+
+// NormalizeLineEndings returns a cord with all line endings, "\r\n", "\r" and
+// "\n", replaced by the marker of convention style. It streams over the
+// chunks of cord and rebuilds only runs of chunks which change, sharing all
+// other subtrees with cord.
+func NormalizeLineEndings(cord Cord, style LineEnding) Cord {
+	return fromCordext(toCordext(cord).NormalizeLineEndings(style))
+}
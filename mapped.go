@@ -0,0 +1,148 @@
+package cords
+
+import (
+	"fmt"
+	"runtime"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/npillmayer/cords/btree"
+	"github.com/npillmayer/cords/chunk"
+)
+
+// FromMapped creates a cord over UTF-8 text without copying the text, usually
+// a read-only memory mapping of a file (see textfile.Map).
+//
+// Opening scans text once, in parallel, to validate it and to summarize the
+// cord's leaves; navigating the cord by position needs all leaf summaries up
+// front. Opening is thus linear in the size of text, at about 2 GB/s per core
+// (see BenchmarkFromMapped), but copies nothing. The resident index holds a
+// summary per subtree of up to (2*btree.Base)^3 chunks, about 100 KB of text.
+// Chunks with their bitmaps are built only when a subtree is first needed,
+// with at most resident subtrees cached in memory. Edits materialize regular
+// chunks for the leaves they touch, while the rest of the cord keeps referring
+// to text. text must remain unchanged and accessible while the cord or any
+// cord derived from it is in use.
+//
+// FromMapped returns an error wrapping chunk.ErrInvalidUTF8 if text is not
+// valid UTF-8.
+func FromMapped(text []byte, resident int) (Cord, error) {
+	if len(text) == 0 {
+		return Cord{}, nil
+	}
+	store := newMappedStore(text)
+	leaves := make([]btree.StoredLeaf[chunk.Summary, btree.NO_EXT], len(store.bounds)-1)
+	workers := min(runtime.GOMAXPROCS(0), len(leaves))
+	errs := make([]error, workers)
+	var wg sync.WaitGroup
+	for w := range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := w; i < len(leaves); i += workers {
+				from, to := store.bounds[i], store.bounds[i+1]
+				summary, err := chunk.SummarizeText(text[from:to])
+				if err != nil {
+					errs[w] = fmt.Errorf("%w: in bytes %d…%d", err, from, to)
+					return
+				}
+				leaves[i] = btree.StoredLeaf[chunk.Summary, btree.NO_EXT]{
+					ID:      btree.LeafID(i),
+					Count:   mappedChunkCount(to - from),
+					Height:  store.height,
+					Summary: summary,
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			return Cord{}, err
+		}
+	}
+	cfg := btree.Config[chunk.Chunk, chunk.Summary, btree.NO_EXT]{Monoid: chunk.Monoid{}}
+	tree, err := btree.FromStore(cfg, btree.NodeStore[chunk.Chunk](store), leaves, resident)
+	if err != nil {
+		return Cord{}, err
+	}
+	return cordFromTree(tree), nil
+}
+
+// mappedChunkSpan is the maximum nominal length of chunks built from mapped
+// text. Chunk boundaries move back by up to utf8.UTFMax-1 bytes to the start
+// of a rune, which a chunk of MaxBase bytes has room for.
+const mappedChunkSpan = chunk.MaxBase - utf8.UTFMax
+
+// mappedUnitHeight is the height of the subtrees loaded from mapped text as a
+// unit. With 3 levels, the resident index is well below 1% of the text.
+const mappedUnitHeight = 3
+
+// mappedUnitChunks is the maximum nominal number of chunks of a unit, chosen
+// such that all units of a text hold between Base^3 and (2*Base)^3 chunks.
+const mappedUnitChunks = 2*btree.Base*2*btree.Base*2*btree.Base - 1
+
+// mappedStore is a node store building the chunks of a subtree from text.
+type mappedStore struct {
+	text   []byte
+	height int   // of the stored subtrees
+	bounds []int // subtree i holds text[bounds[i]:bounds[i+1]]
+}
+
+// newMappedStore plans the subtrees over text. A text too short for a
+// single subtree of mappedUnitHeight becomes a single subtree of the least
+// height which holds it.
+func newMappedStore(text []byte) *mappedStore {
+	store := &mappedStore{text: text, height: 1}
+	if n := mappedChunkCount(len(text)); n <= mappedUnitChunks {
+		for span := 2 * btree.Base; n > span; span *= 2 * btree.Base {
+			store.height++
+		}
+		store.bounds = []int{0, len(text)}
+		return store
+	}
+	store.height = mappedUnitHeight
+	unitSpan := mappedUnitChunks * mappedChunkSpan
+	n := (len(text) + unitSpan - 1) / unitSpan
+	store.bounds = make([]int, n+1)
+	for i := 1; i < n; i++ {
+		store.bounds[i] = runeStartBefore(text, i*len(text)/n)
+	}
+	store.bounds[n] = len(text)
+	return store
+}
+
+// LoadLeaf builds the chunks of subtree id, partitioning its text into chunks
+// of about equal length.
+func (s *mappedStore) LoadLeaf(id btree.LeafID) ([]chunk.Chunk, error) {
+	from, to := s.bounds[id], s.bounds[id+1]
+	n := mappedChunkCount(to - from)
+	chunks := make([]chunk.Chunk, n)
+	start := from
+	for i := range n {
+		end := to
+		if i < n-1 {
+			end = runeStartBefore(s.text, from+(i+1)*(to-from)/n)
+		}
+		c, err := chunk.NewBytes(s.text[start:end])
+		if err != nil {
+			return nil, fmt.Errorf("%w: in bytes %d…%d", err, start, end)
+		}
+		chunks[i], start = c, end
+	}
+	return chunks, nil
+}
+
+// mappedChunkCount returns the number of chunks built from n bytes of text.
+func mappedChunkCount(n int) int {
+	return (n + mappedChunkSpan - 1) / mappedChunkSpan
+}
+
+// runeStartBefore returns the start of the rune containing text[pos]. For
+// invalid UTF-8 it moves back by at most utf8.UTFMax-1 bytes.
+func runeStartBefore(text []byte, pos int) int {
+	for k := 0; k < utf8.UTFMax-1 && pos > 0 && !utf8.RuneStart(text[pos]); k++ {
+		pos--
+	}
+	return pos
+}
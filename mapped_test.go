@@ -0,0 +1,75 @@
+package cords
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/npillmayer/cords/btree"
+	"github.com/npillmayer/cords/chunk"
+	"github.com/npillmayer/schuko/tracing/gotestingadapter"
+)
+
+func TestFromMapped(t *testing.T) {
+	teardown := gotestingadapter.QuickConfig(t, "cords")
+	defer teardown()
+	//
+	for _, n := range []int{1, 7, 100, 3000} {
+		text := strings.Repeat("Größe 🙂 lorem ipsum\n", n)
+		cord, err := FromMapped([]byte(text), 2)
+		if err != nil {
+			t.Fatalf("FromMapped failed: %v", err)
+		}
+		if err := cord.tree.Check(); err != nil {
+			t.Fatalf("invalid tree for %d lines: %v", n, err)
+		}
+		if cord.Summary() != FromString(text).Summary() {
+			t.Fatalf("unexpected summary %+v, want %+v", cord.Summary(), FromString(text).Summary())
+		}
+		if cord.String() != text {
+			t.Fatalf("mapped cord text differs for %d lines", n)
+		}
+	}
+}
+
+func TestFromMappedMaterializesEditedLeavesOnly(t *testing.T) {
+	teardown := gotestingadapter.QuickConfig(t, "cords")
+	defer teardown()
+	//
+	text := strings.Repeat("Größe 🙂 lorem ipsum\n", 1000*chunk.MaxBase)
+	cord, err := FromMapped([]byte(text), 1)
+	if err != nil {
+		t.Fatalf("FromMapped failed: %v", err)
+	}
+	if u := MemoryUsage(cord); u.LazyLeaves != u.Leaves || u.Payload != 0 {
+		t.Fatalf("expected no text to be loaded after open: %v", u)
+	}
+	pos := uint64(strings.Index(text[len(text)/3:], "\n") + len(text)/3)
+	edited, err := Insert(cord, FromString("XYZ"), pos)
+	if err != nil {
+		t.Fatalf("insert failed: %v", err)
+	}
+	if want := text[:pos] + "XYZ" + text[pos:]; edited.String() != want {
+		t.Fatalf("edited text differs")
+	}
+	// The edit materializes the stored subtree around its position and, where
+	// the parts split off are joined again, a neighbouring one.
+	if u := MemoryUsage(edited); u.Leaves-u.LazyLeaves > 2*2*btree.Base*2*btree.Base+1 {
+		t.Errorf("expected edit to materialize few leaves: %v", u)
+	}
+}
+
+func TestFromMappedRejectsInvalidUTF8(t *testing.T) {
+	teardown := gotestingadapter.QuickConfig(t, "cords")
+	defer teardown()
+	//
+	text := []byte(strings.Repeat("lorem ipsum\n", 1000))
+	text[5000] = 0xff
+	if _, err := FromMapped(text, 1); !errors.Is(err, chunk.ErrInvalidUTF8) {
+		t.Fatalf("expected ErrInvalidUTF8, got %v", err)
+	}
+	if c, err := FromMapped(nil, 1); err != nil || !c.IsVoid() {
+		t.Fatalf("expected void cord for empty text, got %v", err)
+	}
+	var _ btree.NodeStore[chunk.Chunk] = &mappedStore{}
+}
@@ -1,8 +1,6 @@
 package metrics
 
 import (
-	"math/bits"
-
 	"github.com/npillmayer/cords/btree"
 	"github.com/npillmayer/cords/chunk"
 	"github.com/npillmayer/cords/cordext"
@@ -264,13 +262,11 @@ func forEachLineBreak(text plainCordType, from uint64, sink acceptBreak) error {
 
 		// We skip chunks where the summary tells us there are no line breaks in it
 		if chnk.Summary().Lines > 0 {
-			mask := uint64(chnk.Newlines())
-			mask = clearBitsBelow(mask, startOff)
+			newlines := chnk.Newlines()
 			if consumedFirstLF {
-				mask &^= 1
+				newlines = newlines.AndNot(chunk.Bitmap{1})
 			}
-			for mask != 0 {
-				i := bits.TrailingZeros64(mask)
+			for i := newlines.Next(startOff); i >= 0; i = newlines.Next(i + 1) {
 				nlPos := chunkStart + uint64(i)
 				br := byteRange{from: nlPos, to: nlPos + 1}
 				if i > 0 && bytes[i-1] == '\r' {
@@ -281,7 +277,6 @@ func forEachLineBreak(text plainCordType, from uint64, sink acceptBreak) error {
 						return nil
 					}
 				}
-				mask &= mask - 1
 			}
 		}
 
@@ -294,15 +289,3 @@ func forEachLineBreak(text plainCordType, from uint64, sink acceptBreak) error {
 	}
 	return nil
 }
-
-// clearBitsBelow is a small helper to clear all bits below a given bit position
-func clearBitsBelow(mask uint64, n int) uint64 {
-	switch {
-	case n <= 0:
-		return mask
-	case n >= 64:
-		return 0
-	default:
-		return mask &^ ((uint64(1) << uint(n)) - 1)
-	}
-}
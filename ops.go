@@ -70,3 +70,16 @@ func (cord Cord) FragmentCount() int {
 	})
 	return cnt
 }
+
+// Fragmentation returns the share of the cord's chunks which a perfect
+// rechunking would save, between 0 and 1. See Compact.
+func (cord Cord) Fragmentation() float64 {
+	return toCordext(cord).Fragmentation()
+}
+
+// Compact returns a cord with the same text, in which runs of partially filled
+// chunks, as left behind by edits, are repacked into fewer, full chunks.
+// Subtrees without such runs are shared with cord.
+func Compact(cord Cord) Cord {
+	return fromCordext(toCordext(cord).Compact())
+}
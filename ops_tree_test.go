@@ -1,6 +1,11 @@
 package cords
 
-import "testing"
+import (
+	"strings"
+	"testing"
+
+	"github.com/npillmayer/cords/chunk"
+)
 
 func mustTreeCord(t *testing.T, s string) Cord {
 	t.Helper()
@@ -68,3 +73,43 @@ func TestTreePathReportAndReader(t *testing.T) {
 		t.Fatalf("unexpected read result: n=%d p=%q", n, string(p))
 	}
 }
+
+func TestCompactFragmentedCord(t *testing.T) {
+	cord := FromString(strings.Repeat("-", 64*chunk.MaxBase))
+	for i := range 200 {
+		var err error
+		if cord, err = Insert(cord, FromString("ab"), uint64(1000+2*i)); err != nil {
+			t.Fatalf("insert %d failed: %v", i, err)
+		}
+	}
+	text := cord.String()
+	compacted := Compact(cord)
+	if compacted.String() != text {
+		t.Fatalf("compaction changed the text")
+	}
+	if compacted.Fragmentation() >= cord.Fragmentation() || compacted.FragmentCount() >= cord.FragmentCount() {
+		t.Errorf("expected fewer chunks after compaction: %d (%.2f) -> %d (%.2f)",
+			cord.FragmentCount(), cord.Fragmentation(), compacted.FragmentCount(), compacted.Fragmentation())
+	}
+	if u := MemoryUsage(cord, compacted); u.Payload >= 2*int64(len(text)) {
+		t.Errorf("expected compacted cord to share untouched chunks: %v", u)
+	}
+}
+
+func TestNormalizeLineEndings(t *testing.T) {
+	cord := FromString(strings.Repeat("line\r\n", 10*chunk.MaxBase) + "mac\runix\n")
+	if le, ok := cord.LineEndings().Dominant(); !ok || le != CRLF {
+		t.Fatalf("expected dominant CRLF, have %v", le)
+	}
+	lf := NormalizeLineEndings(cord, LF)
+	if lf.String() != strings.Repeat("line\n", 10*chunk.MaxBase)+"mac\nunix\n" {
+		t.Fatalf("unexpected LF normalization")
+	}
+	back := NormalizeLineEndings(lf, CRLF)
+	if back.String() != strings.Repeat("line\r\n", 10*chunk.MaxBase)+"mac\r\nunix\r\n" {
+		t.Fatalf("unexpected CRLF normalization")
+	}
+	if c := back.LineEndings(); c.CRLF != 10*chunk.MaxBase+2 || c.LF != 0 || c.CR != 0 {
+		t.Errorf("unexpected line ending counts %+v", c)
+	}
+}
@@ -0,0 +1,38 @@
+package cords
+
+import (
+	"io"
+
+	"github.com/npillmayer/cords/btree"
+	"github.com/npillmayer/cords/chunk"
+)
+
+// Persist writes cord to w in a persistent, indexed form, which may be opened
+// lazily with OpenPersisted.
+func Persist(w io.Writer, cord Cord) error {
+	tree, err := treeFromCord(cord)
+	if err != nil {
+		return err
+	}
+	return btree.Save(w, tree, chunk.Codec{})
+}
+
+// OpenPersisted opens a cord written by Persist without reading its text.
+//
+// Only the index of the cord's leaves is read from r; text is loaded on
+// demand, with at most residentLeaves leaves (of up to 2*btree.Base chunks
+// each) cached in memory. Summary queries like Len and LineCount do not touch
+// r at all, and seeking reads a single leaf. Edits materialize only the leaves
+// they touch. r must remain readable and unchanged while the cord or any cord
+// derived from it is in use.
+//
+// If reading r fails after the cord has been opened, operations returning an
+// error report it; others panic.
+func OpenPersisted(r io.ReaderAt, size int64, residentLeaves int) (Cord, error) {
+	cfg := btree.Config[chunk.Chunk, chunk.Summary, btree.NO_EXT]{Monoid: chunk.Monoid{}}
+	tree, err := btree.Open(cfg, r, size, chunk.Codec{}, residentLeaves)
+	if err != nil {
+		return Cord{}, err
+	}
+	return cordFromTree(tree), nil
+}
@@ -0,0 +1,95 @@
+package cords
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/npillmayer/cords/btree"
+	"github.com/npillmayer/schuko/tracing/gotestingadapter"
+)
+
+// flakyReader counts reads and fails all reads once broken.
+type flakyReader struct {
+	r      *bytes.Reader
+	reads  atomic.Int64
+	broken atomic.Bool
+}
+
+func (f *flakyReader) ReadAt(p []byte, off int64) (int, error) {
+	if f.broken.Load() {
+		return 0, errors.New("device gone")
+	}
+	f.reads.Add(1)
+	return f.r.ReadAt(p, off)
+}
+
+func persistedCord(t *testing.T, text string) (*flakyReader, int64) {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := Persist(&buf, FromString(text)); err != nil {
+		t.Fatalf("persist failed: %v", err)
+	}
+	return &flakyReader{r: bytes.NewReader(buf.Bytes())}, int64(buf.Len())
+}
+
+func TestPersistRoundTrip(t *testing.T) {
+	teardown := gotestingadapter.QuickConfig(t, "cords")
+	defer teardown()
+	//
+	text := strings.Repeat("The quick brown fox\njumps over the lazy dog.\n", 200)
+	r, size := persistedCord(t, text)
+	cord, err := OpenPersisted(r, size, 2)
+	if err != nil {
+		t.Fatalf("open failed: %v", err)
+	}
+	opened := r.reads.Load()
+	if cord.Len() != uint64(len(text)) || cord.LineCount() != 400 {
+		t.Fatalf("unexpected metrics for opened cord: len=%d, lines=%d", cord.Len(), cord.LineCount())
+	}
+	if r.reads.Load() != opened {
+		t.Fatalf("summary queries read from storage")
+	}
+	s, err := cord.Report(4500, 9)
+	if err != nil || s != text[4500:4509] {
+		t.Fatalf("unexpected report %q, %v", s, err)
+	}
+	edited, err := Insert(cord, FromString("XYZ"), 100)
+	if err != nil {
+		t.Fatalf("insert failed: %v", err)
+	}
+	if edited.String() != text[:100]+"XYZ"+text[100:] {
+		t.Fatalf("unexpected text after edit")
+	}
+	if cord.String() != text {
+		t.Fatalf("edit changed the opened cord")
+	}
+}
+
+func TestPersistEmptyCord(t *testing.T) {
+	r, size := persistedCord(t, "")
+	cord, err := OpenPersisted(r, size, 1)
+	if err != nil {
+		t.Fatalf("open failed: %v", err)
+	}
+	if !cord.IsVoid() {
+		t.Fatalf("expected empty cord, got %q", cord.String())
+	}
+}
+
+func TestOpenPersistedReportsStorageErrors(t *testing.T) {
+	r, size := persistedCord(t, strings.Repeat("0123456789", 1000))
+	if _, err := OpenPersisted(r, size-1, 1); !errors.Is(err, btree.ErrCorruptStore) {
+		t.Fatalf("expected ErrCorruptStore for truncated data, got %v", err)
+	}
+	cord, err := OpenPersisted(r, size, 1)
+	if err != nil {
+		t.Fatalf("open failed: %v", err)
+	}
+	r.broken.Store(true)
+	if _, err := cord.Report(5000, 10); !errors.Is(err, btree.ErrNodeStore) {
+		t.Fatalf("expected ErrNodeStore after storage failure, got %v", err)
+	}
+}
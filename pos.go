@@ -3,7 +3,6 @@ package cords
 import (
 	"errors"
 	"fmt"
-	"math/bits"
 
 	"github.com/npillmayer/cords/btree"
 	"github.com/npillmayer/cords/chunk"
@@ -173,16 +172,7 @@ func chunkRunesBeforeByte(c chunk.Chunk, localByte int) (uint64, error) {
 	if !c.IsCharBoundary(localByte) {
 		return 0, ErrIllegalPosition
 	}
-	var mask uint64
-	switch {
-	case localByte <= 0:
-		mask = 0
-	case localByte >= chunk.MaxBase:
-		mask = ^uint64(0)
-	default:
-		mask = (uint64(1) << uint(localByte)) - 1
-	}
-	return uint64(bits.OnesCount64(uint64(c.Chars()) & mask)), nil
+	return uint64(c.Chars().Rank(localByte)), nil
 }
 
 func chunkByteForRuneCount(c chunk.Chunk, runes uint64) (int, error) {
@@ -193,21 +183,12 @@ func chunkByteForRuneCount(c chunk.Chunk, runes uint64) (int, error) {
 		return 0, nil
 	}
 
-	seen := uint64(0)
-	for i := 0; i <= c.Len(); i++ {
-		if i == c.Len() {
-			if seen == runes {
-				return i, nil
-			}
-			break
-		}
-		if !c.IsCharBoundary(i) {
-			continue
-		}
-		if seen == runes {
-			return i, nil
+	// Rune starts are the set bits of the chars bitmap; step over runes of them.
+	chars, i := c.Chars(), 0
+	for range runes {
+		if i = chars.Next(i + 1); i < 0 {
+			i = c.Len()
 		}
-		seen++
 	}
-	return 0, ErrIllegalPosition
+	return i, nil
 }
@@ -1,6 +1,10 @@
 package cords
 
-import "io"
+import (
+	"io"
+
+	"github.com/npillmayer/cords/chunk"
+)
 
 // Reader returns a sequential reader over cord bytes.
 //
@@ -9,6 +13,42 @@ func (cord Cord) Reader() io.Reader {
 	return &cordReader{cord: cord}
 }
 
+// writeBufferSize is the number of bytes WriteTo collects before writing.
+const writeBufferSize = 32 * 1024
+
+// WriteTo writes the cord text to w, chunk by chunk. It implements
+// io.WriterTo and does not materialize the text as a whole.
+//
+// Chunks are collected into writes of up to 32 KiB. WriteTo returns the number
+// of bytes written and the first error encountered.
+func (cord Cord) WriteTo(w io.Writer) (int64, error) {
+	var written int64
+	buf := make([]byte, 0, min(cord.Len(), writeBufferSize))
+	flush := func() error {
+		n, err := w.Write(buf)
+		written += int64(n)
+		if err == nil && n < len(buf) {
+			err = io.ErrShortWrite
+		}
+		buf = buf[:0]
+		return err
+	}
+	err := cord.EachChunk(func(c chunk.Chunk, _ uint64) error {
+		if cap(buf)-len(buf) < c.Len() {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+		// Bytes copies into the free tail of buf, which has room for c.
+		buf = buf[:len(buf)+len(c.Bytes(buf[len(buf):]))]
+		return nil
+	})
+	if err == nil && len(buf) > 0 {
+		err = flush()
+	}
+	return written, err
+}
+
 type cordReader struct {
 	cord   Cord
 	cursor uint64
@@ -0,0 +1,66 @@
+package cords
+
+import "sync/atomic"
+
+// Shared is a container for a cord which is read by many goroutines and
+// updated by one or more writers.
+//
+// As cords are persistent, readers never need locks: Load returns an immutable
+// snapshot, which stays valid and unchanged regardless of subsequent updates.
+// Writers derive a new cord from the current one and publish it with an atomic
+// compare-and-swap of the root.
+//
+// The zero value of Shared is ready to use and holds the empty cord.
+// A Shared must not be copied after first use.
+type Shared struct {
+	current atomic.Pointer[Cord]
+}
+
+// NewShared creates a shared container holding cord.
+func NewShared(cord Cord) *Shared {
+	s := &Shared{}
+	s.current.Store(&cord)
+	return s
+}
+
+// Load returns a snapshot of the current cord.
+func (s *Shared) Load() Cord {
+	if c := s.current.Load(); c != nil {
+		return *c
+	}
+	return Cord{}
+}
+
+// Store unconditionally replaces the current cord.
+func (s *Shared) Store(cord Cord) {
+	s.current.Store(&cord)
+}
+
+// Update derives a new cord from the current one by calling f and publishes
+// the result atomically. If another writer published a cord in the meantime,
+// f is called again with the newer cord, until the swap succeeds.
+//
+// As f may be called more than once, it must not have side effects beyond
+// computing the new cord. If f returns an error, the current cord is left
+// unchanged and the error is returned. On success, Update returns the cord
+// which has been published.
+func (s *Shared) Update(f func(Cord) (Cord, error)) (Cord, error) {
+	if f == nil {
+		return Cord{}, ErrIllegalArguments
+	}
+	for {
+		old := s.current.Load()
+		cur := Cord{}
+		if old != nil {
+			cur = *old
+		}
+		next, err := f(cur)
+		if err != nil {
+			return cur, err
+		}
+		if s.current.CompareAndSwap(old, &next) {
+			return next, nil
+		}
+		tracer().Debugf("cords: shared update lost race, retrying")
+	}
+}
@@ -0,0 +1,127 @@
+package cords
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/npillmayer/schuko/tracing/gotestingadapter"
+)
+
+func TestSharedZeroValueIsEmpty(t *testing.T) {
+	var s Shared
+	if !s.Load().IsVoid() {
+		t.Fatalf("expected zero Shared to hold the empty cord")
+	}
+	c, err := s.Update(func(c Cord) (Cord, error) {
+		return Concat(c, FromString("Hello")), nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected update error: %v", err)
+	}
+	if c.String() != "Hello" || s.Load().String() != "Hello" {
+		t.Fatalf("unexpected shared cord %q", s.Load().String())
+	}
+}
+
+func TestSharedUpdateErrorKeepsCord(t *testing.T) {
+	teardown := gotestingadapter.QuickConfig(t, "cords")
+	defer teardown()
+	//
+	s := NewShared(FromString("Hello World"))
+	snapshot := s.Load()
+	failure := errors.New("failing update")
+	_, err := s.Update(func(c Cord) (Cord, error) {
+		return Cord{}, failure
+	})
+	if !errors.Is(err, failure) {
+		t.Fatalf("expected update error to be passed through, got %v", err)
+	}
+	if s.Load().String() != "Hello World" || snapshot.String() != "Hello World" {
+		t.Fatalf("failing update changed shared cord to %q", s.Load().String())
+	}
+	if _, err = s.Update(nil); !errors.Is(err, ErrIllegalArguments) {
+		t.Fatalf("expected ErrIllegalArguments for nil update, got %v", err)
+	}
+}
+
+// TestSharedConcurrentReaders is meant to be run with the race detector.
+// Writers append and cut fixed-width records; readers verify that every
+// snapshot they observe is made of complete records and does not change while
+// being inspected.
+func TestSharedConcurrentReaders(t *testing.T) {
+	const (
+		writers   = 4
+		readers   = 8
+		rounds    = 100
+		recordLen = 8 // "w0-0000\n"
+	)
+	s := NewShared(Cord{})
+	var wg sync.WaitGroup
+	done := make(chan struct{})
+	for w := range writers {
+		wg.Go(func() {
+			for i := range rounds {
+				record := FromString(fmt.Sprintf("w%d-%04d\n", w, i))
+				_, err := s.Update(func(c Cord) (Cord, error) {
+					if i%10 == 9 && c.Len() >= 2*recordLen {
+						// cut a record from the middle to exercise rebalancing
+						mid := (c.Len() / recordLen / 2) * recordLen
+						c, _, err := Cut(c, mid, recordLen)
+						return c, err
+					}
+					return Insert(c, record, (c.Len()/recordLen/3)*recordLen)
+				})
+				if err != nil {
+					t.Errorf("writer %d: update failed: %v", w, err)
+					return
+				}
+			}
+		})
+	}
+	var rwg sync.WaitGroup
+	for r := range readers {
+		rwg.Go(func() {
+			for {
+				select {
+				case <-done:
+					return
+				default:
+				}
+				snapshot := s.Load()
+				text := snapshot.String()
+				if uint64(len(text)) != snapshot.Len() {
+					t.Errorf("reader %d: snapshot length drift: %d != %d", r, len(text), snapshot.Len())
+					return
+				}
+				if uint64(strings.Count(text, "\n")) != snapshot.LineCount() {
+					t.Errorf("reader %d: snapshot line count drift", r)
+					return
+				}
+				if len(text)%recordLen != 0 {
+					t.Errorf("reader %d: observed partial record", r)
+					return
+				}
+				for i := 0; i < len(text); i += recordLen {
+					if text[i] != 'w' || text[i+recordLen-1] != '\n' {
+						t.Errorf("reader %d: malformed record %q", r, text[i:i+recordLen])
+						return
+					}
+				}
+				if again := snapshot.String(); again != text {
+					t.Errorf("reader %d: snapshot changed while being read", r)
+					return
+				}
+			}
+		})
+	}
+	wg.Wait()
+	close(done)
+	rwg.Wait()
+	cuts := writers * (rounds / 10)
+	if got := s.Load().Len() / recordLen; got != uint64(writers*rounds-2*cuts) {
+		t.Fatalf("expected %d records, got %d", writers*rounds-2*cuts, got)
+	}
+}
@@ -0,0 +1,29 @@
+package styled
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/npillmayer/cords/btree"
+)
+
+// WriteDot writes the tree structure of one or more style run sets in Graphviz
+// DOT format, labeling leaves with run lengths and styles. Passing several
+// versions shows which parts of the tree they share.
+func WriteDot(w io.Writer, runs ...Runs) error {
+	trees := make([]*btree.Tree[Run, Summary, btree.NO_EXT], len(runs))
+	for i, r := range runs {
+		trees[i] = r.tree
+	}
+	return btree.WriteDotVersions(w, runLabel, trees...)
+}
+
+// WriteJSON writes the tree structure of runs as indented JSON, see
+// btree.NodeDump.
+func WriteJSON(w io.Writer, runs Runs) error {
+	return btree.WriteJSON(w, runs.tree, runLabel)
+}
+
+func runLabel(run Run) string {
+	return fmt.Sprintf("%d:%v", run.length, run.style)
+}
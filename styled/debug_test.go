@@ -0,0 +1,29 @@
+package styled
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestWriteDotRuns(t *testing.T) {
+	runs, err := newRuns()
+	if err != nil {
+		t.Fatal(err)
+	}
+	runs, err = runs.InsertAt(0, 10, teststyle("bold"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := WriteDot(&buf, runs); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+	if !strings.Contains(buf.String(), "10:(bold)") {
+		t.Errorf("expected run label in DOT output:\n%s", buf.String())
+	}
+	buf.Reset()
+	if err := WriteJSON(&buf, runs); err != nil || !strings.Contains(buf.String(), `"weight": 1`) {
+		t.Errorf("unexpected JSON output (%v):\n%s", err, buf.String())
+	}
+}
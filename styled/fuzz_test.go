@@ -0,0 +1,123 @@
+package styled
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/npillmayer/cords/btree"
+)
+
+type runTree = *btree.Tree[Run, Summary, btree.NO_EXT]
+
+// runVersion is a tree of runs together with the runs it is expected to hold.
+type runVersion struct {
+	tree  runTree
+	model []Run
+}
+
+// FuzzRunTreeOps drives random InsertAt, DeleteRange, SplitAt and Concat
+// sequences on trees of style runs against a slice model, three bytes per
+// operation. Adjacent runs are deliberately not normalized, so run summaries
+// merge equal styles across node boundaries.
+func FuzzRunTreeOps(f *testing.F) {
+	f.Add([]byte{0, 0, 7, 0, 1, 7, 3, 0, 0, 2, 5, 0, 1, 2, 3})
+	f.Add([]byte{0, 0, 255, 3, 0, 0, 3, 0, 0, 2, 17, 0, 1, 5, 3, 2, 200, 0})
+	f.Fuzz(func(t *testing.T, ops []byte) {
+		if len(ops) > 3*48 {
+			ops = ops[:3*48]
+		}
+		runs, err := newRuns()
+		if err != nil {
+			t.Fatalf("new runs failed: %v", err)
+		}
+		versions := []runVersion{{tree: runs.tree}}
+		for step := 0; step+2 < len(ops); step += 3 {
+			cur := versions[len(versions)-1]
+			n := int64(len(cur.model))
+			a, b := int64(ops[step+1]), int64(ops[step+2])
+			var next runVersion
+			var desc string
+			switch ops[step] % 4 {
+			case 0:
+				pos := a % (n + 1)
+				items := make([]Run, b%8+1)
+				for i := range items {
+					items[i] = Run{length: uint64(b)%5 + 1, style: teststyle(string(rune('a' + (b+int64(i))%3)))}
+				}
+				desc = fmt.Sprintf("InsertAt(%d, %d runs)", pos, len(items))
+				next.tree, err = cur.tree.InsertAt(pos, items...)
+				next.model = concatRuns(cur.model[:pos], items, cur.model[pos:])
+			case 1:
+				if n == 0 {
+					continue
+				}
+				pos := a % n
+				count := b%(n-pos) + 1
+				desc = fmt.Sprintf("DeleteRange(%d, %d)", pos, count)
+				next.tree, err = cur.tree.DeleteRange(pos, count)
+				next.model = concatRuns(cur.model[:pos], cur.model[pos+count:])
+			case 2:
+				pos := a % (n + 1)
+				desc = fmt.Sprintf("SplitAt(%d)", pos)
+				var left, right runTree
+				if left, right, err = cur.tree.SplitAt(pos); err == nil {
+					checkRunVersion(t, desc+" left", runVersion{left, cur.model[:pos]})
+					checkRunVersion(t, desc+" right", runVersion{right, cur.model[pos:]})
+					next.tree, err = right.Concat(left)
+				}
+				next.model = concatRuns(cur.model[pos:], cur.model[:pos])
+			case 3:
+				if n > 300 {
+					continue
+				}
+				desc = "Concat(self)"
+				next.tree, err = cur.tree.Concat(cur.tree)
+				next.model = concatRuns(cur.model, cur.model)
+			}
+			if err != nil {
+				t.Fatalf("step %d: %s failed: %v", step/3, desc, err)
+			}
+			checkRunVersion(t, fmt.Sprintf("step %d: %s", step/3, desc), next)
+			checkRunVersion(t, fmt.Sprintf("step %d: %s: input", step/3, desc), cur)
+			versions = append(versions, next)
+		}
+		for i, v := range versions {
+			checkRunVersion(t, fmt.Sprintf("version %d", i), v)
+		}
+	})
+}
+
+func concatRuns(parts ...[]Run) []Run {
+	var model []Run
+	for _, p := range parts {
+		model = append(model, p...)
+	}
+	return model
+}
+
+func checkRunVersion(t *testing.T, desc string, v runVersion) {
+	t.Helper()
+	report, err := v.tree.Validate()
+	if err != nil {
+		t.Fatalf("%s: validate failed: %v", desc, err)
+	}
+	if !report.OK() {
+		t.Fatalf("%s: %s", desc, report)
+	}
+	if v.tree.Len() != int64(len(v.model)) {
+		t.Fatalf("%s: tree has %d runs, model %d", desc, v.tree.Len(), len(v.model))
+	}
+	var sum Summary
+	var i int
+	v.tree.ForEachItem(func(run Run) bool {
+		if run.length != v.model[i].length || !equals(run.style, v.model[i].style) {
+			t.Fatalf("%s: run %d differs from model", desc, i)
+		}
+		sum = monoid{}.Add(sum, run.Summary())
+		i++
+		return true
+	})
+	if fmt.Sprint(sum.runs) != fmt.Sprint(v.tree.Summary().runs) {
+		t.Fatalf("%s: summary %v differs from model %v", desc, v.tree.Summary().runs, sum.runs)
+	}
+}
@@ -97,6 +97,9 @@ func (t Text) Style(sty Style, from, to uint64) (Text, error) {
 
 // --- Runs of Styles --------------------------------------------------------
 
+// merge concatenates two lists of runs, joining adjacent runs of equal style.
+// Both arguments may be summaries cached in tree nodes and are never modified;
+// the result does not share storage with runs1.
 func merge(runs1, runs2 []Run) []Run {
 	if len(runs1) == 0 {
 		return runs2
@@ -107,13 +110,16 @@ func merge(runs1, runs2 []Run) []Run {
 	l1 := runs1[len(runs1)-1]
 	l2 := runs2[0]
 	//tracer().Debugf("l1 = %v, l2 = %v", l1, l2)
+	rr := make([]Run, 0, len(runs1)+len(runs2))
 	if equals(l1.style, l2.style) {
 		r := l1
 		r.length += l2.length
-		rr := append(runs1[:len(runs1)-1], r)
+		rr = append(rr, runs1[:len(runs1)-1]...)
+		rr = append(rr, r)
 		return append(rr, runs2[1:]...)
 	}
-	return append(runs1, runs2...)
+	rr = append(rr, runs1...)
+	return append(rr, runs2...)
 }
 
 func equals(s1, s2 Style) bool {
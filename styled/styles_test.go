@@ -133,6 +133,26 @@ func TestStyleMergesAdjacentEqualStyles(t *testing.T) {
 	assertRunsInvariant(t, got, text.Raw().Len())
 }
 
+func TestMergeDoesNotModifyRuns(t *testing.T) {
+	bold, italic := teststyle("bold"), teststyle("italic")
+	// Spare capacity, as summaries cached in tree nodes may have.
+	runs1 := make([]Run, 2, 8)
+	runs1[0], runs1[1] = Run{length: 3, style: nil}, Run{length: 4, style: bold}
+	withBold := merge(runs1, []Run{{length: 2, style: bold}, {length: 1, style: nil}})
+	withItalic := merge(runs1, []Run{{length: 5, style: italic}})
+	assertRunsEqual(t, runs1, []Run{{length: 3, style: nil}, {length: 4, style: bold}})
+	assertRunsEqual(t, withBold, []Run{
+		{length: 3, style: nil},
+		{length: 6, style: bold},
+		{length: 1, style: nil},
+	})
+	assertRunsEqual(t, withItalic, []Run{
+		{length: 3, style: nil},
+		{length: 4, style: bold},
+		{length: 5, style: italic},
+	})
+}
+
 func TestStyleNoOpOnEmptySpan(t *testing.T) {
 	teardown := gotestingadapter.QuickConfig(t, "cords")
 	defer teardown()
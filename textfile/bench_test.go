@@ -0,0 +1,48 @@
+package textfile
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// benchFile writes about 4 MiB of text, mostly ASCII with some multi-byte
+// runes, to a temporary file.
+func benchFile(b *testing.B) (string, int64) {
+	lorem, err := os.ReadFile("lorem/lorem.txt")
+	if err != nil {
+		b.Fatal(err)
+	}
+	text := strings.Repeat(string(lorem)+"Größe ½ 🙂\n", 4<<20/len(lorem))
+	path := filepath.Join(b.TempDir(), "bench.txt")
+	if err := os.WriteFile(path, []byte(text), 0o600); err != nil {
+		b.Fatal(err)
+	}
+	return path, int64(len(text))
+}
+
+// BenchmarkLoad measures load throughput.
+func BenchmarkLoad(b *testing.B) {
+	path, size := benchFile(b)
+	b.SetBytes(size)
+	for b.Loop() {
+		if _, err := Load(path, 0, 0, nil); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkMap measures opening a file as a memory mapped cord, compared to
+// BenchmarkLoad.
+func BenchmarkMap(b *testing.B) {
+	path, size := benchFile(b)
+	b.SetBytes(size)
+	for b.Loop() {
+		m, err := Map(path, 16)
+		if err != nil {
+			b.Fatal(err)
+		}
+		_ = m.Close()
+	}
+}
@@ -2,8 +2,48 @@
 Package textfile provides API helpers to load UTF-8 text files as cords.
 
 The current implementation is aligned with the chunk/sum-tree cord core and
-uses a bounded asynchronous prefetch pipeline internally while preserving a
-synchronous `Load` API.
+uses a bounded asynchronous prefetch pipeline internally. `LoadAsync` returns
+a `Loader` as soon as the region around an initial position is loaded, and
+loads the remainder of the file in the background:
+
+	l, err := textfile.LoadAsync(ctx, "huge.txt", pos, 0, nil)
+	view, offset := l.Viewport() // show this while loading
+	cord, err := l.Wait()
+
+`Load` is the synchronous variant. For read-mostly huge files, `Map` maps
+the file into memory and returns a cord referencing the mapping instead of
+copying the text.
+
+`Load` expects UTF-8. `LoadEncoded` also reads ISO-8859-1, Windows-1252,
+UTF-16 or any golang.org/x/text encoding, given explicitly or detected from
+a byte order mark and the file content, and transcodes to UTF-8 while
+streaming. It reports the file's `Format`, its encoding and dominant line
+ending, and may normalize line endings to LF. Passing the format to `Save` in
+`SaveOptions` writes the text back in the original encoding and with the
+original line endings.
+
+`ReadFrom` loads text from any `io.Reader`, such as a pipe or an HTTP body,
+in the same way. Input compressed with gzip is decompressed transparently,
+recognized by a ".gz" file extension or by its magic bytes, and `Save`
+compresses it again.
+
+`Watch` observes a loaded file for modifications on disk, using inotify on
+Linux and polling elsewhere. If the file has just grown, only the new tail is
+read and concatenated to the text; otherwise the file is reloaded and the new
+text is reported together with the `Diff` against the previous one, so that
+clients can keep their own edits.
+
+`Follow` serves log viewers: it follows a file like `tail -F`, yielding ever
+longer snapshots of its text, and starts over after truncation or rotation.
+
+Invalid UTF-8 makes `Load` fail. `LoadOptions.Invalid` lets `LoadEncoded`
+replace invalid bytes with U+FFFD instead, or escape them into private-use
+characters that `Save` turns back into the original bytes. The format reports
+how many bytes were invalid and where.
+
+`Save` writes a cord back to disk atomically: the text is streamed into a
+temporary file, synced and renamed over the target, optionally keeping a
+backup of the previous content.
 
 _________________________________________________________________________
 
@@ -0,0 +1,233 @@
+package textfile
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"unicode/utf8"
+
+	"github.com/npillmayer/cords"
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/unicode"
+	"golang.org/x/text/transform"
+)
+
+// Encoding is the character encoding of a text file. The zero value is UTF-8
+// without a byte order mark.
+type Encoding struct {
+	Name string // name of the encoding, e.g. "UTF-16LE"
+	BOM  bool   // text is preceded by a byte order mark
+	enc  encoding.Encoding
+	mark string // byte order mark of the encoding
+}
+
+// Encodings recognized by DetectEncoding. Other encodings may be created with
+// NewEncoding.
+var (
+	UTF8        = Encoding{Name: "UTF-8", mark: "\xef\xbb\xbf"}
+	UTF16LE     = Encoding{Name: "UTF-16LE", BOM: true, enc: unicode.UTF16(unicode.LittleEndian, unicode.IgnoreBOM), mark: "\xff\xfe"}
+	UTF16BE     = Encoding{Name: "UTF-16BE", BOM: true, enc: unicode.UTF16(unicode.BigEndian, unicode.IgnoreBOM), mark: "\xfe\xff"}
+	Latin1      = Encoding{Name: "ISO-8859-1", enc: charmap.ISO8859_1}
+	Windows1252 = Encoding{Name: "windows-1252", enc: charmap.Windows1252}
+)
+
+// NewEncoding creates an encoding from a golang.org/x/text encoding, e.g.
+// japanese.ShiftJIS. Text in this encoding has no byte order mark.
+func NewEncoding(name string, enc encoding.Encoding) Encoding {
+	return Encoding{Name: name, enc: enc}
+}
+
+// WithBOM returns e with or without a byte order mark. Encodings without a
+// byte order mark ignore bom.
+func (e Encoding) WithBOM(bom bool) Encoding {
+	e.BOM = bom && e.mark != ""
+	return e
+}
+
+func (e Encoding) String() string {
+	name := e.Name
+	if name == "" {
+		name = UTF8.Name
+	}
+	if e.BOM {
+		return name + " with BOM"
+	}
+	return name
+}
+
+// sameAs reports whether e and other are the same encoding, both with or
+// without a byte order mark. Encodings are identified by name, as their
+// golang.org/x/text encodings may not be comparable.
+func (e Encoding) sameAs(other Encoding) bool {
+	return e.Name == other.Name && e.BOM == other.BOM
+}
+
+// isUTF8 reports whether text in e needs no transcoding.
+func (e Encoding) isUTF8() bool {
+	return e.enc == nil
+}
+
+// sampleSize is the number of bytes DetectEncoding is usually given by loaders.
+const sampleSize = 64 * 1024
+
+// DetectEncoding guesses the encoding of text starting with sample.
+//
+// A byte order mark decides for UTF-8 or UTF-16. Without one, many zero bytes
+// at odd or even positions indicate UTF-16. Valid UTF-8 indicates UTF-8, as
+// does text with more multi-byte UTF-8 sequences than invalid bytes, which is
+// UTF-8 with some corruption rather than a single-byte encoding.
+// Anything else is taken as Windows-1252 if it contains bytes 0x80–0x9F,
+// which are control characters in ISO-8859-1, and as ISO-8859-1 otherwise.
+// A sample may end within a UTF-8 sequence.
+func DetectEncoding(sample []byte) Encoding {
+	if e, ok := detectUnicode(sample); ok {
+		return e
+	}
+	if looksLikeUTF8(sample) {
+		return UTF8
+	}
+	for _, b := range sample {
+		if b >= 0x80 && b <= 0x9f {
+			return Windows1252
+		}
+	}
+	return Latin1
+}
+
+// detectUnicode detects UTF-8 or UTF-16 from a byte order mark, and UTF-16
+// without one from zero bytes at odd or even positions.
+func detectUnicode(sample []byte) (Encoding, bool) {
+	for _, e := range []Encoding{UTF8, UTF16LE, UTF16BE} {
+		if bytes.HasPrefix(sample, []byte(e.mark)) {
+			return e.WithBOM(true), true
+		}
+	}
+	var zeros [2]int
+	for i, b := range sample {
+		if b == 0 {
+			zeros[i%2]++
+		}
+	}
+	pairs := len(sample) / 2
+	switch {
+	case pairs > 0 && zeros[1] > pairs/4 && zeros[0]*8 <= zeros[1]:
+		return UTF16LE.WithBOM(false), true
+	case pairs > 0 && zeros[0] > pairs/4 && zeros[1]*8 <= zeros[0]:
+		return UTF16BE.WithBOM(false), true
+	}
+	return Encoding{}, false
+}
+
+// looksLikeUTF8 reports whether sample is valid UTF-8, up to a rune cut at its
+// end, or has more multi-byte sequences than invalid bytes.
+func looksLikeUTF8(sample []byte) bool {
+	var multi, invalid int
+	for i := 0; i < len(sample); {
+		if sample[i] < utf8.RuneSelf {
+			i++
+			continue
+		}
+		r, size := utf8.DecodeRune(sample[i:])
+		switch {
+		case r != utf8.RuneError || size > 1:
+			multi++
+		case !utf8.FullRune(sample[i:]):
+			// rune cut at the end of the sample
+		default:
+			invalid++
+		}
+		i += size
+	}
+	return invalid == 0 || multi > invalid
+}
+
+// LoadOptions control LoadEncoded and ReadFrom. The zero value is ready to use.
+type LoadOptions struct {
+	// Encoding is the encoding of the file. If it is nil, the encoding is
+	// detected from the start of the file with DetectEncoding, unless Invalid
+	// is set.
+	Encoding *Encoding
+	// NormalizeLineEndings converts all line endings to LF.
+	NormalizeLineEndings bool
+	// Invalid decides how bytes which are not valid UTF-8 are treated in files
+	// encoded in UTF-8. Decoders of other encodings always replace invalid
+	// input by U+FFFD.
+	//
+	// Setting a policy other than FailInvalid declares the file to be UTF-8
+	// which may be corrupt. Without Encoding, a file is then loaded as UTF-8
+	// unless a byte order mark or its content indicate UTF-16, even if
+	// DetectEncoding would take a few invalid bytes as a single-byte encoding.
+	Invalid InvalidPolicy
+	// FragSize is the read buffer size, see Load.
+	FragSize int64
+}
+
+// LoadEncoded reads a text file in any encoding and materializes it as a
+// cord, transcoding it to UTF-8 while streaming. It returns the format of the
+// file, its encoding and dominant line ending, which may be passed to Save to
+// write the text back in the same format. A byte order mark is not part of
+// the cord. Files compressed with gzip, recognized by a ".gz" extension or by
+// their content, are decompressed transparently.
+//
+// By default, files in UTF-8 have to be valid UTF-8, as for Load; see
+// LoadOptions.Invalid for lossy and reversible alternatives.
+func LoadEncoded(name string, opts LoadOptions) (cords.Cord, Format, error) {
+	tf, err := openFile(name)
+	if err != nil {
+		return cords.Cord{}, Format{}, err
+	}
+	defer func() {
+		_ = tf.file.Close()
+	}()
+	tracer().Infof("opened file %s", tf.info.Name())
+	opts.FragSize = normalizeFragSize(opts.FragSize, tf.info.Size())
+	return readFrom(context.Background(), tf.file, hasGzipExt(name), opts)
+}
+
+// encodeTo writes the text of cord to w in format f, preceded by a byte order
+// mark if the encoding asks for it, and compresses it if f.Gzip is set.
+func encodeTo(w io.Writer, cord cords.Cord, f Format) error {
+	if f.Gzip {
+		zw := gzip.NewWriter(w)
+		f.Gzip = false
+		if err := encodeTo(zw, cord, f); err != nil {
+			return err
+		}
+		return zw.Close()
+	}
+	e := f.Encoding
+	if e.BOM {
+		if _, err := io.WriteString(w, e.mark); err != nil {
+			return err
+		}
+	}
+	var tw io.WriteCloser
+	switch {
+	case !e.isUTF8():
+		tw = transform.NewWriter(w, e.enc.NewEncoder())
+		w = tw
+	case f.Escaped:
+		tw = transform.NewWriter(w, unescaper{})
+		w = tw
+	}
+	var lw *lineEndingWriter
+	if f.Normalized && f.LineEnding != cords.LF {
+		lw = &lineEndingWriter{w: w, marker: f.LineEnding.Marker()}
+		w = lw
+	}
+	if _, err := cord.WriteTo(w); err != nil {
+		return fmt.Errorf("cannot encode text in %s: %w", e, err)
+	}
+	if lw != nil {
+		if err := lw.Close(); err != nil {
+			return err
+		}
+	}
+	if tw != nil {
+		return tw.Close()
+	}
+	return nil
+}
@@ -0,0 +1,121 @@
+package textfile
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/npillmayer/cords"
+	"github.com/npillmayer/schuko/tracing/gotestingadapter"
+)
+
+func TestDetectEncoding(t *testing.T) {
+	for _, c := range []struct {
+		sample []byte
+		want   string
+	}{
+		{[]byte("plain ascii"), "UTF-8"},
+		{[]byte("Gr\xc3\xb6\xc3\x9fe \xf0\x9f"), "UTF-8"}, // truncated rune at sample end
+		{[]byte("\xef\xbb\xbfGr\xc3\xb6\xc3\x9fe"), "UTF-8 with BOM"},
+		{[]byte("\xff\xfeG\x00r\x00"), "UTF-16LE with BOM"},
+		{[]byte("\xfe\xff\x00G\x00r"), "UTF-16BE with BOM"},
+		{[]byte("G\x00r\x00\xf6\x00\xdf\x00e\x00"), "UTF-16LE"},
+		{[]byte("\x00G\x00r\x00\xf6\x00\xdf\x00e"), "UTF-16BE"},
+		{[]byte("Gr\xf6\xdfe"), "ISO-8859-1"},
+		{[]byte("\x80 5, \x93quoted\x94"), "windows-1252"},
+	} {
+		if got := DetectEncoding(c.sample).String(); got != c.want {
+			t.Errorf("DetectEncoding(%q) = %s, want %s", c.sample, got, c.want)
+		}
+	}
+}
+
+func TestLoadEncodedAndSaveRoundTrip(t *testing.T) {
+	teardown := gotestingadapter.QuickConfig(t, "cords")
+	defer teardown()
+
+	dir := t.TempDir()
+	for _, c := range []struct {
+		name string
+		raw  []byte
+		text string
+	}{
+		{"latin1", bytes.Repeat([]byte("Gr\xf6\xdfe\n"), 5000), "Größe\n"},
+		{"cp1252", bytes.Repeat([]byte("\x80 5, \x93x\x94\n"), 5000), "€ 5, “x”\n"},
+		{"utf16le", append([]byte("\xff\xfe"), bytes.Repeat([]byte("G\x00\xf6\x00=\xd8\x42\xde\n\x00"), 5000)...), "Gö🙂\n"},
+		{"utf16be", bytes.Repeat([]byte("\x00G\x00\xf6\xd8=\xdeB\x00\n"), 5000), "Gö🙂\n"},
+		{"utf8bom", append([]byte("\xef\xbb\xbf"), bytes.Repeat([]byte("Gr\xc3\xb6\xc3\x9fe\n"), 5000)...), "Größe\n"},
+	} {
+		path := filepath.Join(dir, c.name)
+		if err := os.WriteFile(path, c.raw, 0o600); err != nil {
+			t.Fatal(err)
+		}
+		cord, format, err := LoadEncoded(path, LoadOptions{FragSize: 1000})
+		if err != nil {
+			t.Fatalf("%s: LoadEncoded failed: %v", c.name, err)
+		}
+		if want := string(bytes.Repeat([]byte(c.text), 5000)); cord.String() != want {
+			t.Fatalf("%s: unexpected text %.40q… (%s)", c.name, cord.String(), format)
+		}
+		if err := Save(path, cord, SaveOptions{Format: format}); err != nil {
+			t.Fatalf("%s: Save failed: %v", c.name, err)
+		}
+		if b, _ := os.ReadFile(path); !bytes.Equal(b, c.raw) {
+			t.Errorf("%s: saved bytes differ from original (%s)", c.name, format)
+		}
+	}
+}
+
+func TestSaveUnrepresentableText(t *testing.T) {
+	teardown := gotestingadapter.QuickConfig(t, "cords")
+	defer teardown()
+
+	path := filepath.Join(t.TempDir(), "latin1")
+	if err := os.WriteFile(path, []byte("Gr\xf6\xdfe"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := Save(path, cords.FromString("Größe 🙂"), SaveOptions{Format: Format{Encoding: Latin1}}); err == nil {
+		t.Fatalf("expected error for text not representable in ISO-8859-1")
+	}
+	if b, _ := os.ReadFile(path); string(b) != "Gr\xf6\xdfe" {
+		t.Errorf("expected file to be unchanged, have %q", b)
+	}
+	assertOnlyFiles(t, filepath.Dir(path), "latin1")
+	// Explicit UTF-8 stays strict.
+	utf8 := UTF8
+	if _, _, err := LoadEncoded(path, LoadOptions{Encoding: &utf8}); err == nil {
+		t.Errorf("expected invalid UTF-8 to be rejected")
+	}
+}
+
+func TestLoadNormalizesAndSaveRestoresLineEndings(t *testing.T) {
+	teardown := gotestingadapter.QuickConfig(t, "cords")
+	defer teardown()
+
+	path := filepath.Join(t.TempDir(), "dos.txt")
+	raw := bytes.Repeat([]byte("Gr\xf6\xdfe\r\n"), 3000)
+	if err := os.WriteFile(path, append(raw, "stray\n\r"...), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cord, format, err := LoadEncoded(path, LoadOptions{NormalizeLineEndings: true})
+	if err != nil {
+		t.Fatalf("LoadEncoded failed: %v", err)
+	}
+	if format.String() != "ISO-8859-1, CRLF" || !format.Normalized {
+		t.Fatalf("unexpected format %s, normalized=%v", format, format.Normalized)
+	}
+	want := strings.Repeat("Größe\n", 3000) + "stray\n\n"
+	if cord.String() != want {
+		t.Fatalf("expected line endings normalized to LF")
+	}
+	edited, _ := cords.Insert(cord, cords.FromString("new\n"), 0)
+	if err := Save(path, edited, SaveOptions{Format: format}); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	b, _ := os.ReadFile(path)
+	if want := "new\r\n" + string(raw) + "stray\r\n\r\n"; string(b) != want {
+		t.Errorf("expected CRLF line endings on save, have %.60q…", b)
+	}
+}
@@ -0,0 +1,205 @@
+package textfile
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"iter"
+	"os"
+	"time"
+
+	"github.com/npillmayer/cords"
+)
+
+// Follow follows the UTF-8 text file name as it grows, like `tail -F`. It
+// yields a snapshot of the text read so far and, whenever bytes are appended
+// to the file, a new snapshot which is the previous one concatenated with the
+// new text. Runes split across writes are held back until they are complete.
+//
+// If the file is truncated, or replaced by a new file as in log rotation, the
+// rest of the old file is read, then Follow yields an empty cord and starts
+// over with the file now found under name.
+//
+// The sequence ends when the consumer stops iterating or ctx is cancelled,
+// or after yielding an error, e.g. for invalid UTF-8. Of opts.Load, only
+// FragSize is used.
+func Follow(ctx context.Context, name string, opts WatchOptions) iter.Seq2[cords.Cord, error] {
+	return func(yield func(cords.Cord, error) bool) {
+		fl := &follower{name: name, opts: opts}
+		if err := fl.start(); err != nil {
+			yield(cords.Cord{}, err)
+			return
+		}
+		defer fl.close()
+		var tick <-chan time.Time
+		var events <-chan struct{}
+		if fl.notify != nil {
+			events = fl.notify.C
+		} else {
+			ticker := time.NewTicker(fl.opts.Interval)
+			defer ticker.Stop()
+			tick = ticker.C
+		}
+		_, err := fl.read()
+		grown := true // the first snapshot is yielded even if empty
+		for {
+			if err != nil {
+				yield(cords.Cord{}, err)
+				return
+			}
+			if grown && !yield(fl.cord, nil) {
+				return
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-tick:
+			case _, ok := <-events:
+				if !ok {
+					yield(cords.Cord{}, fl.notify.Err())
+					return
+				}
+			}
+			var restart bool
+			if restart, err = fl.replaced(); err != nil {
+				continue
+			} else if !restart {
+				grown, err = fl.read()
+				continue
+			}
+			// Read the rest of the old file before starting over.
+			if grown, err = fl.read(); err != nil {
+				continue
+			}
+			if grown && !yield(fl.cord, nil) {
+				return
+			}
+			if err = fl.reopen(); err == nil {
+				if !yield(fl.cord, nil) {
+					return
+				}
+				grown, err = fl.read()
+			}
+		}
+	}
+}
+
+// follower holds the state of Follow.
+type follower struct {
+	name    string
+	opts    WatchOptions
+	notify  *notifier // nil if polling
+	file    *os.File
+	info    fs.FileInfo // info of file as opened
+	offset  int64       // bytes read from file
+	pending []byte      // start of a rune split by the end of the file
+	buf     []byte
+	cord    cords.Cord
+}
+
+func (fl *follower) start() error {
+	if fl.opts.Interval <= 0 {
+		fl.opts.Interval = defaultPollInterval
+	}
+	fl.buf = make([]byte, normalizeFragSize(fl.opts.Load.FragSize, oneMb))
+	if !fl.opts.Poll {
+		n, err := newNotifier(fl.name)
+		if err != nil {
+			tracer().Infof("following %s by polling: %v", fl.name, err)
+		}
+		fl.notify = n
+	}
+	if err := fl.open(); err != nil {
+		fl.close()
+		return err
+	}
+	return nil
+}
+
+func (fl *follower) open() error {
+	tf, err := openFile(fl.name)
+	if err != nil {
+		return err
+	}
+	if fl.info, err = tf.file.Stat(); err != nil {
+		_ = tf.file.Close()
+		return err
+	}
+	fl.file = tf.file
+	return nil
+}
+
+func (fl *follower) close() {
+	if fl.file != nil {
+		_ = fl.file.Close()
+	}
+	if fl.notify != nil {
+		_ = fl.notify.Close()
+	}
+}
+
+// read appends the bytes written to the file since the last read to the
+// text, except for an incomplete rune at its end. It reports whether the text
+// has grown.
+func (fl *follower) read() (bool, error) {
+	var b cords.Builder
+	grown := false
+	for {
+		n, readErr := fl.file.Read(fl.buf)
+		if n > 0 {
+			fl.offset += int64(n)
+			data := append(fl.pending, fl.buf[:n]...)
+			prefix, tail, err := splitValidUTF8Prefix(data)
+			if err != nil {
+				return false, err
+			}
+			if len(prefix) > 0 {
+				if err := b.AppendBytes(prefix); err != nil {
+					return false, err
+				}
+				grown = true
+			}
+			fl.pending = append(fl.pending[:0:0], tail...)
+		}
+		if readErr == io.EOF {
+			break
+		} else if readErr != nil {
+			return false, readErr
+		}
+	}
+	if grown {
+		fl.cord = cords.Concat(fl.cord, b.Cord())
+	}
+	return grown, nil
+}
+
+// replaced reports whether the file has been truncated or replaced by a
+// different file since it has been opened.
+func (fl *follower) replaced() (bool, error) {
+	info, err := os.Stat(fl.name)
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, nil // rotation in progress
+	} else if err != nil {
+		return false, err
+	}
+	if !os.SameFile(info, fl.info) {
+		tracer().Infof("%s has been replaced", fl.name)
+		return true, nil
+	}
+	if info.Size() < fl.offset {
+		tracer().Infof("%s has been truncated", fl.name)
+		return true, nil
+	}
+	return false, nil
+}
+
+// reopen starts over with the file found under name and an empty text.
+func (fl *follower) reopen() error {
+	if len(fl.pending) > 0 {
+		tracer().Infof("%s: dropping %d bytes of an incomplete rune", fl.name, len(fl.pending))
+	}
+	_ = fl.file.Close()
+	fl.file, fl.offset, fl.pending, fl.cord = nil, 0, nil, cords.Cord{}
+	return fl.open()
+}
@@ -0,0 +1,91 @@
+package textfile
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/npillmayer/cords/chunk"
+	"github.com/npillmayer/schuko/tracing/gotestingadapter"
+)
+
+func TestFollow(t *testing.T) {
+	teardown := gotestingadapter.QuickConfig(t, "cords")
+	defer teardown()
+
+	for _, poll := range []bool{false, true} {
+		t.Run(map[bool]string{false: "notify", true: "poll"}[poll], func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "app.log")
+			if err := os.WriteFile(path, []byte("start\n"), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			// Each step is taken when the snapshot has reached the expected text.
+			steps := []struct {
+				want string
+				act  func()
+			}{
+				{"start\n", func() { appendFile(t, path, "x\xc3") }},
+				{"start\nx", func() { appendFile(t, path, "\xa4\n") }},
+				{"start\nxä\n", func() {
+					appendFile(t, path, "late\n")
+					if err := os.Rename(path, path+".1"); err != nil {
+						t.Fatal(err)
+					}
+					if err := os.WriteFile(path, []byte("fresh\n"), 0o600); err != nil {
+						t.Fatal(err)
+					}
+				}},
+				{"fresh\n", func() {
+					if err := os.WriteFile(path, []byte("t\n"), 0o600); err != nil {
+						t.Fatal(err)
+					}
+				}},
+				{"t\n", cancel},
+			}
+			var prev string
+			var restarts int
+			var late bool
+			for cord, err := range Follow(ctx, path, WatchOptions{Poll: poll, Interval: 10 * time.Millisecond}) {
+				if err != nil {
+					t.Fatalf("Follow failed: %v", err)
+				}
+				text := cord.String()
+				if text == "" {
+					restarts++
+				} else if !strings.HasPrefix(text, prev) {
+					t.Fatalf("snapshot %q does not extend %q", text, prev)
+				}
+				late = late || text == "start\nxä\nlate\n" // rest of the rotated file
+				prev = text
+				if len(steps) > 0 && text == steps[0].want {
+					steps[0].act()
+					steps = steps[1:]
+				}
+			}
+			if len(steps) > 0 || restarts != 2 || !late {
+				t.Errorf("%d steps left, %d restarts, late=%t, last snapshot %q", len(steps), restarts, late, prev)
+			}
+		})
+	}
+}
+
+func TestFollowInvalidUTF8(t *testing.T) {
+	teardown := gotestingadapter.QuickConfig(t, "cords")
+	defer teardown()
+
+	path := filepath.Join(t.TempDir(), "app.log")
+	if err := os.WriteFile(path, []byte("ok\n\xff\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	for _, err := range Follow(context.Background(), path, WatchOptions{Poll: true}) {
+		if !errors.Is(err, chunk.ErrInvalidUTF8) {
+			t.Errorf("expected ErrInvalidUTF8, got %v", err)
+		}
+	}
+}
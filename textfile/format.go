@@ -0,0 +1,74 @@
+package textfile
+
+import (
+	"io"
+
+	"github.com/npillmayer/cords"
+)
+
+// Format describes how text is stored in a file.
+type Format struct {
+	Encoding   Encoding
+	LineEnding cords.LineEnding // dominant line ending, LF for files without any
+	// Normalized tells that the text uses LF line endings, normalized on load.
+	// Save then writes all line endings as LineEnding.
+	Normalized bool
+	// Gzip tells that the file is compressed with gzip. Save then compresses
+	// the text as well.
+	Gzip bool
+	// Escaped tells that invalid bytes have been escaped on load (see
+	// EscapeInvalid). Save then writes them back as they were.
+	Escaped bool
+	// InvalidBytes is the number of invalid bytes replaced or escaped on load,
+	// InvalidOffsets holds the file offsets of the first MaxInvalidOffsets.
+	InvalidBytes   int64
+	InvalidOffsets []int64
+}
+
+func (f Format) String() string {
+	return f.Encoding.String() + ", " + f.LineEnding.String()
+}
+
+// lineEndingWriter writes text with all line endings, "\r\n", "\r" and "\n",
+// replaced by marker. Close has to be called to write a final "\r".
+type lineEndingWriter struct {
+	w         io.Writer
+	marker    string
+	pendingCR bool // the previous write ended with '\r'
+	buf       []byte
+}
+
+func (lw *lineEndingWriter) Write(p []byte) (int, error) {
+	lw.buf = lw.buf[:0]
+	for _, b := range p {
+		switch {
+		case b == '\r':
+			if lw.pendingCR {
+				lw.buf = append(lw.buf, lw.marker...)
+			}
+			lw.pendingCR = true
+			continue
+		case b == '\n' || lw.pendingCR:
+			lw.buf = append(lw.buf, lw.marker...)
+		}
+		lw.pendingCR = false
+		if b != '\n' {
+			lw.buf = append(lw.buf, b)
+		}
+	}
+	if _, err := lw.w.Write(lw.buf); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Close writes a pending line ending. It does not close the underlying
+// writer.
+func (lw *lineEndingWriter) Close() error {
+	if !lw.pendingCR {
+		return nil
+	}
+	lw.pendingCR = false
+	_, err := io.WriteString(lw.w, lw.marker)
+	return err
+}
@@ -0,0 +1,132 @@
+package textfile
+
+import (
+	"fmt"
+	"unicode/utf8"
+
+	"github.com/npillmayer/cords/chunk"
+	"golang.org/x/text/transform"
+)
+
+// InvalidPolicy decides how LoadEncoded treats bytes of a UTF-8 file which are
+// not valid UTF-8.
+type InvalidPolicy uint8
+
+const (
+	// FailInvalid makes loading fail with an error wrapping
+	// chunk.ErrInvalidUTF8.
+	FailInvalid InvalidPolicy = iota
+	// ReplaceInvalid replaces each invalid byte by U+FFFD.
+	ReplaceInvalid
+	// EscapeInvalid replaces each invalid byte b by the private use code point
+	// U+10FE00+b, which Save turns back into b. Valid characters in this range
+	// are escaped byte-wise as well, so that a file is saved byte-identically.
+	EscapeInvalid
+)
+
+func (p InvalidPolicy) String() string {
+	switch p {
+	case ReplaceInvalid:
+		return "replace"
+	case EscapeInvalid:
+		return "escape"
+	}
+	return "fail"
+}
+
+// MaxInvalidOffsets is the maximum number of invalid byte offsets reported in
+// a Format.
+const MaxInvalidOffsets = 1000
+
+// escapeBase is the code point escaping byte 0: byte b is escaped as
+// escapeBase+b. Only bytes 0x80–0xFF are ever escaped.
+const escapeBase = 0x10FE00
+
+func isEscape(r rune) bool {
+	return r >= escapeBase+0x80 && r <= escapeBase+0xFF
+}
+
+// sanitizer is a transformer applying an InvalidPolicy to UTF-8 text and
+// recording the offsets of invalid bytes in a Format.
+type sanitizer struct {
+	policy InvalidPolicy
+	offset int64 // file offset of the next source byte
+	format *Format
+}
+
+func (s *sanitizer) Reset() {}
+
+func (s *sanitizer) Transform(dst, src []byte, atEOF bool) (nDst, nSrc int, err error) {
+	defer func() {
+		s.offset += int64(nSrc)
+	}()
+	var escaped [utf8.UTFMax * utf8.UTFMax]byte
+	for nSrc < len(src) {
+		if b := src[nSrc]; b < utf8.RuneSelf {
+			if nDst == len(dst) {
+				return nDst, nSrc, transform.ErrShortDst
+			}
+			dst[nDst] = b
+			nDst, nSrc = nDst+1, nSrc+1
+			continue
+		}
+		r, size := utf8.DecodeRune(src[nSrc:])
+		invalid := r == utf8.RuneError && size == 1
+		if invalid && !atEOF && !utf8.FullRune(src[nSrc:]) {
+			return nDst, nSrc, transform.ErrShortSrc
+		}
+		out := src[nSrc : nSrc+size]
+		switch {
+		case invalid && s.policy == FailInvalid:
+			return nDst, nSrc, fmt.Errorf("%w: at byte %d", chunk.ErrInvalidUTF8, s.offset+int64(nSrc))
+		case invalid && s.policy == ReplaceInvalid:
+			out = utf8.AppendRune(escaped[:0], utf8.RuneError)
+		case invalid || s.policy == EscapeInvalid && isEscape(r):
+			out = escaped[:0]
+			for _, b := range src[nSrc : nSrc+size] {
+				out = utf8.AppendRune(out, escapeBase+rune(b))
+			}
+		}
+		if len(dst)-nDst < len(out) {
+			return nDst, nSrc, transform.ErrShortDst
+		}
+		if invalid {
+			s.format.InvalidBytes++
+			if len(s.format.InvalidOffsets) < MaxInvalidOffsets {
+				s.format.InvalidOffsets = append(s.format.InvalidOffsets, s.offset+int64(nSrc))
+			}
+		}
+		nDst += copy(dst[nDst:], out)
+		nSrc += size
+	}
+	return nDst, nSrc, nil
+}
+
+// unescaper is a transformer turning bytes escaped by EscapeInvalid back into
+// raw bytes.
+type unescaper struct{}
+
+func (unescaper) Reset() {}
+
+func (unescaper) Transform(dst, src []byte, atEOF bool) (nDst, nSrc int, err error) {
+	for nSrc < len(src) {
+		r, size := utf8.DecodeRune(src[nSrc:])
+		if r == utf8.RuneError && size == 1 && !atEOF && !utf8.FullRune(src[nSrc:]) {
+			return nDst, nSrc, transform.ErrShortSrc
+		}
+		if isEscape(r) {
+			if nDst == len(dst) {
+				return nDst, nSrc, transform.ErrShortDst
+			}
+			dst[nDst] = byte(r - escapeBase)
+			nDst, nSrc = nDst+1, nSrc+size
+			continue
+		}
+		if len(dst)-nDst < size {
+			return nDst, nSrc, transform.ErrShortDst
+		}
+		nDst += copy(dst[nDst:], src[nSrc:nSrc+size])
+		nSrc += size
+	}
+	return nDst, nSrc, nil
+}
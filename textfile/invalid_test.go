@@ -0,0 +1,107 @@
+package textfile
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/npillmayer/cords/chunk"
+	"github.com/npillmayer/schuko/tracing/gotestingadapter"
+)
+
+func TestInvalidUTF8Policies(t *testing.T) {
+	teardown := gotestingadapter.QuickConfig(t, "cords")
+	defer teardown()
+
+	// A log with invalid bytes, a truncated rune at the end and a literal
+	// character from the escape range.
+	line := []byte("Größe \xff\xfe ok \xf4\x8f\xba\x85 \xc3(\n")
+	raw := append(bytes.Repeat(line, 300), "tail \xe2\x82"...)
+	path := filepath.Join(t.TempDir(), "log.txt")
+	if err := os.WriteFile(path, raw, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	wantOffsets := []int64{8, 9, 19}
+	utf8 := UTF8
+	_, _, err := LoadEncoded(path, LoadOptions{Encoding: &utf8, FragSize: 7})
+	if !errors.Is(err, chunk.ErrInvalidUTF8) || !strings.Contains(err.Error(), "at byte 8") {
+		t.Fatalf("expected ErrInvalidUTF8 at byte 8, got %v", err)
+	}
+	cord, format, err := LoadEncoded(path, LoadOptions{Invalid: ReplaceInvalid, FragSize: 7})
+	if err != nil {
+		t.Fatalf("LoadEncoded failed: %v", err)
+	}
+	if format.Encoding.Name != "UTF-8" || format.InvalidBytes != 3*300+2 {
+		t.Fatalf("unexpected format %s with %d invalid bytes", format, format.InvalidBytes)
+	}
+	if !slices.Equal(format.InvalidOffsets[:3], wantOffsets) {
+		t.Errorf("unexpected invalid offsets %v", format.InvalidOffsets[:3])
+	}
+	if !strings.HasPrefix(cord.String(), "Größe �� ok \U0010FE85 �(\n") {
+		t.Errorf("unexpected replacement: %.40q", cord.String())
+	}
+	cord, format, err = LoadEncoded(path, LoadOptions{Invalid: EscapeInvalid, FragSize: 7})
+	if err != nil {
+		t.Fatalf("LoadEncoded failed: %v", err)
+	}
+	if !format.Escaped || format.InvalidBytes != 3*300+2 {
+		t.Fatalf("unexpected format %s with %d invalid bytes", format, format.InvalidBytes)
+	}
+	if err := Save(path, cord, SaveOptions{Format: format}); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	if b, _ := os.ReadFile(path); !bytes.Equal(b, raw) {
+		t.Errorf("escaped text not saved byte-identically")
+	}
+}
+
+func TestDetectEncodingToleratesCorruptUTF8(t *testing.T) {
+	if e := DetectEncoding([]byte("Größe \xff ok")); e.Name != "UTF-8" {
+		t.Errorf("expected UTF-8 with a corrupt byte to be detected as UTF-8, have %s", e)
+	}
+}
+
+func TestInvalidPolicyWithDetectedEncoding(t *testing.T) {
+	teardown := gotestingadapter.QuickConfig(t, "cords")
+	defer teardown()
+
+	// Mostly ASCII with a few invalid bytes, which DetectEncoding alone
+	// takes as ISO-8859-1.
+	raw := []byte(strings.Repeat("request ok\n", 100) + "bad \xff byte\n" + strings.Repeat("request ok\n", 100))
+	path := filepath.Join(t.TempDir(), "log.txt")
+	if err := os.WriteFile(path, raw, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if e := DetectEncoding(raw); e.Name == "UTF-8" {
+		t.Fatalf("expected sparse invalid bytes without multi-byte runes to look like a single-byte encoding")
+	}
+	cord, format, err := LoadEncoded(path, LoadOptions{Invalid: ReplaceInvalid})
+	if err != nil {
+		t.Fatalf("LoadEncoded failed: %v", err)
+	}
+	if format.Encoding.Name != "UTF-8" || format.InvalidBytes != 1 {
+		t.Fatalf("unexpected format %s with %d invalid bytes", format, format.InvalidBytes)
+	}
+	if !slices.Equal(format.InvalidOffsets, []int64{1104}) {
+		t.Errorf("unexpected invalid offsets %v", format.InvalidOffsets)
+	}
+	if !strings.Contains(cord.String(), "bad � byte\n") {
+		t.Errorf("expected invalid byte to be replaced")
+	}
+	// A byte order mark still decides for UTF-16.
+	utf16 := append([]byte("\xff\xfe"), "o\x00k\x00"...)
+	if err := os.WriteFile(path, utf16, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cord, format, err = LoadEncoded(path, LoadOptions{Invalid: EscapeInvalid})
+	if err != nil {
+		t.Fatalf("LoadEncoded failed: %v", err)
+	}
+	if format.Encoding.Name != UTF16LE.Name || cord.String() != "ok" {
+		t.Errorf("expected UTF-16 text \"ok\", have %s text %q", format, cord.String())
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"io"
 	"os"
 	"sync"
+	"sync/atomic"
 	"unicode/utf8"
 
 	"github.com/npillmayer/cords"
@@ -21,6 +22,10 @@ const (
 	oneMb     = 1048576
 )
 
+// viewportSize is the size of the region around the initial position which is
+// loaded before LoadAsync returns.
+const viewportSize = 64 * 1024
+
 // textFile represents an OS file to be loaded as a cord.
 type textFile struct {
 	path string
@@ -28,43 +33,179 @@ type textFile struct {
 	file *os.File
 }
 
+// Loader is a handle for a file being loaded in the background, as returned by
+// LoadAsync.
+type Loader struct {
+	size     int64
+	viewport cords.Cord
+	viewPos  int64
+	loaded   atomic.Int64
+	cancel   context.CancelFunc
+	done     chan struct{}
+	cord     cords.Cord // set before done is closed
+	err      error      // set before done is closed
+}
+
 // Load reads a UTF-8 text file and materializes it as a cord.
 //
-// This migration step intentionally uses a synchronous, correctness-first load
-// path on top of the chunk/sum-tree cord implementation.
-//
-// `initialPos` and `wg` are currently accepted for API compatibility and are
-// ignored in this synchronous implementation.
+// Load is LoadAsync followed by Wait. The region around `initialPos` is read
+// first. If `wg` is not nil, it is incremented for the duration of the load.
 //
 // `fragSize` controls the read buffer size used during loading. If it is out of
 // range, a default based on file size is chosen.
+//
+// Invalid UTF-8 is an error; use LoadEncoded with LoadOptions.Invalid to view
+// files containing it.
+//
+// Files compressed with gzip are decompressed transparently. They are read from
+// the start, ignoring `initialPos`.
 func Load(name string, initialPos, fragSize int64, wg *sync.WaitGroup) (cords.Cord, error) {
-	_ = initialPos
-	if wg != nil {
-		wg.Add(1)
-		defer wg.Done()
+	if gz, err := isGzipFile(name); err != nil {
+		return cords.Cord{}, err
+	} else if gz {
+		if wg != nil {
+			wg.Add(1)
+			defer wg.Done()
+		}
+		cord, _, err := LoadEncoded(name, LoadOptions{Encoding: &UTF8, FragSize: fragSize})
+		return cord, err
 	}
+	l, err := LoadAsync(context.Background(), name, initialPos, fragSize, wg)
+	if err != nil {
+		return cords.Cord{}, err
+	}
+	return l.Wait()
+}
 
+// LoadAsync starts loading a UTF-8 text file as a cord. It returns as soon as
+// the region around `initialPos` is available from the loader's Viewport,
+// which allows an editor to display that region while the remainder of the
+// file loads in the background.
+//
+// Loading stops if ctx is cancelled or Cancel is called. If `wg` is not nil, it
+// is incremented until background loading has terminated.
+//
+// `fragSize` controls the read buffer size used during loading. If it is out of
+// range, a default based on file size is chosen.
+func LoadAsync(ctx context.Context, name string, initialPos, fragSize int64, wg *sync.WaitGroup) (*Loader, error) {
 	tf, err := openFile(name)
 	if err != nil {
-		return cords.Cord{}, err
+		return nil, err
 	}
-	defer func() {
+	tracer().Infof("opened file %s", tf.info.Name())
+	size := tf.info.Size()
+	fragSize = normalizeFragSize(fragSize, size)
+	ctx, cancel := context.WithCancel(ctx)
+	l := &Loader{size: size, cancel: cancel, done: make(chan struct{})}
+	from, to, err := l.loadViewport(tf.file, initialPos)
+	if err != nil {
+		cancel()
 		_ = tf.file.Close()
+		return nil, err
+	}
+	if wg != nil {
+		wg.Add(1)
+	}
+	go func() {
+		defer func() {
+			_ = tf.file.Close()
+			cancel()
+			close(l.done)
+			if wg != nil {
+				wg.Done()
+			}
+		}()
+		var behind, before cords.Builder
+		if to < size {
+			if l.err = l.loadRange(ctx, tf.file, to, size, fragSize, &behind); l.err != nil {
+				return
+			}
+		}
+		if from > 0 {
+			if l.err = l.loadRange(ctx, tf.file, 0, from, fragSize, &before); l.err != nil {
+				return
+			}
+		}
+		l.cord = cords.Concat(before.Cord(), l.viewport, behind.Cord())
+		tracer().Infof("loaded %d bytes from %s", l.cord.Len(), tf.info.Name())
 	}()
+	return l, nil
+}
 
-	tracer().Infof("opened file %s", tf.info.Name())
-	fragSize = normalizeFragSize(fragSize, tf.info.Size())
+// Viewport returns the region around the initial position, which is available
+// as soon as LoadAsync returns, together with its byte offset within the file.
+// The region is aligned to UTF-8 rune boundaries.
+func (l *Loader) Viewport() (cords.Cord, int64) {
+	return l.viewport, l.viewPos
+}
+
+// Progress returns the number of bytes loaded so far and the size of the file.
+func (l *Loader) Progress() (loaded, total int64) {
+	return l.loaded.Load(), l.size
+}
+
+// Done returns a channel which is closed when background loading has
+// terminated, successfully or not.
+func (l *Loader) Done() <-chan struct{} {
+	return l.done
+}
+
+// Cancel stops background loading. Wait will then report a cancellation error,
+// unless loading has already completed.
+func (l *Loader) Cancel() {
+	l.cancel()
+}
 
-	if tf.info.Size() == 0 {
-		return cords.Cord{}, nil
+// Wait blocks until background loading has terminated and returns the cord for
+// the complete file.
+func (l *Loader) Wait() (cords.Cord, error) {
+	<-l.done
+	if l.err != nil {
+		return cords.Cord{}, l.err
 	}
+	return l.cord, nil
+}
 
-	b := cords.NewBuilder()
-	if err := loadWithPrefetch(tf.file, fragSize, b); err != nil {
-		return cords.Cord{}, err
+// loadViewport loads the region of about viewportSize bytes around pos and
+// returns its bounds, which are rune boundaries.
+func (l *Loader) loadViewport(file *os.File, pos int64) (from, to int64, err error) {
+	from = max(0, min(pos, l.size)-viewportSize/2)
+	to = min(l.size, from+viewportSize)
+	buf := make([]byte, to-from)
+	if _, err := file.ReadAt(buf, from); err != nil {
+		return 0, 0, fmt.Errorf("textfile load failed: %w", err)
+	}
+	// Move the bounds off continuation bytes of runes cut by the region.
+	if from > 0 {
+		skip := 0
+		for skip < min(len(buf), utf8.UTFMax-1) && !utf8.RuneStart(buf[skip]) {
+			skip++
+		}
+		buf, from = buf[skip:], from+int64(skip)
+	}
+	if to < l.size {
+		prefix, tail, err := splitValidUTF8Prefix(buf)
+		if err != nil {
+			return 0, 0, err
+		}
+		buf, to = prefix, to-int64(len(tail))
+	}
+	var b cords.Builder
+	if err := b.AppendBytes(buf); err != nil {
+		return 0, 0, err
+	}
+	l.viewport, l.viewPos = b.Cord(), from
+	l.loaded.Add(int64(len(buf)))
+	return from, to, nil
+}
+
+// loadRange loads bytes [from,to) of file into b.
+func (l *Loader) loadRange(ctx context.Context, file *os.File, from, to, fragSize int64, b *cords.Builder) error {
+	r := io.NewSectionReader(file, from, to-from)
+	if err := loadWithPrefetch(ctx, r, fragSize, b, &l.loaded); err != nil {
+		return err
 	}
-	return b.Cord(), nil
+	return ctx.Err()
 }
 
 // openFile opens an OS file and checks basic preconditions.
@@ -109,8 +250,10 @@ func normalizeFragSize(fragSize, fileSize int64) int64 {
 	}
 }
 
-func loadWithPrefetch(file *os.File, fragSize int64, b *cords.Builder) error {
-	ctx, cancel := context.WithCancel(context.Background())
+// loadWithPrefetch appends the text read from r to b, reading ahead in a
+// separate goroutine. It adds the number of bytes appended to loaded.
+func loadWithPrefetch(ctx context.Context, r io.Reader, fragSize int64, b *cords.Builder, loaded *atomic.Int64) error {
+	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
 
 	chunks := make(chan []byte, 8)
@@ -119,7 +262,7 @@ func loadWithPrefetch(file *os.File, fragSize int64, b *cords.Builder) error {
 	go func() {
 		defer close(done)
 		defer close(chunks)
-		readFileChunks(ctx, file, fragSize, chunks, errCh)
+		readFileChunks(ctx, r, fragSize, chunks, errCh)
 	}()
 
 	for frag := range chunks {
@@ -131,6 +274,7 @@ func loadWithPrefetch(file *os.File, fragSize int64, b *cords.Builder) error {
 			}
 			return err
 		}
+		loaded.Add(int64(len(frag)))
 	}
 	<-done
 	if err := consumeErr(errCh); err != nil {
@@ -139,8 +283,7 @@ func loadWithPrefetch(file *os.File, fragSize int64, b *cords.Builder) error {
 	return nil
 }
 
-func readFileChunks(ctx context.Context, file *os.File, fragSize int64, out chan<- []byte, errCh chan<- error) {
-	reader := io.Reader(file)
+func readFileChunks(ctx context.Context, reader io.Reader, fragSize int64, out chan<- []byte, errCh chan<- error) {
 	buf := make([]byte, fragSize)
 	pending := make([]byte, 0, 3)
 	for {
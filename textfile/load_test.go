@@ -1,9 +1,11 @@
 package textfile
 
 import (
+	"context"
 	"errors"
 	"os"
 	"path/filepath"
+	"strings"
 	"sync"
 	"testing"
 
@@ -92,3 +94,66 @@ func TestLoadRejectsInvalidUTF8(t *testing.T) {
 		t.Fatalf("expected chunk.ErrInvalidUTF8, got %v", err)
 	}
 }
+
+func TestLoadAsyncViewportFirst(t *testing.T) {
+	teardown := gotestingadapter.QuickConfig(t, "cords")
+	defer teardown()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "big.txt")
+	text := strings.Repeat("Größe 🙂 lorem ipsum\n", 30000)
+	if err := os.WriteFile(path, []byte(text), 0o600); err != nil {
+		t.Fatalf("write temp file failed: %v", err)
+	}
+	var wg sync.WaitGroup
+	l, err := LoadAsync(context.Background(), path, int64(len(text)/2), 100, &wg)
+	if err != nil {
+		t.Fatalf("LoadAsync failed: %v", err)
+	}
+	view, pos := l.Viewport()
+	if view.Len() == 0 || pos == 0 || pos+int64(view.Len()) >= int64(len(text)) {
+		t.Fatalf("expected viewport in the middle of the file, have [%d,%d)", pos, pos+int64(view.Len()))
+	}
+	if view.String() != text[pos:pos+int64(view.Len())] {
+		t.Fatalf("viewport text differs from file content")
+	}
+	wg.Wait()
+	select {
+	case <-l.Done():
+	default:
+		t.Fatalf("expected loader to be done after wg.Wait")
+	}
+	cord, err := l.Wait()
+	if err != nil {
+		t.Fatalf("Wait failed: %v", err)
+	}
+	if cord.String() != text {
+		t.Fatalf("loaded text differs from file content")
+	}
+	if loaded, total := l.Progress(); loaded != total || total != int64(len(text)) {
+		t.Errorf("expected progress %d of %d, have %d of %d", len(text), len(text), loaded, total)
+	}
+}
+
+func TestLoadAsyncCancel(t *testing.T) {
+	teardown := gotestingadapter.QuickConfig(t, "cords")
+	defer teardown()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "big.txt")
+	if err := os.WriteFile(path, []byte(strings.Repeat("lorem ipsum\n", 50000)), 0o600); err != nil {
+		t.Fatalf("write temp file failed: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	l, err := LoadAsync(ctx, path, 0, 0, nil)
+	if err != nil {
+		t.Fatalf("LoadAsync failed: %v", err)
+	}
+	if view, _ := l.Viewport(); view.Len() == 0 {
+		t.Errorf("expected viewport to be loaded")
+	}
+	if _, err := l.Wait(); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+}
@@ -0,0 +1,72 @@
+package textfile
+
+import (
+	"fmt"
+	"math"
+	"sync"
+
+	"github.com/npillmayer/cords"
+)
+
+// MappedFile is a text file mapped into memory, with a cord over its text.
+type MappedFile struct {
+	cord  cords.Cord
+	unmap func() error
+	once  sync.Once
+	err   error
+}
+
+// Map maps a UTF-8 text file into memory and returns a cord over its text,
+// which references the mapping instead of copying it (see cords.FromMapped).
+// Opening a huge file therefore takes a single pass over its pages, and only
+// edited regions and at most resident subtrees of the cord are copied into
+// chunks.
+//
+// Memory mapping is used on Linux. On other systems, the file is read into
+// memory instead.
+//
+// The file must not be modified while it is mapped. The cord, and all cords
+// derived from it, must not be used after Close.
+func Map(name string, resident int) (*MappedFile, error) {
+	tf, err := openFile(name)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = tf.file.Close()
+	}()
+	size := tf.info.Size()
+	if size == 0 {
+		return &MappedFile{}, nil
+	}
+	if size > math.MaxInt {
+		return nil, fmt.Errorf("textfile map failed: %s too large", name)
+	}
+	data, unmap, err := mapFile(tf.file, int(size))
+	if err != nil {
+		return nil, fmt.Errorf("textfile map failed: %w", err)
+	}
+	cord, err := cords.FromMapped(data, resident)
+	if err != nil {
+		_ = unmap()
+		return nil, err
+	}
+	tracer().Infof("mapped %d bytes of file %s", size, tf.info.Name())
+	return &MappedFile{cord: cord, unmap: unmap}, nil
+}
+
+// Cord returns the cord over the text of the file.
+func (m *MappedFile) Cord() cords.Cord {
+	return m.cord
+}
+
+// Close releases the mapping. It is safe to call Close more than once.
+func (m *MappedFile) Close() error {
+	m.once.Do(func() {
+		if m.unmap != nil {
+			m.err = m.unmap()
+		}
+		m.cord = cords.Cord{}
+	})
+	return m.err
+}
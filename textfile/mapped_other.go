@@ -0,0 +1,18 @@
+//go:build !linux
+
+package textfile
+
+import (
+	"io"
+	"os"
+)
+
+// mapFile reads size bytes of f into memory, for systems without memory
+// mapping support in this package.
+func mapFile(f *os.File, size int) ([]byte, func() error, error) {
+	data := make([]byte, size)
+	if _, err := io.ReadFull(f, data); err != nil {
+		return nil, nil, err
+	}
+	return data, func() error { return nil }, nil
+}
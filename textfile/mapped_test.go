@@ -0,0 +1,72 @@
+package textfile
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/npillmayer/cords"
+	"github.com/npillmayer/schuko/tracing/gotestingadapter"
+)
+
+func TestMap(t *testing.T) {
+	teardown := gotestingadapter.QuickConfig(t, "cords")
+	defer teardown()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "log.txt")
+	text := strings.Repeat("2026-10-18 Größe 🙂 request served\n", 20000)
+	if err := os.WriteFile(path, []byte(text), 0o600); err != nil {
+		t.Fatalf("write temp file failed: %v", err)
+	}
+	m, err := Map(path, 4)
+	if err != nil {
+		t.Fatalf("Map failed: %v", err)
+	}
+	cord := m.Cord()
+	if cord.Len() != uint64(len(text)) || cord.LineCount() != 20000 {
+		t.Fatalf("unexpected metrics: len=%d, lines=%d", cord.Len(), cord.LineCount())
+	}
+	if u := cords.MemoryUsage(cord); u.Payload != 0 {
+		t.Errorf("expected no text to be copied on open: %v", u)
+	}
+	s, err := cord.Report(uint64(len(text)/2), 10)
+	if err != nil || s != text[len(text)/2:len(text)/2+10] {
+		t.Fatalf("unexpected report %q, %v", s, err)
+	}
+	edited, err := cords.Insert(cord, cords.FromString("edit\n"), 0)
+	if err != nil {
+		t.Fatalf("insert failed: %v", err)
+	}
+	if err := Save(filepath.Join(dir, "edited.txt"), edited, SaveOptions{}); err != nil {
+		t.Fatalf("save failed: %v", err)
+	}
+	if err := m.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if err := m.Close(); err != nil {
+		t.Fatalf("second Close failed: %v", err)
+	}
+	b, _ := os.ReadFile(filepath.Join(dir, "edited.txt"))
+	if string(b) != "edit\n"+text {
+		t.Errorf("saved text differs from edited text")
+	}
+}
+
+func TestMapEmptyFile(t *testing.T) {
+	teardown := gotestingadapter.QuickConfig(t, "cords")
+	defer teardown()
+
+	path := filepath.Join(t.TempDir(), "empty.txt")
+	if err := os.WriteFile(path, nil, 0o600); err != nil {
+		t.Fatalf("write temp file failed: %v", err)
+	}
+	m, err := Map(path, 1)
+	if err != nil {
+		t.Fatalf("Map failed: %v", err)
+	}
+	if !m.Cord().IsVoid() || m.Close() != nil {
+		t.Errorf("expected void cord for empty file")
+	}
+}
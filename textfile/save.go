@@ -0,0 +1,150 @@
+package textfile
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/npillmayer/cords"
+	"github.com/npillmayer/cords/internal/fsutil"
+)
+
+// SaveOptions control how Save writes a file. The zero value is ready to use.
+type SaveOptions struct {
+	// Perm is the permission of a newly created file. Existing files keep
+	// their permission. Defaults to 0644.
+	Perm fs.FileMode
+	// Backup keeps the previous content of an existing file as a backup file.
+	Backup bool
+	// BackupSuffix is appended to the file name to form the backup file name.
+	// Defaults to "~".
+	BackupSuffix string
+	// Format is the format to write the text in, usually the one reported by
+	// LoadEncoded. Defaults to UTF-8 without a byte order mark, with line
+	// endings written as they are.
+	Format Format
+}
+
+// Save writes the text of cord to file name, atomically replacing an existing
+// file.
+//
+// The text is streamed chunk by chunk into a temporary file in the same
+// directory, which is synced to disk and then renamed to name. Readers of name
+// therefore see either the old or the new content, never a partial file. An
+// existing file keeps its permission and, if possible, its owner and group. If
+// name is a symbolic link, the file the link points to is replaced.
+//
+// If opts.Backup is set, the old content remains available as name plus
+// opts.BackupSuffix, replacing an earlier backup. If the text cannot be
+// represented in the encoding of opts.Format, Save fails and leaves the file untouched.
+func Save(name string, cord cords.Cord, opts SaveOptions) (err error) {
+	if opts.Perm == 0 {
+		opts.Perm = 0o644
+	}
+	if opts.BackupSuffix == "" {
+		opts.BackupSuffix = "~"
+	}
+	if target, err := filepath.EvalSymlinks(name); err == nil {
+		name = target
+	}
+	old, err := os.Stat(name)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		old = nil
+	case err != nil:
+		return fmt.Errorf("textfile save failed: %w", err)
+	case !old.Mode().IsRegular():
+		return fmt.Errorf("textfile save failed: %s is not a regular file", name)
+	}
+	dir, base := filepath.Split(name)
+	tmp, err := os.CreateTemp(dir, "."+base+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("textfile save failed: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			_ = tmp.Close()
+			_ = os.Remove(tmp.Name())
+		}
+	}()
+	if err = writeSynced(tmp, cord, opts.Format); err != nil {
+		return fmt.Errorf("textfile save failed: %w", err)
+	}
+	perm := opts.Perm
+	if old != nil {
+		perm = old.Mode().Perm()
+	}
+	if err = tmp.Chmod(perm); err != nil {
+		return fmt.Errorf("textfile save failed: %w", err)
+	}
+	if old != nil {
+		if err = chown(tmp, old); err != nil {
+			return fmt.Errorf("textfile save failed: %w", err)
+		}
+	}
+	if err = tmp.Close(); err != nil {
+		return fmt.Errorf("textfile save failed: %w", err)
+	}
+	if old != nil && opts.Backup {
+		if err = backup(name, name+opts.BackupSuffix); err != nil {
+			return fmt.Errorf("textfile save failed: backup: %w", err)
+		}
+	}
+	if err = os.Rename(tmp.Name(), name); err != nil {
+		return fmt.Errorf("textfile save failed: %w", err)
+	}
+	tracer().Infof("saved %d bytes to %s", cord.Len(), name)
+	if err := fsutil.SyncDir(dir); err != nil {
+		return fmt.Errorf("textfile save failed: %w", err)
+	}
+	return nil
+}
+
+// writeSynced writes the text of cord to f in format ff and syncs f to disk.
+func writeSynced(f *os.File, cord cords.Cord, ff Format) error {
+	w := bufio.NewWriter(f)
+	if err := encodeTo(w, cord, ff); err != nil {
+		return err
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	return f.Sync()
+}
+
+// backup makes the content of file name available as file bak. It links bak to
+// name if possible and copies name otherwise.
+func backup(name, bak string) error {
+	if err := os.Remove(bak); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	if err := os.Link(name, bak); err == nil {
+		return nil
+	}
+	src, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	info, err := src.Stat()
+	if err != nil {
+		return err
+	}
+	dst, err := os.OpenFile(bak, os.O_WRONLY|os.O_CREATE|os.O_EXCL, info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(dst, src); err != nil {
+		_ = dst.Close()
+		return err
+	}
+	if err := dst.Sync(); err != nil {
+		_ = dst.Close()
+		return err
+	}
+	return dst.Close()
+}
@@ -0,0 +1,13 @@
+//go:build !unix
+
+package textfile
+
+import (
+	"io/fs"
+	"os"
+)
+
+// chown does nothing on systems without Unix file ownership.
+func chown(f *os.File, info fs.FileInfo) error {
+	return nil
+}
@@ -0,0 +1,129 @@
+package textfile
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/npillmayer/cords"
+	"github.com/npillmayer/schuko/tracing/gotestingadapter"
+)
+
+func TestSaveRoundTrip(t *testing.T) {
+	teardown := gotestingadapter.QuickConfig(t, "cords")
+	defer teardown()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "out.txt")
+	text := strings.Repeat("Größe 🙂 lorem ipsum\n", 5000)
+	if err := Save(path, cords.FromString(text), SaveOptions{}); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("stat failed: %v", err)
+	}
+	if info.Mode().Perm() != 0o644 {
+		t.Errorf("expected mode 0644 for new file, have %v", info.Mode().Perm())
+	}
+	cord, err := Load(path, 0, 0, nil)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if cord.String() != text {
+		t.Errorf("loaded text differs from saved text")
+	}
+	assertOnlyFiles(t, dir, "out.txt")
+}
+
+func TestSaveReplacesFileKeepingModeAndBackup(t *testing.T) {
+	teardown := gotestingadapter.QuickConfig(t, "cords")
+	defer teardown()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "doc.txt")
+	if err := os.WriteFile(path, []byte("old"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	link := filepath.Join(dir, "link.txt")
+	if err := os.Symlink("doc.txt", link); err != nil {
+		t.Skipf("cannot create symlink: %v", err)
+	}
+	if err := Save(link, cords.FromString("new"), SaveOptions{Backup: true}); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	if b, _ := os.ReadFile(path); string(b) != "new" {
+		t.Errorf("expected new content, have %q", b)
+	}
+	if b, _ := os.ReadFile(path + "~"); string(b) != "old" {
+		t.Errorf("expected backup with old content, have %q", b)
+	}
+	if info, _ := os.Lstat(link); info.Mode()&os.ModeSymlink == 0 {
+		t.Errorf("expected symlink to be kept")
+	}
+	if info, _ := os.Stat(path); info.Mode().Perm() != 0o600 {
+		t.Errorf("expected mode 0600 to be kept, have %v", info.Mode().Perm())
+	}
+	// A second save replaces the backup.
+	if err := Save(path, cords.FromString("newer"), SaveOptions{Backup: true, BackupSuffix: ".bak"}); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	if b, _ := os.ReadFile(path + ".bak"); string(b) != "new" {
+		t.Errorf("expected backup with previous content, have %q", b)
+	}
+	assertOnlyFiles(t, dir, "doc.txt", "doc.txt.bak", "doc.txt~", "link.txt")
+}
+
+func TestSaveFailureKeepsFile(t *testing.T) {
+	teardown := gotestingadapter.QuickConfig(t, "cords")
+	defer teardown()
+
+	dir := t.TempDir()
+	if err := Save(dir, cords.FromString("x"), SaveOptions{}); err == nil {
+		t.Fatalf("expected saving onto a directory to fail")
+	}
+	err := Save(filepath.Join(dir, "missing", "x.txt"), cords.FromString("x"), SaveOptions{})
+	if !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("expected ErrNotExist for missing directory, got %v", err)
+	}
+	assertOnlyFiles(t, dir)
+}
+
+func assertOnlyFiles(t *testing.T, dir string, want ...string) {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var have []string
+	for _, e := range entries {
+		have = append(have, e.Name())
+	}
+	if strings.Join(have, ",") != strings.Join(want, ",") {
+		t.Errorf("expected files %v in directory, have %v", want, have)
+	}
+}
+
+func TestLineEndingWriter(t *testing.T) {
+	for _, writes := range [][]string{
+		{"a\r\nb\rc\nd\r"},
+		{"a\r", "\nb\r", "c\n", "d", "\r"},
+		{"a", "\r", "\n", "b", "\r", "c", "\n", "d\r"},
+	} {
+		var sb strings.Builder
+		lw := &lineEndingWriter{w: &sb, marker: "\r\n"}
+		for _, w := range writes {
+			if n, err := lw.Write([]byte(w)); n != len(w) || err != nil {
+				t.Fatalf("Write(%q) = %d, %v", w, n, err)
+			}
+		}
+		if err := lw.Close(); err != nil {
+			t.Fatal(err)
+		}
+		if got := sb.String(); got != "a\r\nb\r\nc\r\nd\r\n" {
+			t.Errorf("writes %q produced %q", writes, got)
+		}
+	}
+}
@@ -0,0 +1,26 @@
+//go:build unix
+
+package textfile
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"syscall"
+)
+
+// chown gives f the owner and group of the file described by info. Failing
+// to do so for lack of permission is not an error; the file then belongs to
+// the caller.
+func chown(f *os.File, info fs.FileInfo) error {
+	st, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return nil
+	}
+	err := f.Chown(int(st.Uid), int(st.Gid))
+	if errors.Is(err, fs.ErrPermission) {
+		tracer().Infof("cannot preserve owner of %s: %v", info.Name(), err)
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,133 @@
+package textfile
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+
+	"github.com/npillmayer/cords"
+	"golang.org/x/text/transform"
+)
+
+// gzipMagic starts every gzip stream.
+const gzipMagic = "\x1f\x8b"
+
+// ReadFrom reads text from r until EOF and materializes it as a cord, in the
+// same way as LoadEncoded does for files. This allows loading text from pipes,
+// network connections or archives. A gzip stream is recognized by its first
+// bytes and decompressed transparently.
+//
+// Reading stops if ctx is cancelled. The size of r is unknown, so if
+// opts.FragSize is out of range, a default for large files is chosen.
+func ReadFrom(ctx context.Context, r io.Reader, opts LoadOptions) (cords.Cord, Format, error) {
+	opts.FragSize = normalizeFragSize(opts.FragSize, oneMb)
+	return readFrom(ctx, r, false, opts)
+}
+
+// readFrom reads the text of r. If gz is set, r has to be a gzip stream,
+// otherwise it is decompressed only if it starts like one.
+func readFrom(ctx context.Context, r io.Reader, gz bool, opts LoadOptions) (cords.Cord, Format, error) {
+	br := bufio.NewReaderSize(r, sampleSize)
+	if !gz {
+		magic, err := br.Peek(len(gzipMagic))
+		if err != nil && err != io.EOF {
+			return cords.Cord{}, Format{}, fmt.Errorf("textfile load failed: %w", err)
+		}
+		gz = string(magic) == gzipMagic
+	}
+	if gz {
+		zr, err := gzip.NewReader(br)
+		if err != nil {
+			return cords.Cord{}, Format{}, fmt.Errorf("textfile load failed: %w", err)
+		}
+		defer func() {
+			_ = zr.Close()
+		}()
+		br = bufio.NewReaderSize(zr, sampleSize)
+	}
+	sample, err := br.Peek(sampleSize)
+	if err != nil && err != io.EOF {
+		return cords.Cord{}, Format{}, fmt.Errorf("textfile load failed: %w", err)
+	}
+	var enc Encoding
+	switch {
+	case opts.Encoding != nil:
+		enc = *opts.Encoding
+	case opts.Invalid != FailInvalid:
+		var ok bool
+		if enc, ok = detectUnicode(sample); !ok {
+			enc = UTF8
+		}
+	default:
+		enc = DetectEncoding(sample)
+	}
+	// The cord does not include a byte order mark, but the encoding records it.
+	skip := int64(0)
+	enc.BOM = enc.mark != "" && bytes.HasPrefix(sample, []byte(enc.mark))
+	if enc.BOM {
+		skip = int64(len(enc.mark))
+		_, _ = br.Discard(len(enc.mark))
+	}
+	tracer().Infof("loading text encoded in %s, gzip=%t", enc, gz)
+	format := Format{Encoding: enc, Gzip: gz, Escaped: enc.isUTF8() && opts.Invalid == EscapeInvalid}
+	var tr io.Reader
+	if enc.isUTF8() {
+		tr = transform.NewReader(br, &sanitizer{policy: opts.Invalid, offset: skip, format: &format})
+	} else {
+		tr = transform.NewReader(br, enc.enc.NewDecoder())
+	}
+	var b cords.Builder
+	var loaded atomic.Int64
+	if err := loadWithPrefetch(ctx, tr, opts.FragSize, &b, &loaded); err != nil {
+		return cords.Cord{}, Format{}, err
+	}
+	if err := ctx.Err(); err != nil {
+		return cords.Cord{}, Format{}, err
+	}
+	cord := b.Cord()
+	if format.InvalidBytes > 0 {
+		tracer().Infof("%d invalid bytes, policy %s", format.InvalidBytes, opts.Invalid)
+	}
+	format.LineEnding, _ = cord.LineEndings().Dominant()
+	if opts.NormalizeLineEndings {
+		cord = cords.NormalizeLineEndings(cord, cords.LF)
+		format.Normalized = true
+	}
+	return cord, format, nil
+}
+
+// hasGzipExt reports whether name has the extension of a gzip file.
+func hasGzipExt(name string) bool {
+	return strings.EqualFold(filepath.Ext(name), ".gz")
+}
+
+// isGzipFile reports whether the file name is compressed with gzip, judged by
+// its extension or its first bytes.
+func isGzipFile(name string) (bool, error) {
+	if hasGzipExt(name) {
+		return true, nil
+	}
+	f, err := os.Open(name)
+	if err != nil {
+		return false, err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+	magic := make([]byte, len(gzipMagic))
+	if _, err := io.ReadFull(f, magic); err != nil {
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return false, nil
+		}
+		return false, err
+	}
+	return string(magic) == gzipMagic, nil
+}
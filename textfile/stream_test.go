@@ -0,0 +1,142 @@
+package textfile
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/npillmayer/cords"
+	"github.com/npillmayer/schuko/tracing/gotestingadapter"
+)
+
+func gzipped(t *testing.T, text []byte) []byte {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(text); err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestReadFromPipe(t *testing.T) {
+	teardown := gotestingadapter.QuickConfig(t, "cords")
+	defer teardown()
+
+	want := strings.Repeat("aä🙂\r\nβ", 1000)
+	pr, pw := io.Pipe()
+	go func() {
+		// write in odd pieces to cut runes between reads
+		for s := want; len(s) > 0; s = s[min(len(s), 7):] {
+			if _, err := io.WriteString(pw, s[:min(len(s), 7)]); err != nil {
+				return
+			}
+		}
+		_ = pw.Close()
+	}()
+	cord, format, err := ReadFrom(context.Background(), pr, LoadOptions{FragSize: 5})
+	if err != nil {
+		t.Fatalf("ReadFrom failed: %v", err)
+	}
+	if cord.String() != want {
+		t.Errorf("unexpected text from pipe")
+	}
+	if format.Encoding.Name != "UTF-8" || format.LineEnding.String() != "CRLF" || format.Gzip {
+		t.Errorf("unexpected format %s", format)
+	}
+}
+
+func TestReadFromHTTPGzip(t *testing.T) {
+	teardown := gotestingadapter.QuickConfig(t, "cords")
+	defer teardown()
+
+	text, err := os.ReadFile("lorem/lorem_small.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body := gzipped(t, text)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/octet-stream")
+		_, _ = w.Write(body)
+	}))
+	defer srv.Close()
+	resp, err := http.Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	cord, format, err := ReadFrom(context.Background(), resp.Body, LoadOptions{})
+	if err != nil {
+		t.Fatalf("ReadFrom failed: %v", err)
+	}
+	if !format.Gzip || cord.String() != string(text) {
+		t.Errorf("expected decompressed text, have %d bytes, gzip=%t", cord.Len(), format.Gzip)
+	}
+}
+
+func TestReadFromCancel(t *testing.T) {
+	teardown := gotestingadapter.QuickConfig(t, "cords")
+	defer teardown()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, _, err := ReadFrom(ctx, strings.NewReader(strings.Repeat("x", 100000)), LoadOptions{})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected cancellation error, got %v", err)
+	}
+}
+
+func TestLoadGzipFile(t *testing.T) {
+	teardown := gotestingadapter.QuickConfig(t, "cords")
+	defer teardown()
+
+	text, err := os.ReadFile("lorem/lorem_small.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	for _, name := range []string{"lorem.txt.gz", "lorem.log"} { // by extension, by content
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, gzipped(t, text), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		cord, err := Load(path, 0, 0, nil)
+		if err != nil {
+			t.Fatalf("Load of %s failed: %v", name, err)
+		}
+		if cord.String() != string(text) {
+			t.Errorf("unexpected text loaded from %s", name)
+		}
+		cord, format, err := LoadEncoded(path, LoadOptions{})
+		if err != nil || !format.Gzip {
+			t.Fatalf("LoadEncoded of %s failed: %v, gzip=%t", name, err, format.Gzip)
+		}
+		edited := cord.String() + "more\n"
+		if err := Save(path, cords.FromString(edited), SaveOptions{Format: format}); err != nil {
+			t.Fatalf("Save failed: %v", err)
+		}
+		f, err := os.Open(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		zr, err := gzip.NewReader(f)
+		if err != nil {
+			t.Fatalf("saved file is not compressed: %v", err)
+		}
+		saved, err := io.ReadAll(zr)
+		_ = f.Close()
+		if err != nil || string(saved) != edited {
+			t.Errorf("unexpected content of saved file: %v", err)
+		}
+	}
+}
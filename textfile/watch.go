@@ -0,0 +1,494 @@
+package textfile
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"sync/atomic"
+	"time"
+	"unicode/utf8"
+
+	"github.com/npillmayer/cords"
+	"golang.org/x/text/transform"
+)
+
+// defaultPollInterval is the interval at which a Watcher polls the file if
+// change notifications are not available.
+const defaultPollInterval = 500 * time.Millisecond
+
+// tailCheckSize is the number of bytes before the previous end of the file
+// which have to be unchanged for a grown file to be treated as appended to.
+const tailCheckSize = 4096
+
+// diffBlockSize is the size of the blocks in which texts are compared.
+const diffBlockSize = 32 * 1024
+
+// Diff describes a change of text: bytes [Pos, Pos+Deleted) of the previous
+// text have been replaced by bytes [Pos, Pos+Inserted) of the new text. Both
+// ranges are aligned to rune boundaries.
+type Diff struct {
+	Pos      uint64
+	Deleted  uint64
+	Inserted uint64
+}
+
+// IsEmpty reports whether the diff describes no change at all.
+func (d Diff) IsEmpty() bool {
+	return d.Deleted == 0 && d.Inserted == 0
+}
+
+// Change is reported by a Watcher when the content of the watched file has
+// changed on disk.
+type Change struct {
+	Cord   cords.Cord // new text, sharing unchanged parts with the previous one
+	Format Format     // format of the file, as reported by LoadEncoded
+	Diff   Diff       // changed range relative to the previous text
+	// Appended tells that the file has just grown, and that Cord is the
+	// previous text concatenated with the new tail.
+	Appended bool
+}
+
+// WatchOptions control Watch. The zero value is ready to use.
+type WatchOptions struct {
+	// Load are the options used to reload the file.
+	Load LoadOptions
+	// Poll forces polling instead of change notifications by the operating
+	// system, which may miss changes on network file systems.
+	Poll bool
+	// Interval is the polling interval; it defaults to 500ms.
+	Interval time.Duration
+}
+
+// Watcher watches a loaded file for modifications on disk, as returned by
+// Watch.
+type Watcher struct {
+	name     string
+	opts     WatchOptions
+	cord     cords.Cord // text of the file as of the last change
+	format   Format
+	info     fs.FileInfo            // file info as of the last change, nil if missing
+	consumed int64                  // bytes of the file the text has been loaded from
+	endings  cords.LineEndingCounts // line endings of cord, if counted
+	counted  bool                   // endings have been counted
+	notify   *notifier              // nil if polling
+	changes  chan Change
+	cancel   context.CancelFunc
+	done     chan struct{}
+	err      error // set before changes is closed
+}
+
+// Watch starts watching file name, which has been loaded as cord in format
+// `format`, e.g. with LoadEncoded. Whenever the file changes on disk, the
+// watcher reloads it and reports the new text on its Changes channel, so that
+// clients can rebase their edits with the help of the diff.
+//
+// If the file has just grown, as is common for logs, only the appended tail
+// is read, with `tail -f` semantics. Otherwise the file is reloaded completely
+// and the new text shares the unchanged head and tail with the previous one.
+// Files which are replaced, e.g. by log rotation or an editor saving
+// atomically, continue to be watched by name.
+//
+// Watching stops if ctx is cancelled, Close is called or an error occurs.
+func Watch(ctx context.Context, name string, cord cords.Cord, format Format, opts WatchOptions) (*Watcher, error) {
+	if opts.Interval <= 0 {
+		opts.Interval = defaultPollInterval
+	}
+	w := &Watcher{
+		name:    name,
+		opts:    opts,
+		cord:    cord,
+		format:  format,
+		changes: make(chan Change),
+		done:    make(chan struct{}),
+	}
+	if !opts.Poll {
+		// Start listening before taking the baseline, so that no change gets lost.
+		n, err := newNotifier(name)
+		if err != nil {
+			tracer().Infof("watching %s by polling: %v", name, err)
+		}
+		w.notify = n
+	}
+	info, err := os.Stat(name)
+	if err != nil {
+		if w.notify != nil {
+			_ = w.notify.Close()
+		}
+		return nil, err
+	}
+	w.info = info
+	w.consumed = w.textOffset() + int64(cord.Len())
+	ctx, w.cancel = context.WithCancel(ctx)
+	go w.run(ctx)
+	return w, nil
+}
+
+// Changes returns the channel on which changes of the file are reported. It is
+// closed when watching stops.
+func (w *Watcher) Changes() <-chan Change {
+	return w.changes
+}
+
+// Err returns the error which has stopped watching, if any. It is valid once
+// the Changes channel has been closed.
+func (w *Watcher) Err() error {
+	<-w.done
+	return w.err
+}
+
+// Close stops watching and waits for the watcher to terminate.
+func (w *Watcher) Close() error {
+	w.cancel()
+	<-w.done
+	return nil
+}
+
+func (w *Watcher) run(ctx context.Context) {
+	defer func() {
+		if w.notify != nil {
+			_ = w.notify.Close()
+		}
+		close(w.changes)
+		close(w.done)
+	}()
+	var tick <-chan time.Time
+	var events <-chan struct{}
+	if w.notify != nil {
+		events = w.notify.C
+	} else {
+		ticker := time.NewTicker(w.opts.Interval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+	for {
+		force := false
+		select {
+		case <-ctx.Done():
+			return
+		case <-tick:
+		case _, ok := <-events:
+			if !ok {
+				w.err = fmt.Errorf("textfile watch failed: %w", w.notify.Err())
+				return
+			}
+			force = true
+		}
+		change, changed, err := w.check(ctx, force)
+		if err != nil {
+			w.err = err
+			return
+		}
+		if !changed {
+			continue
+		}
+		select {
+		case w.changes <- change:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// check looks for a modification of the file. A file which has been notified
+// as changed is checked even if its size and time stamp are unchanged.
+func (w *Watcher) check(ctx context.Context, force bool) (Change, bool, error) {
+	info, err := os.Stat(w.name)
+	if errors.Is(err, fs.ErrNotExist) {
+		// The file is being replaced; wait for the new one.
+		w.info = nil
+		return Change{}, false, nil
+	} else if err != nil {
+		return Change{}, false, err
+	}
+	if !force && w.info != nil && os.SameFile(info, w.info) &&
+		info.Size() == w.info.Size() && info.ModTime().Equal(w.info.ModTime()) {
+		return Change{}, false, nil
+	}
+	prev := w.info
+	w.info = info
+	if prev != nil && os.SameFile(info, prev) && info.Size() > w.consumed && w.appendable() {
+		if change, ok, err := w.readTail(ctx, info.Size()); ok || err != nil {
+			return change, ok && !change.Diff.IsEmpty(), err
+		}
+	}
+	return w.reload()
+}
+
+// appendable reports whether text appended to the file may be loaded on its
+// own, which requires the text to be a plain copy of the file.
+func (w *Watcher) appendable() bool {
+	f := w.format
+	return f.Encoding.isUTF8() && !f.Gzip && !f.Normalized && !f.Escaped && f.InvalidBytes == 0
+}
+
+// textOffset returns the offset of the text within the file.
+func (w *Watcher) textOffset() int64 {
+	if w.format.Encoding.BOM {
+		return int64(len(w.format.Encoding.mark))
+	}
+	return 0
+}
+
+// readTail appends bytes [w.consumed, size) of the file to the text. It
+// returns false if the file has not just grown, but has been modified before
+// its previous end.
+func (w *Watcher) readTail(ctx context.Context, size int64) (Change, bool, error) {
+	f, err := os.Open(w.name)
+	if err != nil {
+		return Change{}, false, err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+	if ok, err := w.sameTail(f); !ok || err != nil {
+		return Change{}, false, err
+	}
+	end, err := runeEnd(f, w.consumed, size)
+	if err != nil {
+		return Change{}, false, err
+	}
+	if end == w.consumed { // only part of a rune has been written so far
+		return Change{}, true, nil
+	}
+	format := w.format
+	format.Escaped = w.opts.Load.Invalid == EscapeInvalid
+	r := transform.NewReader(io.NewSectionReader(f, w.consumed, end-w.consumed),
+		&sanitizer{policy: w.opts.Load.Invalid, offset: w.consumed, format: &format})
+	var b cords.Builder
+	var loaded atomic.Int64
+	fragSize := normalizeFragSize(w.opts.Load.FragSize, end-w.consumed)
+	if err := loadWithPrefetch(ctx, r, fragSize, &b, &loaded); err != nil {
+		return Change{}, false, err
+	}
+	tail := b.Cord()
+	if err := w.countLineEndings(tail); err != nil {
+		return Change{}, false, err
+	}
+	change := Change{
+		Cord:     cords.Concat(w.cord, tail),
+		Diff:     Diff{Pos: w.cord.Len(), Inserted: tail.Len()},
+		Appended: true,
+	}
+	format.LineEnding, _ = w.endings.Dominant()
+	change.Format = format
+	tracer().Debugf("%s: %d bytes appended", w.name, tail.Len())
+	w.cord, w.format, w.consumed = change.Cord, format, end
+	return change, true, nil
+}
+
+// countLineEndings adds the line endings of tail, which is about to be
+// appended to the text, to the line endings of the text. Only the text loaded
+// initially or by the latest reload is counted completely.
+func (w *Watcher) countLineEndings(tail cords.Cord) error {
+	if !w.counted {
+		w.endings, w.counted = w.cord.LineEndings(), true
+	}
+	counts := tail.LineEndings()
+	if w.cord.Len() > 0 && tail.Len() > 0 {
+		last, err := reportBefore(w.cord, w.cord.Len(), 1)
+		if err != nil {
+			return err
+		}
+		end, err := runeStartAtOrBefore(tail, min(tail.Len(), utf8.UTFMax))
+		if err != nil {
+			return err
+		}
+		first, err := tail.Report(0, end)
+		if err != nil {
+			return err
+		}
+		if last[len(last)-1] == '\r' && first[0] == '\n' { // a CRLF split by the append
+			counts.CR, counts.LF, counts.CRLF = counts.CR-1, counts.LF-1, counts.CRLF+1
+		}
+	}
+	w.endings.LF += counts.LF
+	w.endings.CRLF += counts.CRLF
+	w.endings.CR += counts.CR
+	return nil
+}
+
+// sameTail reports whether the last bytes of the file before w.consumed are
+// still equal to the end of the text.
+func (w *Watcher) sameTail(f *os.File) (bool, error) {
+	n := min(uint64(tailCheckSize), w.cord.Len())
+	if n == 0 {
+		return true, nil
+	}
+	start, err := runeStartAtOrBefore(w.cord, w.cord.Len()-n)
+	if err != nil {
+		return false, err
+	}
+	want, err := w.cord.Report(start, w.cord.Len()-start)
+	if err != nil {
+		return false, err
+	}
+	have := make([]byte, len(want))
+	if _, err := f.ReadAt(have, w.consumed-int64(len(want))); err != nil {
+		return false, err
+	}
+	return string(have) == want, nil
+}
+
+// reload loads the file completely and diffs it against the previous text.
+func (w *Watcher) reload() (Change, bool, error) {
+	cord, format, err := LoadEncoded(w.name, w.opts.Load)
+	if errors.Is(err, fs.ErrNotExist) {
+		w.info = nil
+		return Change{}, false, nil
+	} else if err != nil {
+		return Change{}, false, err
+	}
+	diff, err := diffCords(w.cord, cord)
+	if err != nil {
+		return Change{}, false, err
+	}
+	if diff.IsEmpty() && format.Encoding.sameAs(w.format.Encoding) {
+		return Change{}, false, nil
+	}
+	// Share the unchanged head and tail with the previous text.
+	if cord, err = splice(w.cord, cord, diff); err != nil {
+		return Change{}, false, err
+	}
+	tracer().Debugf("%s: reloaded, %d bytes at %d replaced by %d bytes", w.name,
+		diff.Deleted, diff.Pos, diff.Inserted)
+	w.cord, w.format, w.counted = cord, format, false
+	w.consumed = w.textOffset() + int64(cord.Len())
+	return Change{Cord: cord, Format: format, Diff: diff}, true, nil
+}
+
+// diffCords returns the range in which text differs from prev, as delimited by
+// their longest common prefix and suffix.
+func diffCords(prev, text cords.Cord) (Diff, error) {
+	prefix, err := commonPrefix(prev, text)
+	if err != nil {
+		return Diff{}, err
+	}
+	limit := min(prev.Len(), text.Len()) - prefix
+	var suffix uint64
+	for suffix < limit {
+		n := min(uint64(tailCheckSize), limit-suffix)
+		a, err := reportBefore(prev, prev.Len()-suffix, n)
+		if err != nil {
+			return Diff{}, err
+		}
+		b, err := reportBefore(text, text.Len()-suffix, n)
+		if err != nil {
+			return Diff{}, err
+		}
+		m := 0
+		for m < len(a) && m < len(b) && a[len(a)-1-m] == b[len(b)-1-m] {
+			m++
+		}
+		suffix = min(suffix+uint64(m), limit)
+		if m < min(len(a), len(b)) {
+			break
+		}
+	}
+	// Both texts are valid UTF-8, so the bounds of the common parts are rune
+	// boundaries in one of them if and only if they are in the other.
+	if prefix, err = runeStartAtOrBefore(prev, prefix); err != nil {
+		return Diff{}, err
+	}
+	for suffix > 0 {
+		if _, err := prev.PosFromByte(prev.Len() - suffix); err == nil {
+			break
+		}
+		suffix--
+	}
+	return Diff{
+		Pos:      prefix,
+		Deleted:  prev.Len() - prefix - suffix,
+		Inserted: text.Len() - prefix - suffix,
+	}, nil
+}
+
+// commonPrefix returns the length of the longest common prefix of a and b in
+// bytes.
+func commonPrefix(a, b cords.Cord) (uint64, error) {
+	ra, rb := a.Reader(), b.Reader()
+	bufA, bufB := make([]byte, diffBlockSize), make([]byte, diffBlockSize)
+	var prefix uint64
+	for {
+		na, errA := io.ReadFull(ra, bufA)
+		nb, errB := io.ReadFull(rb, bufB)
+		n := min(na, nb)
+		m := 0
+		for m < n && bufA[m] == bufB[m] {
+			m++
+		}
+		prefix += uint64(m)
+		if m < n || na != nb || errA != nil || errB != nil {
+			for _, err := range []error{errA, errB} {
+				if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+					return 0, err
+				}
+			}
+			return prefix, nil
+		}
+	}
+}
+
+// reportBefore materializes at least n bytes of cord before byte offset end,
+// extended to the preceding rune boundary.
+func reportBefore(cord cords.Cord, end, n uint64) ([]byte, error) {
+	start, err := runeStartAtOrBefore(cord, end-n)
+	if err != nil {
+		return nil, err
+	}
+	s, err := cord.Report(start, end-start)
+	return []byte(s), err
+}
+
+// runeStartAtOrBefore returns the last rune boundary of cord at or before
+// byte offset i.
+func runeStartAtOrBefore(cord cords.Cord, i uint64) (uint64, error) {
+	for j := 0; j < utf8.UTFMax && i > 0; j++ {
+		if _, err := cord.PosFromByte(i); err == nil {
+			return i, nil
+		}
+		i--
+	}
+	if _, err := cord.PosFromByte(i); err != nil {
+		return 0, err
+	}
+	return i, nil
+}
+
+// splice returns text with the parts outside of diff taken from prev.
+func splice(prev, text cords.Cord, diff Diff) (cords.Cord, error) {
+	head, err := cords.Substr(prev, 0, diff.Pos)
+	if err != nil {
+		return cords.Cord{}, err
+	}
+	middle, err := cords.Substr(text, diff.Pos, diff.Inserted)
+	if err != nil {
+		return cords.Cord{}, err
+	}
+	tail, err := cords.Substr(prev, diff.Pos+diff.Deleted, prev.Len()-diff.Pos-diff.Deleted)
+	if err != nil {
+		return cords.Cord{}, err
+	}
+	return cords.Concat(head, middle, tail), nil
+}
+
+// runeEnd returns the end of the last complete rune in bytes [from, to) of f,
+// which is to unless the file ends in the middle of a multi-byte rune.
+func runeEnd(f *os.File, from, to int64) (int64, error) {
+	n := min(to-from, utf8.UTFMax-1)
+	buf := make([]byte, n)
+	if _, err := f.ReadAt(buf, to-n); err != nil {
+		return 0, err
+	}
+	for i := len(buf) - 1; i >= 0; i-- {
+		if utf8.RuneStart(buf[i]) {
+			if !utf8.FullRune(buf[i:]) && buf[i] >= utf8.RuneSelf {
+				return to - n + int64(i), nil
+			}
+			break
+		}
+	}
+	return to, nil
+}
@@ -0,0 +1,80 @@
+//go:build linux
+
+package textfile
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"sync"
+	"syscall"
+	"unsafe"
+)
+
+// notifier signals changes of a file, as reported by inotify. It watches the
+// directory of the file, so that replacing the file is noticed as well.
+type notifier struct {
+	C    <-chan struct{} // closed on failure, see Err
+	f    *os.File
+	err  error
+	once sync.Once
+}
+
+const inotifyMask = syscall.IN_MODIFY | syscall.IN_ATTRIB | syscall.IN_CLOSE_WRITE |
+	syscall.IN_CREATE | syscall.IN_DELETE | syscall.IN_MOVED_FROM | syscall.IN_MOVED_TO
+
+func newNotifier(name string) (*notifier, error) {
+	fd, err := syscall.InotifyInit1(syscall.IN_CLOEXEC | syscall.IN_NONBLOCK)
+	if err != nil {
+		return nil, os.NewSyscallError("inotify_init1", err)
+	}
+	if _, err := syscall.InotifyAddWatch(fd, filepath.Dir(name), inotifyMask); err != nil {
+		_ = syscall.Close(fd)
+		return nil, os.NewSyscallError("inotify_add_watch", err)
+	}
+	// A non-blocking descriptor is served by the runtime poller, so Close
+	// interrupts a pending Read.
+	f := os.NewFile(uintptr(fd), "inotify")
+	c := make(chan struct{}, 1)
+	n := &notifier{C: c, f: f}
+	go n.read(filepath.Base(name), c)
+	return n, nil
+}
+
+func (n *notifier) read(base string, c chan<- struct{}) {
+	defer close(c)
+	buf := make([]byte, 64*(syscall.SizeofInotifyEvent+syscall.NAME_MAX+1))
+	for {
+		k, err := n.f.Read(buf)
+		if err != nil {
+			n.err = err
+			return
+		}
+		for off := 0; off+syscall.SizeofInotifyEvent <= k; {
+			ev := (*syscall.InotifyEvent)(unsafe.Pointer(&buf[off]))
+			name := buf[off+syscall.SizeofInotifyEvent : off+syscall.SizeofInotifyEvent+int(ev.Len)]
+			off += syscall.SizeofInotifyEvent + int(ev.Len)
+			if ev.Mask&syscall.IN_Q_OVERFLOW == 0 && string(bytes.TrimRight(name, "\x00")) != base {
+				continue
+			}
+			select {
+			case c <- struct{}{}:
+			default: // a change is pending already
+			}
+		}
+	}
+}
+
+// Err returns the error which has closed C.
+func (n *notifier) Err() error {
+	return n.err
+}
+
+// Close stops watching.
+func (n *notifier) Close() error {
+	var err error
+	n.once.Do(func() {
+		err = n.f.Close()
+	})
+	return err
+}
@@ -0,0 +1,23 @@
+//go:build !linux
+
+package textfile
+
+import "errors"
+
+// notifier signals changes of a file. Change notifications are implemented for
+// Linux only; elsewhere, watchers poll.
+type notifier struct {
+	C <-chan struct{}
+}
+
+func newNotifier(name string) (*notifier, error) {
+	return nil, errors.New("change notifications not supported on this platform")
+}
+
+func (n *notifier) Err() error {
+	return nil
+}
+
+func (n *notifier) Close() error {
+	return nil
+}
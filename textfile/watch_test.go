@@ -0,0 +1,228 @@
+package textfile
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/npillmayer/cords"
+	"github.com/npillmayer/schuko/tracing/gotestingadapter"
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+)
+
+func nextChange(t *testing.T, w *Watcher) Change {
+	t.Helper()
+	select {
+	case change, ok := <-w.Changes():
+		if !ok {
+			t.Fatalf("watcher stopped: %v", w.Err())
+		}
+		return change
+	case <-time.After(5 * time.Second):
+		t.Fatalf("no change reported")
+	}
+	return Change{}
+}
+
+func appendFile(t *testing.T, path, text string) {
+	t.Helper()
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.WriteString(text); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestWatch(t *testing.T) {
+	teardown := gotestingadapter.QuickConfig(t, "cords")
+	defer teardown()
+
+	for _, poll := range []bool{false, true} {
+		t.Run(map[bool]string{false: "notify", true: "poll"}[poll], func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "app.log")
+			text := strings.Repeat("line ä\n", 1000)
+			if err := os.WriteFile(path, []byte(text), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			cord, format, err := LoadEncoded(path, LoadOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			w, err := Watch(context.Background(), path, cord, format,
+				WatchOptions{Poll: poll, Interval: 10 * time.Millisecond})
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer w.Close()
+
+			// A rune written in two steps is reported when complete.
+			appendFile(t, path, "new \xc3")
+			change := nextChange(t, w)
+			if !change.Appended || change.Diff != (Diff{Pos: cord.Len(), Inserted: 4}) {
+				t.Errorf("expected append of 4 bytes, have %+v", change.Diff)
+			}
+			appendFile(t, path, "\xb6\n")
+			change = nextChange(t, w)
+			text += "new ö\n"
+			if !change.Appended || change.Cord.String() != text {
+				t.Errorf("expected appended tail, have %+v", change.Diff)
+			}
+
+			// An editor replaces the file, changing a line in the middle.
+			edited := strings.Replace(text, "line ä", "line ö", 500)
+			tmp := path + ".tmp"
+			if err := os.WriteFile(tmp, []byte(edited), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.Rename(tmp, path); err != nil {
+				t.Fatal(err)
+			}
+			change = nextChange(t, w)
+			want := Diff{Pos: 5, Deleted: 499*8 + 2, Inserted: 499*8 + 2}
+			if change.Appended || change.Diff != want || change.Cord.String() != edited {
+				t.Errorf("expected diff %+v, have %+v", want, change.Diff)
+			}
+		})
+	}
+}
+
+func TestWatchAppendedLineEndings(t *testing.T) {
+	teardown := gotestingadapter.QuickConfig(t, "cords")
+	defer teardown()
+
+	path := filepath.Join(t.TempDir(), "app.log")
+	if err := os.WriteFile(path, []byte("one\rtwo\r"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cord, format, err := LoadEncoded(path, LoadOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if format.LineEnding != cords.CR {
+		t.Fatalf("expected CR line endings, have %v", format.LineEnding)
+	}
+	w, err := Watch(context.Background(), path, cord, format,
+		WatchOptions{Poll: true, Interval: 10 * time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	// The append completes a CRLF, which makes CRLF line endings dominant.
+	appendFile(t, path, "\nthree\r\n")
+	change := nextChange(t, w)
+	if !change.Appended || change.Format.LineEnding != cords.CRLF {
+		t.Errorf("expected appended CRLF line endings, have %v", change.Format.LineEnding)
+	}
+	if want := change.Cord.LineEndings(); w.endings != want {
+		t.Errorf("expected line endings %+v, have %+v", want, w.endings)
+	}
+}
+
+func TestWatchTruncated(t *testing.T) {
+	teardown := gotestingadapter.QuickConfig(t, "cords")
+	defer teardown()
+
+	path := filepath.Join(t.TempDir(), "app.log")
+	if err := os.WriteFile(path, []byte("old entries\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cord, format, err := LoadEncoded(path, LoadOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	w, err := Watch(context.Background(), path, cord, format,
+		WatchOptions{Poll: true, Interval: 10 * time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// copytruncate rotation, followed by new entries longer than the old ones
+	if err := os.WriteFile(path, []byte("new entries, more of them\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	change := nextChange(t, w)
+	if change.Appended || change.Cord.String() != "new entries, more of them\n" {
+		t.Errorf("expected reloaded text, have %q", change.Cord.String())
+	}
+	if err := w.Close(); err != nil || w.Err() != nil {
+		t.Errorf("unexpected errors %v, %v", err, w.Err())
+	}
+	if _, ok := <-w.Changes(); ok {
+		t.Errorf("expected changes to be closed")
+	}
+}
+
+// uncomparableEncoding is an encoding which panics if compared with ==.
+type uncomparableEncoding struct {
+	encoding.Encoding
+	aliases []string
+}
+
+func TestWatchReloadWithUncomparableEncoding(t *testing.T) {
+	teardown := gotestingadapter.QuickConfig(t, "cords")
+	defer teardown()
+
+	path := filepath.Join(t.TempDir(), "menu.txt")
+	if err := os.WriteFile(path, []byte("caf\xe9\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	enc := NewEncoding("latin-1", uncomparableEncoding{Encoding: charmap.ISO8859_1})
+	opts := LoadOptions{Encoding: &enc}
+	cord, format, err := LoadEncoded(path, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w, err := Watch(context.Background(), path, cord, format,
+		WatchOptions{Load: opts, Poll: true, Interval: 10 * time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	// Touching the file reloads the same text, which is not reported.
+	later := time.Now().Add(time.Hour)
+	if err := os.Chtimes(path, later, later); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(50 * time.Millisecond)
+	if err := os.WriteFile(path, []byte("caf\xe9s\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	change := nextChange(t, w)
+	if change.Diff != (Diff{Pos: 5, Inserted: 1}) || change.Cord.String() != "cafés\n" {
+		t.Errorf("expected insertion of 1 byte at 5, have %+v", change.Diff)
+	}
+}
+
+func TestDiffCords(t *testing.T) {
+	for _, tc := range []struct {
+		prev, text string
+		diff       Diff
+	}{
+		{"abc", "abc", Diff{Pos: 3}},
+		{"abc", "abxc", Diff{Pos: 2, Inserted: 1}},
+		{"xäy", "xöy", Diff{Pos: 1, Deleted: 2, Inserted: 2}},
+		{"aaaa", "aa", Diff{Pos: 2, Deleted: 2}},
+		{"", "new", Diff{Inserted: 3}},
+		{strings.Repeat("ab", 5000) + "ä", strings.Repeat("ab", 5000) + "ö", Diff{Pos: 10000, Deleted: 2, Inserted: 2}},
+		{"ä" + strings.Repeat("ab", 5000), "ö" + strings.Repeat("ab", 5000), Diff{Deleted: 2, Inserted: 2}},
+	} {
+		prev, text := cords.FromString(tc.prev), cords.FromString(tc.text)
+		diff, err := diffCords(prev, text)
+		if err != nil || diff != tc.diff {
+			t.Errorf("diff of %.10q and %.10q: expected %+v, have %+v (%v)", tc.prev, tc.text, tc.diff, diff, err)
+			continue
+		}
+		spliced, err := splice(prev, text, diff)
+		if err != nil || spliced.String() != tc.text {
+			t.Errorf("splice of %.10q and %.10q failed: %v", tc.prev, tc.text, err)
+		}
+	}
+}
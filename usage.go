@@ -0,0 +1,21 @@
+package cords
+
+import (
+	"github.com/npillmayer/cords/btree"
+	"github.com/npillmayer/cords/chunk"
+)
+
+// MemoryUsage reports the memory held by cords, counting tree nodes shared
+// between cords only once. Payload is the number of text bytes held by the
+// distinct chunks.
+//
+// Called with all versions of a text kept in an edit history, MemoryUsage tells
+// how much memory the history pins. Comparing the result with the usage of all
+// versions except one tells how much memory dropping that version would free.
+func MemoryUsage(cords ...Cord) btree.Usage {
+	trees := make([]*btree.Tree[chunk.Chunk, chunk.Summary, btree.NO_EXT], len(cords))
+	for i, cord := range cords {
+		trees[i] = cord.tree
+	}
+	return btree.MemoryUsage(trees...)
+}
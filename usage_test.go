@@ -0,0 +1,31 @@
+package cords
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/npillmayer/cords/chunk"
+)
+
+func TestMemoryUsageAcrossVersions(t *testing.T) {
+	text := strings.Repeat("0123456789", 16*chunk.MaxBase)
+	c1 := FromString(text)
+	u1 := MemoryUsage(c1)
+	if u1.Payload != int64(len(text)) {
+		t.Fatalf("expected payload of %d text bytes, got %v", len(text), u1)
+	}
+	c2, err := Insert(c1, FromString("abc"), uint64(len(text)/2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	u2 := MemoryUsage(c1, c2)
+	if u2.Trees != 2 || u2.Payload >= 2*u1.Payload || u2.Payload < u1.Payload {
+		t.Errorf("expected versions to share most chunks: %v vs %v", u2, u1)
+	}
+	if freed := u2.Bytes - MemoryUsage(c2).Bytes; freed <= 0 || freed >= u1.Bytes/4 {
+		t.Errorf("dropping c1 should free its few unshared nodes only, frees %d of %d bytes", freed, u1.Bytes)
+	}
+	if u := MemoryUsage(); u.Nodes != 0 {
+		t.Errorf("expected no nodes for no cords, got %v", u)
+	}
+}